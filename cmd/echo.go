@@ -40,7 +40,7 @@ var echoCmd = &cobra.Command{
 		wg.Add(1)
 		go echo.Start(echoAddr, wg)
 
-		c := make(chan os.Signal)
+		c := make(chan os.Signal, 1)
 		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 		<-c
 		wg.Done()
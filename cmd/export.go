@@ -0,0 +1,88 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fantai/ftab/pkg/httpfile"
+	"github.com/spf13/cobra"
+)
+
+var exportInFile, exportOutFile, exportFormat, exportName string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export [http file]",
+	Short: "export http file to cURL, HAR or Postman collection",
+	Long:  `export cases of http file as cURL command lines, a HAR 1.2 or a Postman collection v2.1, variables are replaced for curl and har, postman keep them as collection variables and set values of request variables by test scripts`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			exportInFile = args[0]
+		}
+		if exportInFile == "" {
+			return fmt.Errorf("http file is required")
+		}
+		file, err := httpfile.ParseFile(exportInFile)
+		if err != nil {
+			return fmt.Errorf("parse file: %w", err)
+		}
+		defer file.Release()
+
+		if envFile != "" {
+			file.Env, err = httpfile.LoadEnvironment(envFile, envName)
+			if err != nil {
+				return err
+			}
+		}
+
+		buff := bytes.NewBuffer(nil)
+		switch exportFormat {
+		case "curl":
+			err = file.WriteCurl(buff)
+		case "har":
+			err = file.WriteHAR(buff)
+		case "postman":
+			name := exportName
+			if name == "" {
+				name = strings.TrimSuffix(filepath.Base(exportInFile), filepath.Ext(exportInFile))
+			}
+			err = file.WritePostman(buff, name)
+		default:
+			return fmt.Errorf("unknown format %s, curl, har or postman is expected", exportFormat)
+		}
+		if err != nil {
+			return err
+		}
+
+		return writeOutput(exportOutFile, buff.Bytes())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportInFile, "in", "i", "", "the http file to export")
+	exportCmd.Flags().StringVarP(&exportOutFile, "out", "o", "", "the file to write, default is stdout")
+	exportCmd.Flags().StringVarP(&exportFormat, "format", "f", "curl", "format of output, curl, har or postman")
+	exportCmd.Flags().StringVar(&exportName, "name", "", "name of Postman collection, default is name of http file")
+	exportCmd.Flags().StringVar(&envFile, "env-file", "", "env file of environment variables and oauth2 profiles")
+	exportCmd.Flags().StringVarP(&envName, "env", "e", "", "environment name in env file")
+}
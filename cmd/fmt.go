@@ -0,0 +1,91 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/fantai/ftab/pkg/httpfile"
+	"github.com/spf13/cobra"
+)
+
+var fmtList bool
+
+// fmtCmd represents the fmt command
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [http file...]",
+	Short: "format http files",
+	Long:  `format http files in place, variables are written as @name = value, directives as # @name value, cases are separated by ### and empty lines, comments and directives of a case are kept in order before request line, bodies and curl commands are kept as written, comments between headers are refused, stdin is formatted to stdout if no file is given`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			content, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			formatted, err := formatHTTPFile(content)
+			if err != nil {
+				return err
+			}
+			return writeOutput("", formatted)
+		}
+
+		for _, name := range args {
+			content, err := os.ReadFile(name)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", name, err)
+			}
+			formatted, err := formatHTTPFile(content)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			if bytes.Equal(content, formatted) {
+				continue
+			}
+			if fmtList {
+				fmt.Println(name)
+				continue
+			}
+			if err := writeOutput(name, formatted); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// formatHTTPFile parse content and write it back
+func formatHTTPFile(content []byte) ([]byte, error) {
+	file, err := httpfile.ParseBytes(content)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Release()
+
+	buff := bytes.NewBuffer(nil)
+	if _, err := file.WriteTo(buff); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func init() {
+	rootCmd.AddCommand(fmtCmd)
+
+	fmtCmd.Flags().BoolVarP(&fmtList, "list", "l", false, "list files whose formatting differs, files are not written")
+}
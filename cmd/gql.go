@@ -0,0 +1,83 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/fantai/ftab/pkg/gql"
+	"github.com/spf13/cobra"
+)
+
+var gqlSchemaFile, gqlOutFile, gqlEndpoint string
+var gqlDepth int
+
+// gqlCmd represents the gql command
+var gqlCmd = &cobra.Command{
+	Use:   "gql",
+	Short: "GraphQL tools",
+}
+
+// gqlGenCmd represents the gql gen command
+var gqlGenCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "generate GraphQL cases from schema",
+	Long:  `generate a GraphQL case for each query field from an introspection result or SDL file, selection sets are limited by depth and arguments are mocked`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		schema, err := gql.LoadSchema(gqlSchemaFile)
+		if err != nil {
+			return err
+		}
+
+		buff := bytes.NewBuffer(nil)
+		fmt.Fprintf(buff, "@endpoint = %s\n", gqlEndpoint)
+		for i, q := range gql.Generate(schema, gqlDepth) {
+			if i > 0 {
+				buff.WriteString("\n###\n")
+			}
+			// arguments are file variables, so they are mocked for each request
+			buff.WriteString("\n")
+			for _, v := range q.Variables {
+				fmt.Fprintf(buff, "@%s = %s\n", q.VariableName(v), v.Template)
+			}
+			fmt.Fprintf(buff, "# @name %s\nPOST {{endpoint}}\nX-REQUEST-TYPE: %s\n\n%s\n", q.Field, "GraphQL", q.Query)
+			if variables := q.VariablesTemplate(); variables != "" {
+				fmt.Fprintf(buff, "\n%s\n", variables)
+			}
+		}
+
+		if gqlOutFile == "" {
+			_, err = os.Stdout.Write(buff.Bytes())
+			return err
+		}
+		if err := os.WriteFile(gqlOutFile, buff.Bytes(), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", gqlOutFile, err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gqlCmd)
+	gqlCmd.AddCommand(gqlGenCmd)
+
+	gqlGenCmd.Flags().StringVarP(&gqlSchemaFile, "in", "i", "schema.json", "introspection result json or SDL file")
+	gqlGenCmd.Flags().StringVarP(&gqlOutFile, "out", "o", "", "the http file to write, default is stdout")
+	gqlGenCmd.Flags().StringVar(&gqlEndpoint, "endpoint", "http://127.0.0.1:8080/graphql", "url of GraphQL endpoint")
+	gqlGenCmd.Flags().IntVar(&gqlDepth, "depth", gql.DefaultDepth, "depth of selection sets")
+}
@@ -0,0 +1,219 @@
+/*
+Copyright © 2020 NAME HERE <EMAIL ADDRESS>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/fantai/ftab/pkg/httpfile"
+	"github.com/spf13/cobra"
+)
+
+var importInFile, importOutFile string
+var harExclude string
+var harMinThinkTime time.Duration
+var openAPIBaseURL string
+var postmanEnvironments []string
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "import requests from other formats to http file",
+}
+
+// importCurlCmd represents the import curl command
+var importCurlCmd = &cobra.Command{
+	Use:   "curl [command...]",
+	Short: "import cURL command lines",
+	Long:  `import cURL command lines given as arguments, or read from input file or stdin, each command is a case of http file`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		text := strings.Join(args, "\n")
+		if len(args) == 0 {
+			content, err := readImportInput()
+			if err != nil {
+				return err
+			}
+			text = string(content)
+		}
+
+		cases, err := httpfile.ParseCurl(text)
+		if err != nil {
+			return err
+		}
+		return writeImport(importOutFile, &httpfile.HTTPFile{Cases: cases})
+	},
+}
+
+// importHARCmd represents the import har command
+var importHARCmd = &cobra.Command{
+	Use:   "har",
+	Short: "import a HAR recorded by browser",
+	Long:  `import requests of a HAR in order with think time between them, static assets matched by exclude pattern are skipped, values of responses used by later requests become request variables`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		content, err := readImportInput()
+		if err != nil {
+			return err
+		}
+		opts := httpfile.HAROptions{MinThinkTime: harMinThinkTime}
+		if harExclude != "" {
+			if opts.Exclude, err = regexp.Compile(harExclude); err != nil {
+				return fmt.Errorf("exclude pattern: %w", err)
+			}
+		}
+
+		file, err := httpfile.ImportHAR(content, opts)
+		if err != nil {
+			return err
+		}
+		return writeImport(importOutFile, file)
+	},
+}
+
+// importOpenAPICmd represents the import openapi command
+var importOpenAPICmd = &cobra.Command{
+	Use:   "openapi [spec]",
+	Short: "import an OpenAPI 3 spec",
+	Long:  `import a named case for each operation of an OpenAPI 3 spec in yaml or json, path and query parameters are variables, bodies are examples or mocked from schemas, security schemes give credential headers`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			importInFile = args[0]
+		}
+		content, err := readImportInput()
+		if err != nil {
+			return err
+		}
+
+		file, err := httpfile.ImportOpenAPI(content, httpfile.OpenAPIOptions{BaseURL: openAPIBaseURL})
+		if err != nil {
+			return err
+		}
+		return writeImport(importOutFile, file)
+	},
+}
+
+// importPostmanCmd represents the import postman command
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman [collection]",
+	Short: "import a Postman collection v2.1 and environments",
+	Long:  `import requests of a Postman collection to http files in output directory, requests of each folder are a http file, collection variables and environments are written to env.json, variables set by scripts become variables of http files, unsupported scripts and features are warned`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 0 {
+			importInFile = args[0]
+		}
+		content, err := readImportInput()
+		if err != nil {
+			return err
+		}
+		environments := make([][]byte, 0, len(postmanEnvironments))
+		for _, name := range postmanEnvironments {
+			env, err := os.ReadFile(name)
+			if err != nil {
+				return fmt.Errorf("read %s: %w", name, err)
+			}
+			environments = append(environments, env)
+		}
+
+		result, err := httpfile.ImportPostman(content, environments...)
+		if err != nil {
+			return err
+		}
+		dir := importOutFile
+		if dir == "" {
+			dir = "."
+		}
+		for _, f := range result.Files {
+			fileName := filepath.Join(dir, filepath.FromSlash(f.Path))
+			if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+				return err
+			}
+			if err := writeImport(fileName, f.File); err != nil {
+				return err
+			}
+		}
+		if len(result.Env) > 0 {
+			env, err := json.MarshalIndent(result.Env, "", "    ")
+			if err != nil {
+				return err
+			}
+			if err := writeOutput(filepath.Join(dir, "env.json"), append(env, '\n')); err != nil {
+				return err
+			}
+		}
+		for _, warning := range result.Warnings {
+			fmt.Fprintln(os.Stderr, "warning:", warning)
+		}
+		return nil
+	},
+}
+
+// writeImport format imported file as http file and write it
+func writeImport(fileName string, file *httpfile.HTTPFile) error {
+	buff := bytes.NewBuffer(nil)
+	if _, err := file.WriteTo(buff); err != nil {
+		return err
+	}
+	return writeOutput(fileName, buff.Bytes())
+}
+
+// readImportInput read input file, or stdin if it's not given
+func readImportInput() ([]byte, error) {
+	if importInFile == "" {
+		return io.ReadAll(os.Stdin)
+	}
+	content, err := os.ReadFile(importInFile)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", importInFile, err)
+	}
+	return content, nil
+}
+
+// writeOutput write content to file, or stdout if it's not given
+func writeOutput(fileName string, content []byte) error {
+	if fileName == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	if err := os.WriteFile(fileName, content, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", fileName, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.AddCommand(importCurlCmd)
+	importCmd.AddCommand(importHARCmd)
+	importCmd.AddCommand(importOpenAPICmd)
+	importCmd.AddCommand(importPostmanCmd)
+
+	importCmd.PersistentFlags().StringVarP(&importInFile, "in", "i", "", "the file to import, default is stdin")
+	importCmd.PersistentFlags().StringVarP(&importOutFile, "out", "o", "", "the http file to write, default is stdout, it's the directory of http files for postman")
+
+	importHARCmd.Flags().StringVar(&harExclude, "exclude", httpfile.DefaultHARExclude.String(), "requests with url matched are skipped, empty is import all")
+	importHARCmd.Flags().DurationVar(&harMinThinkTime, "min-think", httpfile.DefaultMinThinkTime, "shorter think time between requests is ignored")
+	importPostmanCmd.Flags().StringSliceVar(&postmanEnvironments, "environment", nil, "Postman environment files, variables are written to env.json by names of environments")
+	importOpenAPICmd.Flags().StringVar(&openAPIBaseURL, "base-url", "", "base url of cases, default is url of first server in spec")
+}
@@ -20,10 +20,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"os"
 	"strings"
 
+	"github.com/fantai/ftab/pkg/gql"
 	"github.com/fantai/ftab/pkg/httpfile"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -36,6 +36,13 @@ var cfgFile, testFile string
 var outputFormat string
 var conns, requests, rateLimit int
 var sandbox bool
+var tlsOptions httpfile.TLSOptions
+var proxyURL string
+var maxRedirects int
+var envFile, envName string
+var graphqlSchema string
+var allowGraphQLErrors bool
+var noThinkTime bool
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -52,20 +59,39 @@ var rootCmd = &cobra.Command{
 		}
 		defer fp.Close()
 
-		file, err := httpfile.ParseReader(fp)
+		var parseOpts []httpfile.Opt
+		if graphqlSchema != "" {
+			schema, err := gql.LoadSchema(graphqlSchema)
+			if err != nil {
+				return err
+			}
+			parseOpts = append(parseOpts, httpfile.WithGraphQLSchema(schema))
+		}
+		if allowGraphQLErrors {
+			parseOpts = append(parseOpts, httpfile.AllowGraphQLErrors)
+		}
+		if noThinkTime {
+			parseOpts = append(parseOpts, httpfile.IgnoreThinkTime)
+		}
+
+		file, err := httpfile.ParseReader(fp, parseOpts...)
 		if err != nil {
 			return fmt.Errorf("parse file: %w", err)
 		}
 		defer file.Release()
 
-		if sandbox {
-			fp, err := os.Open(testFile)
+		if envFile != "" {
+			file.Env, err = httpfile.LoadEnvironment(envFile, envName)
 			if err != nil {
-				return fmt.Errorf("open file: %w", err)
+				return err
 			}
-			defer fp.Close()
+		}
+
+		if sandbox {
 			buff := bytes.NewBuffer(nil)
-			io.Copy(buff, fp)
+			if _, err := file.WriteTo(buff); err != nil {
+				return err
+			}
 
 			body := buff.Bytes()
 
@@ -80,8 +106,13 @@ var rootCmd = &cobra.Command{
 			rateLimit = 0
 		}
 
+		opts, err := clientOpts()
+		if err != nil {
+			return err
+		}
+
 		if requests > 1 {
-			r := httpfile.ReportStat(httpfile.Bench(file, conns, requests, rateLimit))
+			r := httpfile.ReportStat(httpfile.Bench(file, conns, requests, rateLimit, opts...))
 			r.Currency = conns
 			r.RateLimit = rateLimit
 
@@ -98,7 +129,7 @@ var rootCmd = &cobra.Command{
 				httpfile.HumanOutput(&r, os.Stdout)
 			}
 		} else {
-			traceInfo := httpfile.Execute(file)
+			traceInfo := httpfile.Execute(file, opts...)
 			fmt.Println(traceInfo)
 		}
 
@@ -106,6 +137,19 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// clientOpts build client options from flags
+func clientOpts() ([]httpfile.ClientOpt, error) {
+	tlsConfig, err := tlsOptions.Config()
+	if err != nil {
+		return nil, fmt.Errorf("tls config: %w", err)
+	}
+	return []httpfile.ClientOpt{
+		httpfile.WithTLSConfig(tlsConfig),
+		httpfile.WithProxy(proxyURL),
+		httpfile.WithMaxRedirects(maxRedirects),
+	}, nil
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
@@ -129,11 +173,27 @@ func init() {
 
 	rootCmd.Flags().StringVarP(&outputFormat, "output", "m", "human", "result output format[plain, human, json]")
 	rootCmd.Flags().StringVarP(&testFile, "in", "i", "test.http", "the http file to bench")
+	rootCmd.Flags().StringVar(&envFile, "env-file", "", "env file of environment variables and oauth2 profiles")
+	rootCmd.Flags().StringVarP(&envName, "env", "e", "", "environment name in env file")
+	rootCmd.Flags().StringVar(&graphqlSchema, "graphql-schema", "", "SDL or introspection json file to validate GraphQL cases")
+	rootCmd.Flags().BoolVar(&allowGraphQLErrors, "allow-graphql-errors", false, "don't fail GraphQL cases by errors in response")
+	rootCmd.Flags().BoolVar(&noThinkTime, "no-think-time", false, "don't wait think time of cases given by # @think")
 	rootCmd.Flags().IntVarP(&conns, "connections", "c", 1, "connection in this bench ")
 	rootCmd.Flags().IntVarP(&requests, "requests", "n", 1, "total requests in this bench ")
 	rootCmd.Flags().IntVarP(&rateLimit, "rate", "r", 0, "requtes per second limit, <= 0 is no limit")
 	rootCmd.Flags().BoolVarP(&sandbox, "sandbox", "s", false, "print case but don't execute")
 
+	rootCmd.Flags().StringVar(&tlsOptions.CACert, "cacert", "", "CA certificate file to verify server")
+	rootCmd.Flags().StringVar(&tlsOptions.Cert, "cert", "", "client certificate file")
+	rootCmd.Flags().StringVar(&tlsOptions.Key, "key", "", "client private key file")
+	rootCmd.Flags().BoolVarP(&tlsOptions.Insecure, "insecure", "k", false, "skip verify server certificate")
+	rootCmd.Flags().StringVar(&tlsOptions.ServerName, "server-name", "", "server name indication, default is host of url")
+	rootCmd.Flags().StringVar(&tlsOptions.MinVersion, "tls-min", "", "minimum tls version[1.0, 1.1, 1.2, 1.3]")
+	rootCmd.Flags().StringVar(&tlsOptions.MaxVersion, "tls-max", "", "maximum tls version[1.0, 1.1, 1.2, 1.3]")
+	rootCmd.Flags().IntVar(&maxRedirects, "max-redirects", httpfile.DefaultMaxRedirects, "max redirects followed by a case, 0 is not follow")
+	rootCmd.Flags().StringVarP(&proxyURL, "proxy", "x", "", "proxy url[http://, https://, socks5://], default is from HTTP_PROXY and HTTPS_PROXY")
+	rootCmd.Flags().StringSliceVar(&tlsOptions.CipherSuites, "ciphers", nil, "cipher suites, separated by comma")
+
 	viper.BindPFlags(rootCmd.Flags())
 
 }
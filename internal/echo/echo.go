@@ -18,7 +18,7 @@ func echoHandler(ctx *fasthttp.RequestCtx) {
 	}
 
 	ctx.Request.Header.VisitAll(func(key, val []byte) {
-		if bytes.HasPrefix(key, []byte("echo-")) {
+		if bytes.HasPrefix(bytes.ToLower(key), []byte("echo-")) {
 			ctx.Response.Header.AddBytesKV(key, val)
 		}
 	})
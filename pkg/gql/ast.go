@@ -0,0 +1,187 @@
+package gql
+
+// Document is an executable GraphQL document
+type Document struct {
+	Operations []*OperationDefinition
+	Fragments  []*FragmentDefinition
+}
+
+// Operation return operation by name, the only operation if name is empty
+func (d *Document) Operation(name string) *OperationDefinition {
+	if name == "" {
+		if len(d.Operations) == 1 {
+			return d.Operations[0]
+		}
+		return nil
+	}
+	for _, op := range d.Operations {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
+
+// Fragment return fragment by name, nil if not found
+func (d *Document) Fragment(name string) *FragmentDefinition {
+	for _, f := range d.Fragments {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// OperationType is query, mutation or subscription
+type OperationType string
+
+// operation types
+const (
+	Query        OperationType = "query"
+	Mutation     OperationType = "mutation"
+	Subscription OperationType = "subscription"
+)
+
+// OperationDefinition is a query, mutation or subscription, name is empty if anonymous
+type OperationDefinition struct {
+	Type                OperationType
+	Name                string
+	VariableDefinitions []*VariableDefinition
+	Directives          []*Directive
+	SelectionSet        []Selection
+	Pos                 Position
+}
+
+// VariableDefinition is $name: Type = default
+type VariableDefinition struct {
+	Name         string
+	Type         *Type
+	DefaultValue *Value
+	Directives   []*Directive
+	Pos          Position
+}
+
+// FragmentDefinition is fragment Name on Type { ... }
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Pos           Position
+}
+
+// Selection is *Field, *FragmentSpread or *InlineFragment
+type Selection interface {
+	position() Position
+}
+
+// Field is alias: name(arguments) @directives { selections }
+type Field struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*Directive
+	SelectionSet []Selection
+	Pos          Position
+}
+
+// ResponseKey is alias or name of field
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// FragmentSpread is ...Name @directives
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+	Pos        Position
+}
+
+// InlineFragment is ... on Type @directives { selections }, type condition is optional
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+	Pos           Position
+}
+
+func (f *Field) position() Position          { return f.Pos }
+func (f *FragmentSpread) position() Position { return f.Pos }
+func (f *InlineFragment) position() Position { return f.Pos }
+
+// Argument is name: value
+type Argument struct {
+	Name  string
+	Value *Value
+	Pos   Position
+}
+
+// Directive is @name(arguments)
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Pos       Position
+}
+
+// ValueKind is kind of value
+type ValueKind int
+
+// kinds of value
+const (
+	VariableValue ValueKind = iota
+	IntLiteral
+	FloatLiteral
+	StringLiteral
+	BooleanLiteral
+	NullLiteral
+	EnumLiteral
+	ListLiteral
+	ObjectLiteral
+)
+
+// Value is a literal or variable, Raw is name of variable or enum, text of number,
+// unescaped string, true or false, children are items of list or fields of object
+type Value struct {
+	Kind   ValueKind
+	Raw    string
+	List   []*Value
+	Fields []*ObjectField
+	Pos    Position
+}
+
+// ObjectField is name: value of object literal
+type ObjectField struct {
+	Name  string
+	Value *Value
+	Pos   Position
+}
+
+// Type is a named, list or non null type reference
+type Type struct {
+	Name    string // name of named type, empty for list
+	Elem    *Type  // item type of list
+	NonNull bool
+	Pos     Position
+}
+
+// NamedType is the innermost name of type, [Int!]! is Int
+func (t *Type) NamedType() string {
+	for t.Elem != nil {
+		t = t.Elem
+	}
+	return t.Name
+}
+
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
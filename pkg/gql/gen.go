@@ -0,0 +1,253 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/rand"
+	"strings"
+)
+
+// DefaultDepth is default depth of generated selection sets
+const DefaultDepth = 2
+
+// GeneratedQuery is a query generated for a field of query type
+type GeneratedQuery struct {
+	Field     string
+	Query     string
+	Variables []GeneratedVariable
+}
+
+// GeneratedVariable is a variable of generated query, template is json of value for http
+// file, numbers, ids, dates and strings are functions like {{$randomInt 1 100}}
+type GeneratedVariable struct {
+	Name     string
+	Template string
+}
+
+// VariableName is name of file variable holding value of v, like human_id
+func (q *GeneratedQuery) VariableName(v GeneratedVariable) string {
+	return q.Field + "_" + v.Name
+}
+
+// VariablesTemplate format variables as json object referring file variables named
+// by VariableName, so they are mocked for each request, empty if query has no variable
+func (q *GeneratedQuery) VariablesTemplate() string {
+	if len(q.Variables) == 0 {
+		return ""
+	}
+	buf := bytes.NewBufferString("{\n")
+	for i, v := range q.Variables {
+		name, _ := json.Marshal(v.Name)
+		buf.WriteString("  ")
+		buf.Write(name)
+		buf.WriteString(": {{" + q.VariableName(v) + "}}")
+		if i < len(q.Variables)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
+
+// generator build queries, selection sets are limited by depth, fields have required
+// arguments are skipped except root fields
+type generator struct {
+	schema *Schema
+	depth  int
+}
+
+// Generate a query for each field of query type, required arguments of field are
+// variables with mocked templates, depth is levels of selection sets, DefaultDepth if <= 0
+func Generate(schema *Schema, depth int) []*GeneratedQuery {
+	if depth <= 0 {
+		depth = DefaultDepth
+	}
+	g := &generator{schema: schema, depth: depth}
+	root := schema.RootType(Query)
+	if root == nil {
+		return nil
+	}
+
+	var queries []*GeneratedQuery
+	for _, f := range root.Fields {
+		if strings.HasPrefix(f.Name, "__") {
+			continue
+		}
+		if q := g.query(f); q != nil {
+			queries = append(queries, q)
+		}
+	}
+	return queries
+}
+
+func (g *generator) query(f *FieldDefinition) *GeneratedQuery {
+	q := &GeneratedQuery{Field: f.Name}
+	var defs, args []string
+	for _, arg := range f.Args {
+		if !required(arg) {
+			continue
+		}
+		tpl, ok := g.mockValue(arg.Name, arg.Type, g.depth)
+		if !ok {
+			return nil
+		}
+		defs = append(defs, "$"+arg.Name+": "+arg.Type.String())
+		args = append(args, arg.Name+": $"+arg.Name)
+		q.Variables = append(q.Variables, GeneratedVariable{Name: arg.Name, Template: tpl})
+	}
+
+	var sb strings.Builder
+	sb.WriteString("query " + f.Name)
+	if len(defs) > 0 {
+		sb.WriteString("(" + strings.Join(defs, ", ") + ")")
+	}
+	sb.WriteString(" {\n  " + f.Name)
+	if len(args) > 0 {
+		sb.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	if t, ok := g.schema.Types[f.Type.NamedType()]; ok && t.IsComposite() {
+		sb.WriteString(" ")
+		g.selectionSet(&sb, t, g.depth, "  ")
+	}
+	sb.WriteString("\n}")
+	q.Query = sb.String()
+	return q
+}
+
+func required(arg *InputValueDefinition) bool {
+	return arg.Type.NonNull && arg.DefaultValue == nil
+}
+
+// selectionSet write { ... } of composite type, leaf fields are selected at each level,
+// composite fields are selected while depth is more than 1
+func (g *generator) selectionSet(sb *strings.Builder, t *TypeDefinition, depth int, indent string) {
+	inner := indent + "  "
+	sb.WriteString("{\n")
+	if t.Kind != Object {
+		sb.WriteString(inner + "__typename\n")
+	}
+	if t.Kind == Union {
+		if depth > 1 {
+			for _, name := range t.PossibleTypes {
+				if member, ok := g.schema.Types[name]; ok {
+					sb.WriteString(inner + "... on " + name + " ")
+					g.selectionSet(sb, member, depth-1, inner)
+					sb.WriteString("\n")
+				}
+			}
+		}
+		sb.WriteString(indent + "}")
+		return
+	}
+
+	selected := 0
+	for _, f := range t.Fields {
+		if strings.HasPrefix(f.Name, "__") || hasRequiredArgs(f) {
+			continue
+		}
+		ft, ok := g.schema.Types[f.Type.NamedType()]
+		if !ok {
+			continue
+		}
+		if !ft.IsComposite() {
+			sb.WriteString(inner + f.Name + "\n")
+			selected++
+			continue
+		}
+		if depth > 1 {
+			sb.WriteString(inner + f.Name + " ")
+			g.selectionSet(sb, ft, depth-1, inner)
+			sb.WriteString("\n")
+			selected++
+		}
+	}
+	if selected == 0 && t.Kind == Object {
+		sb.WriteString(inner + "__typename\n")
+	}
+	sb.WriteString(indent + "}")
+}
+
+func hasRequiredArgs(f *FieldDefinition) bool {
+	for _, arg := range f.Args {
+		if required(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// mockValue mock template of input type, input objects have required fields only, false
+// if value can't be mocked, like input object nested deeper than depth
+func (g *generator) mockValue(name string, t *Type, depth int) (string, bool) {
+	if t.Elem != nil {
+		tpl, ok := g.mockValue(name, t.Elem, depth)
+		if !ok {
+			return "", false
+		}
+		return "[" + tpl + "]", true
+	}
+
+	def, ok := g.schema.Types[t.Name]
+	if !ok {
+		return "", false
+	}
+	switch def.Kind {
+	case Enum:
+		if len(def.EnumValues) == 0 {
+			return "", false
+		}
+		return marshal(def.EnumValues[rand.Intn(len(def.EnumValues))]), true
+	case InputObject:
+		if depth <= 0 {
+			return "", false
+		}
+		var fields []string
+		for _, f := range def.InputFields {
+			if !required(f) {
+				continue
+			}
+			tpl, ok := g.mockValue(f.Name, f.Type, depth-1)
+			if !ok {
+				return "", false
+			}
+			fields = append(fields, marshal(f.Name)+": "+tpl)
+		}
+		return "{" + strings.Join(fields, ", ") + "}", true
+	case Scalar:
+		return mockScalar(name, def.Name), true
+	}
+	return "", false
+}
+
+func marshal(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
+
+// mockScalar mock template of built-in scalars, string is mocked by name of argument,
+// custom scalars like Date and DateTime are mocked by name of scalar, templates are
+// functions, so values are different for each request except booleans and enums
+func mockScalar(name, scalar string) string {
+	switch scalar {
+	case "Int", "Float":
+		return "{{$randomInt 1 100}}"
+	case "Boolean":
+		return marshal(rand.Intn(2) == 1)
+	case "ID":
+		return `"{{$randomInt 1 10000}}"`
+	}
+
+	lower := strings.ToLower(scalar)
+	switch {
+	case strings.Contains(lower, "datetime") || strings.Contains(lower, "timestamp"):
+		return `"{{$datetime rfc3339 {{$randomInt -30 60}} d}}"`
+	case strings.Contains(lower, "date"):
+		return `"{{$datetime YYYY-MM-DD {{$randomInt -30 60}} d}}"`
+	case strings.Contains(lower, "time"):
+		return `"{{$datetime HH:mm:ss {{$randomInt -720 1440}} m}}"`
+	case lower == "json":
+		return "{}"
+	}
+	return `"{{$mock ` + name + `}}"`
+}
@@ -0,0 +1,89 @@
+package gql
+
+import (
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	s := MustParseSchema(starWarsSchema + `
+	input Filter { text: String! episode: Episode! limit: Int }
+	extend type Query { find(filter: Filter!, since: Date!): [Character] }
+	`)
+
+	queries := Generate(s, 0)
+	fields := make([]string, len(queries))
+	for i, q := range queries {
+		fields[i] = q.Field
+		doc, err := Parse(q.Query)
+		if assert.NoError(t, err, q.Query) {
+			assert.NoError(t, Validate(s, doc), q.Query)
+		}
+		if len(q.Variables) > 0 {
+			// templates are json once placeholders are expanded
+			for _, v := range q.Variables {
+				assert.True(t, json.Valid([]byte(expandPlaceholders(v.Template))), v.Template)
+			}
+			var variables map[string]interface{}
+			text := expandPlaceholders(q.VariablesTemplate())
+			assert.NoError(t, json.Unmarshal([]byte(text), &variables), text)
+			assert.Len(t, variables, len(q.Variables))
+		} else {
+			assert.Equal(t, "", q.VariablesTemplate())
+		}
+	}
+	assert.Equal(t, []string{"hero", "human", "search", "today", "find"}, fields)
+
+	assert.Equal(t, `query hero {
+  hero {
+    __typename
+    id
+    name
+    friends {
+      __typename
+      id
+      name
+      appearsIn
+    }
+    appearsIn
+  }
+}`, queries[0].Query)
+	assert.Equal(t, "query today {\n  today\n}", queries[3].Query)
+
+	human := queries[1]
+	assert.Contains(t, human.Query, "query human($id: ID!) {\n  human(id: $id) {")
+	assert.Equal(t, "id", human.Variables[0].Name)
+	assert.Equal(t, `"{{$randomInt 1 10000}}"`, human.Variables[0].Template)
+	assert.Equal(t, "human_id", human.VariableName(human.Variables[0]))
+	assert.Equal(t, "{\n  \"id\": {{human_id}}\n}", human.VariablesTemplate())
+
+	search := queries[2]
+	assert.Equal(t, `"{{$mock text}}"`, search.Variables[0].Template)
+	assert.Contains(t, search.Query, "query search($text: String!) {\n  search(text: $text) {")
+	assert.Contains(t, search.Query, "    ... on Starship {\n      id\n      name\n    }\n")
+
+	find := queries[4]
+	filter := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal([]byte(expandPlaceholders(find.Variables[0].Template)), &filter))
+	assert.Contains(t, find.Variables[0].Template, `"text": "{{$mock text}}"`)
+	assert.Contains(t, []interface{}{"NEWHOPE", "EMPIRE", "JEDI"}, filter["episode"])
+	assert.NotContains(t, filter, "limit")
+	assert.Equal(t, `"{{$datetime YYYY-MM-DD {{$randomInt -30 60}} d}}"`, find.Variables[1].Template)
+
+	deep := Generate(s, 3)[0].Query
+	assert.Contains(t, deep, "      friends {\n        __typename\n")
+	shallow := Generate(s, 1)[0].Query
+	assert.NotContains(t, shallow, "friends")
+}
+
+// expandPlaceholders replace placeholders from the innermost with 1
+func expandPlaceholders(text string) string {
+	placeholder := regexp.MustCompile(`\{\{[^{}]*\}\}`)
+	for placeholder.MatchString(text) {
+		text = placeholder.ReplaceAllString(text, "1")
+	}
+	return text
+}
@@ -1,7 +0,0 @@
-%{
-
-pacakge gql
-
-%}
-
-%%
\ No newline at end of file
@@ -0,0 +1,176 @@
+package gql
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// introspection types, only parts used by schema are decoded
+type introspectionResult struct {
+	Data   *introspectionResult `json:"data"`
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef `json:"queryType"`
+	MutationType     *introspectionTypeRef `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef `json:"subscriptionType"`
+	Types            []introspectionType   `json:"types"`
+	Directives       []struct {
+		Name      string                    `json:"name"`
+		Locations []string                  `json:"locations"`
+		Args      []introspectionInputValue `json:"args"`
+	} `json:"directives"`
+}
+
+type introspectionType struct {
+	Kind          TypeKind                  `json:"kind"`
+	Name          string                    `json:"name"`
+	Fields        []introspectionField      `json:"fields"`
+	Interfaces    []introspectionTypeRef    `json:"interfaces"`
+	PossibleTypes []introspectionTypeRef    `json:"possibleTypes"`
+	EnumValues    []introspectionEnumValue  `json:"enumValues"`
+	InputFields   []introspectionInputValue `json:"inputFields"`
+}
+
+type introspectionEnumValue struct {
+	Name string `json:"name"`
+}
+
+type introspectionField struct {
+	Name string                    `json:"name"`
+	Args []introspectionInputValue `json:"args"`
+	Type *introspectionTypeRef     `json:"type"`
+}
+
+type introspectionInputValue struct {
+	Name         string                `json:"name"`
+	Type         *introspectionTypeRef `json:"type"`
+	DefaultValue *string               `json:"defaultValue"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+// ParseIntrospection parse result of introspection query, both {"data": {"__schema": ...}}
+// and {"__schema": ...} are accepted
+func ParseIntrospection(data []byte) (*Schema, error) {
+	var result introspectionResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	is := result.Schema
+	if result.Data != nil {
+		is = result.Data.Schema
+	}
+	if is == nil {
+		return nil, fmt.Errorf("introspection: __schema is not found")
+	}
+
+	s := newSchema()
+	s.QueryType = is.QueryType.name()
+	s.MutationType = is.MutationType.name()
+	s.SubscriptionType = is.SubscriptionType.name()
+
+	for _, it := range is.Types {
+		t := &TypeDefinition{Kind: it.Kind, Name: it.Name}
+		for _, f := range it.Fields {
+			field := &FieldDefinition{Name: f.Name}
+			var err error
+			if field.Type, err = f.Type.toType(); err != nil {
+				return nil, fmt.Errorf("introspection: field %s.%s: %w", it.Name, f.Name, err)
+			}
+			if field.Args, err = toInputValues(f.Args); err != nil {
+				return nil, fmt.Errorf("introspection: field %s.%s: %w", it.Name, f.Name, err)
+			}
+			t.Fields = append(t.Fields, field)
+		}
+		for _, ref := range it.Interfaces {
+			t.Interfaces = append(t.Interfaces, ref.Name)
+		}
+		for _, ref := range it.PossibleTypes {
+			t.PossibleTypes = append(t.PossibleTypes, ref.Name)
+		}
+		for _, v := range it.EnumValues {
+			t.EnumValues = append(t.EnumValues, v.Name)
+		}
+		var err error
+		if t.InputFields, err = toInputValues(it.InputFields); err != nil {
+			return nil, fmt.Errorf("introspection: input %s: %w", it.Name, err)
+		}
+		s.Types[t.Name] = t
+	}
+	for _, d := range is.Directives {
+		args, err := toInputValues(d.Args)
+		if err != nil {
+			return nil, fmt.Errorf("introspection: directive %s: %w", d.Name, err)
+		}
+		s.Directives[d.Name] = &DirectiveDefinition{Name: d.Name, Args: args, Locations: d.Locations}
+	}
+	if err := s.finish(); err != nil {
+		return nil, fmt.Errorf("introspection: %w", err)
+	}
+	return s, nil
+}
+
+func (r *introspectionTypeRef) name() string {
+	if r == nil {
+		return ""
+	}
+	return r.Name
+}
+
+// toType convert type reference to type, NON_NULL and LIST are wrappers
+func (r *introspectionTypeRef) toType() (*Type, error) {
+	if r == nil {
+		return nil, fmt.Errorf("type is missing")
+	}
+	switch r.Kind {
+	case "NON_NULL":
+		t, err := r.OfType.toType()
+		if err != nil {
+			return nil, err
+		}
+		if t.NonNull {
+			return nil, fmt.Errorf("non null of non null type")
+		}
+		t.NonNull = true
+		return t, nil
+	case "LIST":
+		t, err := r.OfType.toType()
+		if err != nil {
+			return nil, err
+		}
+		return &Type{Elem: t}, nil
+	}
+	if r.Name == "" {
+		return nil, fmt.Errorf("type %s has no name", r.Kind)
+	}
+	return &Type{Name: r.Name}, nil
+}
+
+// toInputValues convert arguments or input fields, default value is parsed as constant value
+func toInputValues(values []introspectionInputValue) ([]*InputValueDefinition, error) {
+	var result []*InputValueDefinition
+	for _, v := range values {
+		t, err := v.Type.toType()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", v.Name, err)
+		}
+		def := &InputValueDefinition{Name: v.Name, Type: t}
+		if v.DefaultValue != nil {
+			p, err := newParser(*v.DefaultValue)
+			if err == nil {
+				def.DefaultValue, err = p.parseValue(true)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%s default value: %w", v.Name, err)
+			}
+		}
+		result = append(result, def)
+	}
+	return result, nil
+}
@@ -1,11 +1,9 @@
 package gql
 
 import (
-	"fmt"
-	"strings"
 	"testing"
-	"text/scanner"
-	"unicode"
+
+	"github.com/stretchr/testify/assert"
 )
 
 var _gqlTestSample = `
@@ -20,19 +18,46 @@ query HeroComparison($first: Int = 3) {
 `
 
 func TestLex(t *testing.T) {
-
-	var s scanner.Scanner
-
-	s.IsIdentRune = func(ch rune, i int) bool {
-		return ch == '$' && i == 0 || unicode.IsLetter(ch) || unicode.IsDigit(ch) && i > 0
+	l := newLexer(_gqlTestSample)
+
+	var values []string
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.Kind == EOF {
+			break
+		}
+		values = append(values, tok.Value)
 	}
+	assert.Equal(t, []string{"query", "HeroComparison", "(", "$", "first", ":", "Int", "=", "3", ")", "{",
+		"leftComparison", ":", "hero", "(", "episode", ":", "EMPIRE", ")", "{", "...", "comparisonFields", "}",
+		"rightComparison", ":", "hero", "(", "episode", ":", "JEDI", ")", "{", "...", "comparisonFields", "}",
+		"}"}, values)
+
+	l = newLexer("\n  a(x: -1.5e3, s: \"\\u00e9\\n\", b: \"\"\"\n    one\n      two\n  \"\"\") # comment\n  b")
+	var tokens []Token
+	for {
+		tok, err := l.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tok.Kind == EOF {
+			break
+		}
+		tokens = append(tokens, tok)
+	}
+	assert.Equal(t, Token{Kind: Name, Value: "a", Pos: Position{Line: 2, Column: 3}}, tokens[0])
+	assert.Equal(t, Token{Kind: FloatValue, Value: "-1.5e3", Pos: Position{Line: 2, Column: 8}}, tokens[4])
+	assert.Equal(t, "é\n", tokens[7].Value)
+	assert.Equal(t, Token{Kind: BlockString, Value: "one\n  two", Pos: Position{Line: 2, Column: 34}}, tokens[10])
+	assert.Equal(t, Token{Kind: Name, Value: "b", Pos: Position{Line: 6, Column: 3}}, tokens[12])
+}
 
-	r := strings.NewReader(_gqlTestSample)
-
-	s.Init(r)
-	s.Filename = "default"
-
-	for tok := s.Scan(); tok != scanner.EOF; tok = s.Scan() {
-		fmt.Printf("%s: %s\n", s.Position, s.TokenText())
+func TestLexInvalid(t *testing.T) {
+	for _, src := range []string{`01`, `1.`, `1e`, `1a`, `.5`, `..`, `"abc`, "\"a\nb\"", `"\x"`, `"\uD800"`, `"""a`, `?`, `é`} {
+		_, err := newLexer(src).Next()
+		assert.Error(t, err, src)
 	}
 }
@@ -0,0 +1,351 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is kind of token
+type Kind int
+
+// kinds of token
+const (
+	EOF Kind = iota
+	Punctuator
+	Name
+	IntValue
+	FloatValue
+	StringValue
+	BlockString
+)
+
+var kindNames = [...]string{"EOF", "Punctuator", "Name", "Int", "Float", "String", "BlockString"}
+
+func (k Kind) String() string {
+	return kindNames[k]
+}
+
+// Position is line and column of token, both start at 1
+type Position struct {
+	Line   int
+	Column int
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token is a lexical token, value of string is unescaped
+type Token struct {
+	Kind  Kind
+	Value string
+	Pos   Position
+}
+
+// Error is a syntax or validation error at a position of document
+type Error struct {
+	Message string
+	Pos     Position
+}
+
+func (e *Error) Error() string {
+	if e.Pos.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+// lexer split GraphQL source to tokens, white space, line terminators, commas and comments are ignored
+type lexer struct {
+	src  string
+	pos  int
+	line int
+	col  int // byte offset of line start
+}
+
+func newLexer(src string) *lexer {
+	src = strings.TrimPrefix(src, "\uFEFF")
+	return &lexer{src: src, line: 1}
+}
+
+func (l *lexer) position(offset int) Position {
+	return Position{Line: l.line, Column: offset - l.col + 1}
+}
+
+func (l *lexer) errorf(offset int, format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...), Pos: l.position(offset)}
+}
+
+func (l *lexer) newLine(next int) {
+	l.line++
+	l.col = next
+}
+
+// skipIgnored skip ignored tokens
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newLine(l.pos)
+		case '\r':
+			l.pos++
+			if l.pos < len(l.src) && l.src[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newLine(l.pos)
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+// Next return next token, EOF at end of source
+func (l *lexer) Next() (Token, error) {
+	l.skipIgnored()
+	start := l.pos
+	if start >= len(l.src) {
+		return Token{Kind: EOF, Pos: l.position(start)}, nil
+	}
+
+	c := l.src[start]
+	switch {
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return Token{Kind: Punctuator, Value: string(c), Pos: l.position(start)}, nil
+	case c == '.':
+		if !strings.HasPrefix(l.src[start:], "...") {
+			return Token{}, l.errorf(start, "unexpected character %q", c)
+		}
+		l.pos += 3
+		return Token{Kind: Punctuator, Value: "...", Pos: l.position(start)}, nil
+	case isNameStart(c):
+		for l.pos < len(l.src) && isNameContinue(l.src[l.pos]) {
+			l.pos++
+		}
+		return Token{Kind: Name, Value: l.src[start:l.pos], Pos: l.position(start)}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		if strings.HasPrefix(l.src[start:], `"""`) {
+			return l.blockString()
+		}
+		return l.string()
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[start:])
+	return Token{}, l.errorf(start, "unexpected character %q", r)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// number scan IntValue or FloatValue, leading zero is not allowed and name can't follow number
+func (l *lexer) number() (Token, error) {
+	start := l.pos
+	kind := IntValue
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '0' {
+		l.pos++
+		if l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			return Token{}, l.errorf(start, "invalid number, unexpected digit after 0")
+		}
+	} else if err := l.digits(start); err != nil {
+		return Token{}, err
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = FloatValue
+		l.pos++
+		if err := l.digits(start); err != nil {
+			return Token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = FloatValue
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if err := l.digits(start); err != nil {
+			return Token{}, err
+		}
+	}
+	if l.pos < len(l.src) && (isNameStart(l.src[l.pos]) || l.src[l.pos] == '.') {
+		return Token{}, l.errorf(start, "invalid number, unexpected %q", l.src[l.pos])
+	}
+	return Token{Kind: kind, Value: l.src[start:l.pos], Pos: l.position(start)}, nil
+}
+
+func (l *lexer) digits(start int) error {
+	if l.pos >= len(l.src) || !isDigit(l.src[l.pos]) {
+		return l.errorf(start, "invalid number, expect digit")
+	}
+	for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+		l.pos++
+	}
+	return nil
+}
+
+// string scan a quoted string and unescape it
+func (l *lexer) string() (Token, error) {
+	start := l.pos
+	l.pos++
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return Token{Kind: StringValue, Value: sb.String(), Pos: l.position(start)}, nil
+		case c == '\n' || c == '\r':
+			return Token{}, l.errorf(start, "unterminated string")
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return Token{}, l.errorf(start, "unterminated string")
+			}
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				sb.WriteByte(esc)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				r, err := l.unicode(start)
+				if err != nil {
+					return Token{}, err
+				}
+				sb.WriteRune(r)
+			default:
+				return Token{}, l.errorf(l.pos-2, "invalid escape \\%c", esc)
+			}
+		default:
+			sb.WriteByte(c)
+			l.pos++
+		}
+	}
+	return Token{}, l.errorf(start, "unterminated string")
+}
+
+// unicode parse \uXXXX or \u{X...} after \u, surrogate pair is combined
+func (l *lexer) unicode(start int) (rune, error) {
+	if strings.HasPrefix(l.src[l.pos:], "{") {
+		end := strings.IndexByte(l.src[l.pos:], '}')
+		if end < 0 {
+			return 0, l.errorf(start, "invalid unicode escape")
+		}
+		v, err := strconv.ParseUint(l.src[l.pos+1:l.pos+end], 16, 32)
+		if err != nil || v > utf8.MaxRune || v >= 0xD800 && v <= 0xDFFF {
+			return 0, l.errorf(start, "invalid unicode escape")
+		}
+		l.pos += end + 1
+		return rune(v), nil
+	}
+	hex4 := func() (rune, bool) {
+		if l.pos+4 > len(l.src) {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+		if err != nil {
+			return 0, false
+		}
+		l.pos += 4
+		return rune(v), true
+	}
+	r, ok := hex4()
+	if !ok {
+		return 0, l.errorf(start, "invalid unicode escape")
+	}
+	if r >= 0xD800 && r <= 0xDBFF && strings.HasPrefix(l.src[l.pos:], `\u`) {
+		l.pos += 2
+		low, ok := hex4()
+		if !ok || low < 0xDC00 || low > 0xDFFF {
+			return 0, l.errorf(start, "invalid unicode surrogate pair")
+		}
+		return (r-0xD800)<<10 + (low - 0xDC00) + 0x10000, nil
+	}
+	if r >= 0xD800 && r <= 0xDFFF {
+		return 0, l.errorf(start, "invalid unicode surrogate")
+	}
+	return r, nil
+}
+
+// blockString scan """...""", only \""" is escaped, indentation is removed
+func (l *lexer) blockString() (Token, error) {
+	start := l.pos
+	pos := l.position(start)
+	l.pos += 3
+	var sb strings.Builder
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], `"""`):
+			l.pos += 3
+			return Token{Kind: BlockString, Value: blockStringValue(sb.String()), Pos: pos}, nil
+		case strings.HasPrefix(l.src[l.pos:], `\"""`):
+			sb.WriteString(`"""`)
+			l.pos += 4
+		default:
+			c := l.src[l.pos]
+			sb.WriteByte(c)
+			l.pos++
+			if c == '\n' || c == '\r' && (l.pos >= len(l.src) || l.src[l.pos] != '\n') {
+				l.newLine(l.pos)
+			}
+		}
+	}
+	return Token{}, l.errorf(start, "unterminated block string")
+}
+
+// blockStringValue remove common indentation and leading, trailing blank lines
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(raw, "\r\n", "\n"), "\r", "\n"), "\n")
+	common := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent < len(line) && (common < 0 || indent < common) {
+			common = indent
+		}
+	}
+	if common > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) < common {
+				lines[i] = ""
+			} else {
+				lines[i] = lines[i][common:]
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
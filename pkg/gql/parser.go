@@ -0,0 +1,489 @@
+package gql
+
+import (
+	"fmt"
+)
+
+// parser is a recursive descent parser of GraphQL documents with one token look ahead
+type parser struct {
+	lex *lexer
+	tok Token
+}
+
+func newParser(src string) (*parser, error) {
+	p := &parser{lex: newLexer(src)}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *parser) next() error {
+	tok, err := p.lex.Next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &Error{Message: fmt.Sprintf(format, args...), Pos: p.tok.Pos}
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.Kind == EOF {
+		return p.errorf("unexpected end of document")
+	}
+	return p.errorf("unexpected %s %q", p.tok.Kind, p.tok.Value)
+}
+
+// peek check current token is punctuator
+func (p *parser) peek(punct string) bool {
+	return p.tok.Kind == Punctuator && p.tok.Value == punct
+}
+
+// peekKeyword check current token is name
+func (p *parser) peekKeyword(keyword string) bool {
+	return p.tok.Kind == Name && p.tok.Value == keyword
+}
+
+// skip punctuator if it's current token
+func (p *parser) skip(punct string) (bool, error) {
+	if !p.peek(punct) {
+		return false, nil
+	}
+	return true, p.next()
+}
+
+func (p *parser) expect(punct string) error {
+	if p.tok.Kind == EOF {
+		return p.unexpected()
+	}
+	if !p.peek(punct) {
+		return p.errorf("expected %q, found %s %q", punct, p.tok.Kind, p.tok.Value)
+	}
+	return p.next()
+}
+
+func (p *parser) expectKeyword(keyword string) error {
+	if !p.peekKeyword(keyword) {
+		return p.errorf("expected %q, found %s %q", keyword, p.tok.Kind, p.tok.Value)
+	}
+	return p.next()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.Kind == EOF {
+		return "", p.unexpected()
+	}
+	if p.tok.Kind != Name {
+		return "", p.errorf("expected Name, found %s %q", p.tok.Kind, p.tok.Value)
+	}
+	name := p.tok.Value
+	return name, p.next()
+}
+
+// Parse parse an executable document, it must have at least one operation or fragment,
+// type system definitions are not allowed
+func Parse(src string) (*Document, error) {
+	p, err := newParser(src)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	for p.tok.Kind != EOF {
+		if err := p.parseExecutableDefinition(doc); err != nil {
+			return nil, err
+		}
+	}
+	if len(doc.Operations) == 0 && len(doc.Fragments) == 0 {
+		return nil, p.errorf("document has no operation")
+	}
+	return doc, nil
+}
+
+// MustParse is like Parse but panics if source can't be parsed
+func MustParse(src string) *Document {
+	doc, err := Parse(src)
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+func (p *parser) parseExecutableDefinition(doc *Document) error {
+	if p.peek("{") {
+		pos := p.tok.Pos
+		set, err := p.parseSelectionSet()
+		if err != nil {
+			return err
+		}
+		doc.Operations = append(doc.Operations, &OperationDefinition{Type: Query, SelectionSet: set, Pos: pos})
+		return nil
+	}
+	if p.tok.Kind != Name {
+		return p.unexpected()
+	}
+	switch p.tok.Value {
+	case "query", "mutation", "subscription":
+		op, err := p.parseOperation()
+		if err != nil {
+			return err
+		}
+		doc.Operations = append(doc.Operations, op)
+	case "fragment":
+		f, err := p.parseFragment()
+		if err != nil {
+			return err
+		}
+		doc.Fragments = append(doc.Fragments, f)
+	case "schema", "scalar", "type", "interface", "union", "enum", "input", "directive", "extend":
+		return p.errorf("type system definition %q is not allowed in executable document", p.tok.Value)
+	default:
+		return p.unexpected()
+	}
+	return nil
+}
+
+// parseOperation parse OperationType Name? VariableDefinitions? Directives? SelectionSet
+func (p *parser) parseOperation() (*OperationDefinition, error) {
+	op := &OperationDefinition{Type: OperationType(p.tok.Value), Pos: p.tok.Pos}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	var err error
+	if p.tok.Kind == Name {
+		if op.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.peek("(") {
+		if op.VariableDefinitions, err = p.parseVariableDefinitions(); err != nil {
+			return nil, err
+		}
+	}
+	if op.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if op.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// parseVariableDefinitions parse ( $name: Type = default @directives ... )
+func (p *parser) parseVariableDefinitions() ([]*VariableDefinition, error) {
+	open := p.tok.Pos
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var defs []*VariableDefinition
+	for {
+		if ok, err := p.skip(")"); err != nil || ok {
+			if err == nil && len(defs) == 0 {
+				return nil, &Error{Message: "expected variable definition", Pos: open}
+			}
+			return defs, err
+		}
+		def := &VariableDefinition{Pos: p.tok.Pos}
+		if err := p.expect("$"); err != nil {
+			return nil, err
+		}
+		var err error
+		if def.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if def.Type, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if def.DefaultValue, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		if def.Directives, err = p.parseDirectives(true); err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+}
+
+// parseFragment parse fragment Name on Type Directives? SelectionSet
+func (p *parser) parseFragment() (*FragmentDefinition, error) {
+	f := &FragmentDefinition{Pos: p.tok.Pos}
+	if err := p.expectKeyword("fragment"); err != nil {
+		return nil, err
+	}
+	if p.peekKeyword("on") {
+		return nil, p.errorf("fragment can't be named on")
+	}
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if err = p.expectKeyword("on"); err != nil {
+		return nil, err
+	}
+	if f.TypeCondition, err = p.name(); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if f.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseSelectionSet parse { Selection+ }
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	open := p.tok.Pos
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	var set []Selection
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			if err == nil && len(set) == 0 {
+				return nil, &Error{Message: "selection set is empty", Pos: open}
+			}
+			return set, err
+		}
+		sel, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, sel)
+	}
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	if p.peek("...") {
+		return p.parseFragmentSelection()
+	}
+	f := &Field{Pos: p.tok.Pos}
+	var err error
+	if f.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	if ok, err := p.skip(":"); err != nil {
+		return nil, err
+	} else if ok {
+		f.Alias = f.Name
+		if f.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Arguments, err = p.parseArguments(false); err != nil {
+		return nil, err
+	}
+	if f.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if p.peek("{") {
+		if f.SelectionSet, err = p.parseSelectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return f, nil
+}
+
+// parseFragmentSelection parse ...Name Directives? or ... on Type? Directives? SelectionSet
+func (p *parser) parseFragmentSelection() (Selection, error) {
+	pos := p.tok.Pos
+	if err := p.expect("..."); err != nil {
+		return nil, err
+	}
+	if p.tok.Kind == Name && p.tok.Value != "on" {
+		spread := &FragmentSpread{Pos: pos}
+		var err error
+		if spread.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if spread.Directives, err = p.parseDirectives(false); err != nil {
+			return nil, err
+		}
+		return spread, nil
+	}
+	f := &InlineFragment{Pos: pos}
+	var err error
+	if p.peekKeyword("on") {
+		if err = p.next(); err != nil {
+			return nil, err
+		}
+		if f.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if f.Directives, err = p.parseDirectives(false); err != nil {
+		return nil, err
+	}
+	if f.SelectionSet, err = p.parseSelectionSet(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseArguments parse ( name: value ... ), nil if there's no (
+func (p *parser) parseArguments(constant bool) ([]*Argument, error) {
+	if !p.peek("(") {
+		return nil, nil
+	}
+	open := p.tok.Pos
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	var args []*Argument
+	for {
+		if ok, err := p.skip(")"); err != nil || ok {
+			if err == nil && len(args) == 0 {
+				return nil, &Error{Message: "expected argument", Pos: open}
+			}
+			return args, err
+		}
+		arg := &Argument{Pos: p.tok.Pos}
+		var err error
+		if arg.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if arg.Value, err = p.parseValue(constant); err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+}
+
+// parseDirectives parse @name(arguments) ...
+func (p *parser) parseDirectives(constant bool) ([]*Directive, error) {
+	var directives []*Directive
+	for p.peek("@") {
+		d := &Directive{Pos: p.tok.Pos}
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		var err error
+		if d.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if d.Arguments, err = p.parseArguments(constant); err != nil {
+			return nil, err
+		}
+		directives = append(directives, d)
+	}
+	return directives, nil
+}
+
+// parseType parse Name, [Type] and Type!
+func (p *parser) parseType() (*Type, error) {
+	t := &Type{Pos: p.tok.Pos}
+	if ok, err := p.skip("["); err != nil {
+		return nil, err
+	} else if ok {
+		if t.Elem, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if err = p.expect("]"); err != nil {
+			return nil, err
+		}
+	} else if t.Name, err = p.name(); err != nil {
+		return nil, err
+	}
+	ok, err := p.skip("!")
+	t.NonNull = ok
+	return t, err
+}
+
+// parseValue parse a value, variable is not allowed in constant value
+func (p *parser) parseValue(constant bool) (*Value, error) {
+	v := &Value{Pos: p.tok.Pos, Raw: p.tok.Value}
+	switch p.tok.Kind {
+	case IntValue:
+		v.Kind = IntLiteral
+	case FloatValue:
+		v.Kind = FloatLiteral
+	case StringValue, BlockString:
+		v.Kind = StringLiteral
+	case Name:
+		switch p.tok.Value {
+		case "true", "false":
+			v.Kind = BooleanLiteral
+		case "null":
+			v.Kind = NullLiteral
+		default:
+			v.Kind = EnumLiteral
+		}
+	case Punctuator:
+		switch p.tok.Value {
+		case "$":
+			if constant {
+				return nil, p.errorf("variable is not allowed in constant value")
+			}
+			if err := p.next(); err != nil {
+				return nil, err
+			}
+			v.Kind = VariableValue
+			var err error
+			v.Raw, err = p.name()
+			return v, err
+		case "[":
+			return p.parseList(v, constant)
+		case "{":
+			return p.parseObject(v, constant)
+		}
+		return nil, p.unexpected()
+	default:
+		return nil, p.unexpected()
+	}
+	return v, p.next()
+}
+
+func (p *parser) parseList(v *Value, constant bool) (*Value, error) {
+	v.Kind, v.Raw = ListLiteral, ""
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	for {
+		if ok, err := p.skip("]"); err != nil || ok {
+			return v, err
+		}
+		item, err := p.parseValue(constant)
+		if err != nil {
+			return nil, err
+		}
+		v.List = append(v.List, item)
+	}
+}
+
+func (p *parser) parseObject(v *Value, constant bool) (*Value, error) {
+	v.Kind, v.Raw = ObjectLiteral, ""
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			return v, err
+		}
+		field := &ObjectField{Pos: p.tok.Pos}
+		var err error
+		if field.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if field.Value, err = p.parseValue(constant); err != nil {
+			return nil, err
+		}
+		v.Fields = append(v.Fields, field)
+	}
+}
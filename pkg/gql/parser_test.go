@@ -0,0 +1,99 @@
+package gql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(_gqlTestSample + `
+	fragment comparisonFields on Character @include(if: true) {
+		name
+		friendsConnection(first: $first, filter: {tags: ["a", "b"], age: null}) {
+			edges { node { name } }
+		}
+		... on Droid { primaryFunction }
+		... @skip(if: false) { id }
+	}
+
+	mutation CreateReview($ep: Episode!, $review: ReviewInput! = {stars: 5}) {
+		createReview(episode: $ep, review: $review) { stars commentary }
+	}
+
+	subscription { reviewAdded { stars } }
+
+	{ hero { name } }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, doc.Operations, 4)
+	op := doc.Operation("HeroComparison")
+	assert.Equal(t, Query, op.Type)
+	assert.Equal(t, "first", op.VariableDefinitions[0].Name)
+	assert.Equal(t, "Int", op.VariableDefinitions[0].Type.String())
+	assert.Equal(t, &Value{Kind: IntLiteral, Raw: "3", Pos: Position{Line: 2, Column: 36}}, op.VariableDefinitions[0].DefaultValue)
+
+	left := op.SelectionSet[0].(*Field)
+	assert.Equal(t, "leftComparison", left.ResponseKey())
+	assert.Equal(t, "hero", left.Name)
+	assert.Equal(t, &Argument{Name: "episode", Value: &Value{Kind: EnumLiteral, Raw: "EMPIRE", Pos: Position{Line: 3, Column: 32}},
+		Pos: Position{Line: 3, Column: 23}}, left.Arguments[0])
+	assert.Equal(t, "comparisonFields", left.SelectionSet[0].(*FragmentSpread).Name)
+
+	f := doc.Fragment("comparisonFields")
+	assert.Equal(t, "Character", f.TypeCondition)
+	assert.Equal(t, "include", f.Directives[0].Name)
+	conn := f.SelectionSet[1].(*Field)
+	assert.Equal(t, VariableValue, conn.Arguments[0].Value.Kind)
+	filter := conn.Arguments[1].Value
+	assert.Equal(t, ObjectLiteral, filter.Kind)
+	assert.Equal(t, "tags", filter.Fields[0].Name)
+	assert.Len(t, filter.Fields[0].Value.List, 2)
+	assert.Equal(t, NullLiteral, filter.Fields[1].Value.Kind)
+	assert.Equal(t, "Droid", f.SelectionSet[2].(*InlineFragment).TypeCondition)
+	assert.Equal(t, "", f.SelectionSet[3].(*InlineFragment).TypeCondition)
+	assert.Equal(t, "skip", f.SelectionSet[3].(*InlineFragment).Directives[0].Name)
+
+	m := doc.Operation("CreateReview")
+	assert.Equal(t, Mutation, m.Type)
+	assert.Equal(t, "Episode!", m.VariableDefinitions[0].Type.String())
+	assert.Equal(t, "Episode", m.VariableDefinitions[0].Type.NamedType())
+	assert.Equal(t, ObjectLiteral, m.VariableDefinitions[1].DefaultValue.Kind)
+
+	assert.Equal(t, Subscription, doc.Operations[2].Type)
+	assert.Equal(t, "", doc.Operations[3].Name)
+	assert.Nil(t, doc.Operation(""))
+	assert.Nil(t, doc.Operation("missing"))
+
+	doc = MustParse(`query ($ids: [ID!]!) { nodes(ids: $ids) { id } }`)
+	assert.Equal(t, "[ID!]!", doc.Operation("").VariableDefinitions[0].Type.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	for src, message := range map[string]string{
+		``:                           "1:1: document has no operation",
+		`{}`:                         "1:1: selection set is empty",
+		`{ a `:                       "1:5: unexpected end of document",
+		`query { a(b: $c = 1) }`:     `1:17: expected Name, found Punctuator "="`,
+		`query ($a: Int = $b) { a }`: "1:18: variable is not allowed in constant value",
+		`query ($a Int) { a }`:       `1:11: expected ":", found Name "Int"`,
+		`query () { a }`:             "1:7: expected variable definition",
+		`{ a() }`:                    "1:4: expected argument",
+		`fragment on on T { a }`:     "1:10: fragment can't be named on",
+		`fragment F T { a }`:         `1:12: expected "on", found Name "T"`,
+		`type Query { a: Int }`:      `1:1: type system definition "type" is not allowed in executable document`,
+		`{ a(b: [1, 2) }`:            `1:13: unexpected Punctuator ")"`,
+		`{ a(b: {c 1}) }`:            `1:11: expected ":", found Int "1"`,
+		`{ a } b`:                    `1:7: unexpected Name "b"`,
+		`query Q { a(s: "x) }`:       "1:16: unterminated string",
+		`{ ...on }`:                  `1:9: expected Name, found Punctuator "}"`,
+	} {
+		_, err := Parse(src)
+		if assert.Error(t, err, src) {
+			assert.Equal(t, message, err.Error(), src)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package gql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// TypeKind is kind of named type, same as __TypeKind of introspection
+type TypeKind string
+
+// kinds of named type
+const (
+	Scalar      TypeKind = "SCALAR"
+	Object      TypeKind = "OBJECT"
+	Interface   TypeKind = "INTERFACE"
+	Union       TypeKind = "UNION"
+	Enum        TypeKind = "ENUM"
+	InputObject TypeKind = "INPUT_OBJECT"
+)
+
+// Schema is type system of a GraphQL service
+type Schema struct {
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+	Types            map[string]*TypeDefinition
+	Directives       map[string]*DirectiveDefinition
+}
+
+// TypeDefinition is a named type, fields is for object and interface, possible types for
+// interface and union, enum values for enum and input fields for input object
+type TypeDefinition struct {
+	Kind          TypeKind
+	Name          string
+	Fields        []*FieldDefinition
+	Interfaces    []string
+	PossibleTypes []string
+	EnumValues    []string
+	InputFields   []*InputValueDefinition
+}
+
+// FieldDefinition is a field of object or interface
+type FieldDefinition struct {
+	Name string
+	Args []*InputValueDefinition
+	Type *Type
+}
+
+// InputValueDefinition is an argument or field of input object
+type InputValueDefinition struct {
+	Name         string
+	Type         *Type
+	DefaultValue *Value
+}
+
+// DirectiveDefinition is directive @name(args) on locations
+type DirectiveDefinition struct {
+	Name      string
+	Args      []*InputValueDefinition
+	Locations []string
+}
+
+// Field return field by name, nil if not found
+func (t *TypeDefinition) Field(name string) *FieldDefinition {
+	for _, f := range t.Fields {
+		if f.Name == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// InputField return input field by name, nil if not found
+func (t *TypeDefinition) InputField(name string) *InputValueDefinition {
+	return findInputValue(t.InputFields, name)
+}
+
+// IsComposite check type is object, interface or union
+func (t *TypeDefinition) IsComposite() bool {
+	return t.Kind == Object || t.Kind == Interface || t.Kind == Union
+}
+
+// IsInput check type is scalar, enum or input object
+func (t *TypeDefinition) IsInput() bool {
+	return t.Kind == Scalar || t.Kind == Enum || t.Kind == InputObject
+}
+
+func findInputValue(values []*InputValueDefinition, name string) *InputValueDefinition {
+	for _, v := range values {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// newSchema create schema with built-in scalars and directives
+func newSchema() *Schema {
+	s := &Schema{
+		Types:      make(map[string]*TypeDefinition),
+		Directives: make(map[string]*DirectiveDefinition),
+	}
+	for _, name := range []string{"Int", "Float", "String", "Boolean", "ID"} {
+		s.Types[name] = &TypeDefinition{Kind: Scalar, Name: name}
+	}
+	ifArg := []*InputValueDefinition{{Name: "if", Type: &Type{Name: "Boolean", NonNull: true}}}
+	s.Directives["skip"] = &DirectiveDefinition{Name: "skip", Args: ifArg,
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}}
+	s.Directives["include"] = &DirectiveDefinition{Name: "include", Args: ifArg,
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"}}
+	s.Directives["deprecated"] = &DirectiveDefinition{Name: "deprecated",
+		Args:      []*InputValueDefinition{{Name: "reason", Type: &Type{Name: "String"}}},
+		Locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"}}
+	s.Directives["specifiedBy"] = &DirectiveDefinition{Name: "specifiedBy",
+		Args:      []*InputValueDefinition{{Name: "url", Type: &Type{Name: "String", NonNull: true}}},
+		Locations: []string{"SCALAR"}}
+	return s
+}
+
+// RootType return root type of operation, nil if schema doesn't support the operation
+func (s *Schema) RootType(op OperationType) *TypeDefinition {
+	name := s.QueryType
+	switch op {
+	case Mutation:
+		name = s.MutationType
+	case Subscription:
+		name = s.SubscriptionType
+	}
+	if name == "" {
+		return nil
+	}
+	return s.Types[name]
+}
+
+// PossibleTypes return object types of a composite type
+func (s *Schema) PossibleTypes(t *TypeDefinition) []string {
+	if t.Kind == Object {
+		return []string{t.Name}
+	}
+	return t.PossibleTypes
+}
+
+// finish resolve default root types and possible types of interfaces
+func (s *Schema) finish() error {
+	for op, name := range map[*string]string{&s.QueryType: "Query", &s.MutationType: "Mutation", &s.SubscriptionType: "Subscription"} {
+		if *op == "" {
+			if t, ok := s.Types[name]; ok && t.Kind == Object {
+				*op = name
+			}
+		}
+	}
+	if s.QueryType == "" {
+		return fmt.Errorf("schema has no query type")
+	}
+	for _, name := range []string{s.QueryType, s.MutationType, s.SubscriptionType} {
+		if t, ok := s.Types[name]; name != "" && (!ok || t.Kind != Object) {
+			return fmt.Errorf("root type %s is not an object type", name)
+		}
+	}
+	for _, t := range s.Types {
+		if t.Kind == Union {
+			for _, name := range t.PossibleTypes {
+				if m, ok := s.Types[name]; !ok || m.Kind != Object {
+					return fmt.Errorf("member %s of union %s is not an object type", name, t.Name)
+				}
+			}
+		}
+		if t.Kind != Object {
+			continue
+		}
+		for _, name := range t.Interfaces {
+			i, ok := s.Types[name]
+			if !ok || i.Kind != Interface {
+				return fmt.Errorf("type %s implements unknown interface %s", t.Name, name)
+			}
+			if !contains(i.PossibleTypes, t.Name) {
+				i.PossibleTypes = append(i.PossibleTypes, t.Name)
+			}
+		}
+	}
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSchema load schema from a SDL file or an introspection result json file
+func LoadSchema(fileName string) (*Schema, error) {
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read schema %s: %w", fileName, err)
+	}
+	var s *Schema
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("{")) && json.Valid(trimmed) {
+		s, err = ParseIntrospection(data)
+	} else {
+		s, err = ParseSchema(string(data))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", fileName, err)
+	}
+	return s, nil
+}
+
+// MustParseSchema is like ParseSchema but panics if schema can't be parsed
+func MustParseSchema(sdl string) *Schema {
+	s, err := ParseSchema(sdl)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
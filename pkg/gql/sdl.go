@@ -0,0 +1,333 @@
+package gql
+
+// ParseSchema parse a schema definition language document, type extensions are merged to
+// types, executable definitions are not allowed
+func ParseSchema(sdl string) (*Schema, error) {
+	p, err := newParser(sdl)
+	if err != nil {
+		return nil, err
+	}
+	s := newSchema()
+	for p.tok.Kind != EOF {
+		if err := p.parseTypeSystemDefinition(s); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.finish(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// skipDescription skip string before definition
+func (p *parser) skipDescription() error {
+	if p.tok.Kind == StringValue || p.tok.Kind == BlockString {
+		return p.next()
+	}
+	return nil
+}
+
+func (p *parser) parseTypeSystemDefinition(s *Schema) error {
+	if err := p.skipDescription(); err != nil {
+		return err
+	}
+	extend := p.peekKeyword("extend")
+	if extend {
+		if err := p.next(); err != nil {
+			return err
+		}
+	}
+	if p.tok.Kind != Name {
+		return p.unexpected()
+	}
+	keyword := p.tok.Value
+	switch keyword {
+	case "schema":
+		if err := p.next(); err != nil {
+			return err
+		}
+		return p.parseSchemaDefinition(s)
+	case "directive":
+		if extend {
+			return p.errorf("directive can't be extended")
+		}
+		return p.parseDirectiveDefinition(s)
+	case "scalar", "type", "interface", "union", "enum", "input":
+	case "query", "mutation", "subscription", "fragment":
+		return p.errorf("executable definition %q is not allowed in schema", keyword)
+	default:
+		return p.unexpected()
+	}
+
+	if err := p.next(); err != nil {
+		return err
+	}
+	pos := p.tok.Pos
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	kind := map[string]TypeKind{"scalar": Scalar, "type": Object, "interface": Interface,
+		"union": Union, "enum": Enum, "input": InputObject}[keyword]
+	t, ok := s.Types[name]
+	switch {
+	case !ok && extend:
+		return &Error{Message: "extend undefined type " + name, Pos: pos}
+	case ok && !extend:
+		return &Error{Message: "type " + name + " is defined more than once", Pos: pos}
+	case ok && t.Kind != kind:
+		return &Error{Message: "extend " + name + " with different kind", Pos: pos}
+	case !ok:
+		t = &TypeDefinition{Kind: kind, Name: name}
+		s.Types[name] = t
+	}
+
+	if kind == Object || kind == Interface {
+		if t.Interfaces, err = p.parseImplements(t.Interfaces); err != nil {
+			return err
+		}
+	}
+	if _, err = p.parseDirectives(true); err != nil {
+		return err
+	}
+	switch kind {
+	case Object, Interface:
+		return p.parseFieldsDefinition(t)
+	case Union:
+		return p.parseUnionMembers(t)
+	case Enum:
+		return p.parseEnumValues(t)
+	case InputObject:
+		if p.peek("{") {
+			t.InputFields, err = p.parseInputValues("{", "}", t.InputFields)
+		}
+	}
+	return err
+}
+
+// parseSchemaDefinition parse { query: Type mutation: Type subscription: Type }
+func (p *parser) parseSchemaDefinition(s *Schema) error {
+	if _, err := p.parseDirectives(true); err != nil {
+		return err
+	}
+	if !p.peek("{") {
+		return nil
+	}
+	if err := p.next(); err != nil {
+		return err
+	}
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			return err
+		}
+		op, err := p.name()
+		if err != nil {
+			return err
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		switch OperationType(op) {
+		case Query:
+			s.QueryType = name
+		case Mutation:
+			s.MutationType = name
+		case Subscription:
+			s.SubscriptionType = name
+		default:
+			return p.errorf("unknown operation type %q", op)
+		}
+	}
+}
+
+// parseDirectiveDefinition parse directive @name(args) repeatable? on | A | B
+func (p *parser) parseDirectiveDefinition(s *Schema) error {
+	if err := p.next(); err != nil {
+		return err
+	}
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	d := &DirectiveDefinition{}
+	var err error
+	if d.Name, err = p.name(); err != nil {
+		return err
+	}
+	if p.peek("(") {
+		if d.Args, err = p.parseInputValues("(", ")", nil); err != nil {
+			return err
+		}
+	}
+	if p.peekKeyword("repeatable") {
+		if err = p.next(); err != nil {
+			return err
+		}
+	}
+	if err = p.expectKeyword("on"); err != nil {
+		return err
+	}
+	if _, err = p.skip("|"); err != nil {
+		return err
+	}
+	for {
+		loc, err := p.name()
+		if err != nil {
+			return err
+		}
+		d.Locations = append(d.Locations, loc)
+		if ok, err := p.skip("|"); err != nil || !ok {
+			s.Directives[d.Name] = d
+			return err
+		}
+	}
+}
+
+// parseImplements parse implements & A & B
+func (p *parser) parseImplements(interfaces []string) ([]string, error) {
+	if !p.peekKeyword("implements") {
+		return interfaces, nil
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if _, err := p.skip("&"); err != nil {
+		return nil, err
+	}
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		interfaces = append(interfaces, name)
+		if ok, err := p.skip("&"); err != nil || !ok {
+			return interfaces, err
+		}
+	}
+}
+
+// parseFieldsDefinition parse { description? name(args): Type @directives ... }
+func (p *parser) parseFieldsDefinition(t *TypeDefinition) error {
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return err
+	}
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			return err
+		}
+		if err := p.skipDescription(); err != nil {
+			return err
+		}
+		pos := p.tok.Pos
+		f := &FieldDefinition{}
+		var err error
+		if f.Name, err = p.name(); err != nil {
+			return err
+		}
+		if t.Field(f.Name) != nil {
+			return &Error{Message: "field " + t.Name + "." + f.Name + " is defined more than once", Pos: pos}
+		}
+		if p.peek("(") {
+			if f.Args, err = p.parseInputValues("(", ")", nil); err != nil {
+				return err
+			}
+		}
+		if err = p.expect(":"); err != nil {
+			return err
+		}
+		if f.Type, err = p.parseType(); err != nil {
+			return err
+		}
+		if _, err = p.parseDirectives(true); err != nil {
+			return err
+		}
+		t.Fields = append(t.Fields, f)
+	}
+}
+
+// parseInputValues parse arguments or input fields, description? name: Type = default @directives
+func (p *parser) parseInputValues(open, close string, values []*InputValueDefinition) ([]*InputValueDefinition, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+	for {
+		if ok, err := p.skip(close); err != nil || ok {
+			return values, err
+		}
+		if err := p.skipDescription(); err != nil {
+			return nil, err
+		}
+		v := &InputValueDefinition{}
+		var err error
+		if v.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+		if v.Type, err = p.parseType(); err != nil {
+			return nil, err
+		}
+		if ok, err := p.skip("="); err != nil {
+			return nil, err
+		} else if ok {
+			if v.DefaultValue, err = p.parseValue(true); err != nil {
+				return nil, err
+			}
+		}
+		if _, err = p.parseDirectives(true); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+}
+
+// parseUnionMembers parse = | A | B
+func (p *parser) parseUnionMembers(t *TypeDefinition) error {
+	if ok, err := p.skip("="); err != nil || !ok {
+		return err
+	}
+	if _, err := p.skip("|"); err != nil {
+		return err
+	}
+	for {
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		t.PossibleTypes = append(t.PossibleTypes, name)
+		if ok, err := p.skip("|"); err != nil || !ok {
+			return err
+		}
+	}
+}
+
+// parseEnumValues parse { description? VALUE @directives ... }
+func (p *parser) parseEnumValues(t *TypeDefinition) error {
+	if ok, err := p.skip("{"); err != nil || !ok {
+		return err
+	}
+	for {
+		if ok, err := p.skip("}"); err != nil || ok {
+			return err
+		}
+		if err := p.skipDescription(); err != nil {
+			return err
+		}
+		switch p.tok.Value {
+		case "true", "false", "null":
+			return p.errorf("enum value can't be %s", p.tok.Value)
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		if _, err = p.parseDirectives(true); err != nil {
+			return err
+		}
+		t.EnumValues = append(t.EnumValues, name)
+	}
+}
@@ -0,0 +1,531 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Placeholder is put in place of a name or value which is unknown until runtime, like a
+// template variable, names and enum values containing it are not validated
+const Placeholder = "__PLACEHOLDER__"
+
+func isPlaceholder(name string) bool {
+	return strings.Contains(name, Placeholder)
+}
+
+// Errors is all errors found by Validate
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// variableUsage is a variable used at a position expecting a type
+type variableUsage struct {
+	name       string
+	typ        *Type
+	hasDefault bool // location has default value, nullable variable is allowed
+	pos        Position
+}
+
+// validator check document against schema, fragments are validated once by their type
+// condition, variable usages and spreads of them are collected for operations
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	errs      Errors
+	usages    map[*FragmentDefinition][]variableUsage
+	spreads   map[*FragmentDefinition][]string
+	fragments map[string]*FragmentDefinition
+}
+
+func (v *validator) errorf(pos Position, format string, args ...interface{}) {
+	v.errs = append(v.errs, &Error{Message: fmt.Sprintf(format, args...), Pos: pos})
+}
+
+// Validate document against schema, it checks operations, fields, arguments, fragments,
+// directives and variables, nil is returned if document is valid, otherwise Errors
+func Validate(schema *Schema, doc *Document) error {
+	v := &validator{
+		schema:    schema,
+		doc:       doc,
+		usages:    make(map[*FragmentDefinition][]variableUsage),
+		spreads:   make(map[*FragmentDefinition][]string),
+		fragments: make(map[string]*FragmentDefinition),
+	}
+	v.validateFragments()
+	v.validateOperations()
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *validator) validateFragments() {
+	for _, f := range v.doc.Fragments {
+		if _, ok := v.fragments[f.Name]; ok {
+			v.errorf(f.Pos, "fragment %s is defined more than once", f.Name)
+			continue
+		}
+		v.fragments[f.Name] = f
+	}
+	for _, f := range v.doc.Fragments {
+		if v.fragments[f.Name] != f {
+			continue
+		}
+		var usages []variableUsage
+		var spreads []string
+		v.directives(f.Directives, "FRAGMENT_DEFINITION", &usages)
+		if t := v.typeCondition(f.TypeCondition, f.Pos); t != nil {
+			v.selectionSet(t, f.SelectionSet, &usages, &spreads)
+		}
+		v.usages[f] = usages
+		// spreads are collected even if type condition is invalid, so they are not reported as unused
+		spreads = spreads[:0]
+		v.collectSpreads(f.SelectionSet, &spreads)
+		v.spreads[f] = spreads
+	}
+
+	used := make(map[string]bool)
+	for _, op := range v.doc.Operations {
+		var spreads []string
+		v.collectSpreads(op.SelectionSet, &spreads)
+		for _, name := range spreads {
+			v.reachable(name, used)
+		}
+	}
+	for _, f := range v.doc.Fragments {
+		if !used[f.Name] && !isPlaceholder(f.Name) {
+			v.errorf(f.Pos, "fragment %s is never used", f.Name)
+		}
+	}
+	v.checkCycles()
+}
+
+// collectSpreads collect fragment spreads of selections without following fragments
+func (v *validator) collectSpreads(set []Selection, spreads *[]string) {
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *Field:
+			v.collectSpreads(s.SelectionSet, spreads)
+		case *InlineFragment:
+			v.collectSpreads(s.SelectionSet, spreads)
+		case *FragmentSpread:
+			*spreads = append(*spreads, s.Name)
+		}
+	}
+}
+
+// reachable mark fragment and fragments spread by it
+func (v *validator) reachable(name string, visited map[string]bool) {
+	if visited[name] {
+		return
+	}
+	visited[name] = true
+	if f, ok := v.fragments[name]; ok {
+		for _, spread := range v.spreads[f] {
+			v.reachable(spread, visited)
+		}
+	}
+}
+
+// checkCycles report fragments spread themselves directly or indirectly
+func (v *validator) checkCycles() {
+	for _, f := range v.doc.Fragments {
+		visited := make(map[string]bool)
+		for _, spread := range v.spreads[f] {
+			v.reachable(spread, visited)
+		}
+		if visited[f.Name] {
+			v.errorf(f.Pos, "fragment %s spreads itself", f.Name)
+		}
+	}
+}
+
+func (v *validator) validateOperations() {
+	names := make(map[string]bool)
+	for _, op := range v.doc.Operations {
+		if op.Name == "" && len(v.doc.Operations) > 1 {
+			v.errorf(op.Pos, "anonymous operation must be the only operation")
+		}
+		if op.Name != "" {
+			if names[op.Name] {
+				v.errorf(op.Pos, "operation %s is defined more than once", op.Name)
+			}
+			names[op.Name] = true
+		}
+		v.operation(op)
+	}
+}
+
+func (v *validator) operation(op *OperationDefinition) {
+	root := v.schema.RootType(op.Type)
+	if root == nil {
+		v.errorf(op.Pos, "schema doesn't support %s", op.Type)
+		return
+	}
+
+	defined := make(map[string]*VariableDefinition)
+	for _, def := range op.VariableDefinitions {
+		if _, ok := defined[def.Name]; ok {
+			v.errorf(def.Pos, "variable $%s is defined more than once", def.Name)
+		}
+		defined[def.Name] = def
+		if t, ok := v.schema.Types[def.Type.NamedType()]; !ok {
+			v.errorf(def.Type.Pos, "unknown type %s", def.Type.NamedType())
+		} else if !t.IsInput() {
+			v.errorf(def.Type.Pos, "variable $%s type %s is not an input type", def.Name, def.Type)
+		} else if def.DefaultValue != nil {
+			v.value(def.DefaultValue, def.Type, false, nil)
+		}
+		v.directives(def.Directives, "VARIABLE_DEFINITION", nil)
+	}
+
+	var usages []variableUsage
+	var spreads []string
+	v.directives(op.Directives, strings.ToUpper(string(op.Type)), &usages)
+	v.selectionSet(root, op.SelectionSet, &usages, &spreads)
+	if op.Type == Subscription {
+		fields := 0
+		for _, sel := range op.SelectionSet {
+			if _, ok := sel.(*Field); ok {
+				fields++
+			}
+		}
+		if fields > 1 {
+			v.errorf(op.Pos, "subscription must select only one top level field")
+		}
+	}
+
+	visited := make(map[string]bool)
+	for _, name := range spreads {
+		v.reachable(name, visited)
+	}
+	for _, f := range v.doc.Fragments {
+		if visited[f.Name] && v.fragments[f.Name] == f {
+			usages = append(usages, v.usages[f]...)
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, u := range usages {
+		used[u.name] = true
+		def, ok := defined[u.name]
+		if !ok {
+			if !isPlaceholder(u.name) {
+				v.errorf(u.pos, "variable $%s is not defined by operation %s", u.name, op.Name)
+			}
+			continue
+		}
+		if !allowedVariable(def, u) {
+			v.errorf(u.pos, "variable $%s of type %s is used where %s is expected", u.name, def.Type, u.typ)
+		}
+	}
+	for _, def := range op.VariableDefinitions {
+		if !used[def.Name] {
+			v.errorf(def.Pos, "variable $%s is never used in operation %s", def.Name, op.Name)
+		}
+	}
+}
+
+// allowedVariable check variable type can be used at location, nullable variable is allowed
+// by non null location if either of them has default value
+func allowedVariable(def *VariableDefinition, u variableUsage) bool {
+	expected := u.typ
+	if expected.NonNull && !def.Type.NonNull {
+		if def.DefaultValue == nil && !u.hasDefault {
+			return false
+		}
+		nullable := *expected
+		nullable.NonNull = false
+		expected = &nullable
+	}
+	return isSubType(def.Type, expected)
+}
+
+// isSubType check variable type is compatible with expected type
+func isSubType(t, expected *Type) bool {
+	if expected.NonNull && !t.NonNull {
+		return false
+	}
+	if expected.Elem != nil {
+		return t.Elem != nil && isSubType(t.Elem, expected.Elem)
+	}
+	return t.Elem == nil && t.Name == expected.Name
+}
+
+// typeCondition check type condition of fragment is a known composite type
+func (v *validator) typeCondition(name string, pos Position) *TypeDefinition {
+	if isPlaceholder(name) {
+		return nil
+	}
+	t, ok := v.schema.Types[name]
+	if !ok {
+		v.errorf(pos, "unknown type %s", name)
+		return nil
+	}
+	if !t.IsComposite() {
+		v.errorf(pos, "fragment can't condition on non composite type %s", name)
+		return nil
+	}
+	return t
+}
+
+// possible check fragment of type t can be spread in parent
+func (v *validator) possible(parent, t *TypeDefinition) bool {
+	for _, a := range v.schema.PossibleTypes(parent) {
+		if contains(v.schema.PossibleTypes(t), a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *validator) selectionSet(parent *TypeDefinition, set []Selection, usages *[]variableUsage, spreads *[]string) {
+	for _, sel := range set {
+		switch s := sel.(type) {
+		case *Field:
+			v.directives(s.Directives, "FIELD", usages)
+			v.field(parent, s, usages, spreads)
+		case *InlineFragment:
+			v.directives(s.Directives, "INLINE_FRAGMENT", usages)
+			t := parent
+			if s.TypeCondition != "" {
+				if t = v.typeCondition(s.TypeCondition, s.Pos); t == nil {
+					continue
+				}
+				if !v.possible(parent, t) {
+					v.errorf(s.Pos, "fragment on %s can never be spread in %s", t.Name, parent.Name)
+				}
+			}
+			v.selectionSet(t, s.SelectionSet, usages, spreads)
+		case *FragmentSpread:
+			v.directives(s.Directives, "FRAGMENT_SPREAD", usages)
+			if isPlaceholder(s.Name) {
+				continue
+			}
+			*spreads = append(*spreads, s.Name)
+			f, ok := v.fragments[s.Name]
+			if !ok {
+				v.errorf(s.Pos, "unknown fragment %s", s.Name)
+				continue
+			}
+			if t, ok := v.schema.Types[f.TypeCondition]; ok && t.IsComposite() && !v.possible(parent, t) {
+				v.errorf(s.Pos, "fragment %s on %s can never be spread in %s", f.Name, t.Name, parent.Name)
+			}
+		}
+	}
+}
+
+func (v *validator) field(parent *TypeDefinition, f *Field, usages *[]variableUsage, spreads *[]string) {
+	if isPlaceholder(f.Name) {
+		return
+	}
+	if f.Name == "__typename" {
+		if f.SelectionSet != nil {
+			v.errorf(f.Pos, "field __typename of type String must not have a selection")
+		}
+		return
+	}
+
+	def := parent.Field(f.Name)
+	if def == nil && parent.Name == v.schema.QueryType {
+		def = metaField(f.Name)
+	}
+	if def == nil {
+		v.errorf(f.Pos, "field %s is not defined on type %s", f.Name, parent.Name)
+		return
+	}
+	v.arguments(def.Args, f.Arguments, f.Pos, "field "+f.Name, usages)
+
+	t, ok := v.schema.Types[def.Type.NamedType()]
+	if !ok {
+		// introspection types are missing in SDL, selections of them are not validated
+		if !strings.HasPrefix(def.Type.NamedType(), "__") {
+			v.errorf(f.Pos, "unknown type %s of field %s", def.Type.NamedType(), f.Name)
+		}
+		return
+	}
+	switch {
+	case t.IsComposite() && f.SelectionSet == nil:
+		v.errorf(f.Pos, "field %s of type %s must have a selection of subfields", f.Name, def.Type)
+	case !t.IsComposite() && f.SelectionSet != nil:
+		v.errorf(f.Pos, "field %s of type %s must not have a selection", f.Name, def.Type)
+	case t.IsComposite():
+		v.selectionSet(t, f.SelectionSet, usages, spreads)
+	}
+}
+
+// metaField is __schema and __type of query root
+func metaField(name string) *FieldDefinition {
+	switch name {
+	case "__schema":
+		return &FieldDefinition{Name: name, Type: &Type{Name: "__Schema", NonNull: true}}
+	case "__type":
+		return &FieldDefinition{Name: name, Type: &Type{Name: "__Type"},
+			Args: []*InputValueDefinition{{Name: "name", Type: &Type{Name: "String", NonNull: true}}}}
+	}
+	return nil
+}
+
+// arguments check arguments are defined, not duplicated and required ones are provided
+func (v *validator) arguments(defs []*InputValueDefinition, args []*Argument, pos Position, owner string, usages *[]variableUsage) {
+	given := make(map[string]bool)
+	for _, arg := range args {
+		if isPlaceholder(arg.Name) {
+			continue
+		}
+		if given[arg.Name] {
+			v.errorf(arg.Pos, "argument %s of %s is given more than once", arg.Name, owner)
+			continue
+		}
+		given[arg.Name] = true
+		def := findInputValue(defs, arg.Name)
+		if def == nil {
+			v.errorf(arg.Pos, "unknown argument %s of %s", arg.Name, owner)
+			continue
+		}
+		v.value(arg.Value, def.Type, def.DefaultValue != nil, usages)
+	}
+	for _, def := range defs {
+		if def.Type.NonNull && def.DefaultValue == nil && !given[def.Name] {
+			v.errorf(pos, "argument %s of %s is required", def.Name, owner)
+		}
+	}
+}
+
+// directives check directives are known and allowed at location
+func (v *validator) directives(directives []*Directive, location string, usages *[]variableUsage) {
+	for _, d := range directives {
+		def, ok := v.schema.Directives[d.Name]
+		if !ok {
+			v.errorf(d.Pos, "unknown directive @%s", d.Name)
+			continue
+		}
+		if !contains(def.Locations, location) {
+			v.errorf(d.Pos, "directive @%s is not allowed on %s", d.Name, location)
+		}
+		v.arguments(def.Args, d.Arguments, d.Pos, "directive @"+d.Name, usages)
+	}
+}
+
+// value check value can be coerced to type, variable usages are collected,
+// variable is not allowed if usages is nil
+func (v *validator) value(val *Value, t *Type, hasDefault bool, usages *[]variableUsage) {
+	switch val.Kind {
+	case VariableValue:
+		if usages == nil {
+			v.errorf(val.Pos, "variable $%s is not allowed in constant value", val.Raw)
+			return
+		}
+		*usages = append(*usages, variableUsage{name: val.Raw, typ: t, hasDefault: hasDefault, pos: val.Pos})
+		return
+	case NullLiteral:
+		if t.NonNull {
+			v.errorf(val.Pos, "null is not allowed for %s", t)
+		}
+		return
+	case EnumLiteral:
+		if isPlaceholder(val.Raw) {
+			return
+		}
+	}
+
+	if t.Elem != nil {
+		if val.Kind != ListLiteral {
+			v.value(val, t.Elem, false, usages)
+			return
+		}
+		for _, item := range val.List {
+			v.value(item, t.Elem, false, usages)
+		}
+		return
+	}
+
+	def, ok := v.schema.Types[t.Name]
+	if !ok {
+		v.errorf(val.Pos, "unknown type %s", t.Name)
+		return
+	}
+	switch def.Kind {
+	case Scalar:
+		if !scalarLiteral(def.Name, val) {
+			v.errorf(val.Pos, "%s is not a valid %s", describe(val), def.Name)
+		}
+	case Enum:
+		if val.Kind != EnumLiteral || !contains(def.EnumValues, val.Raw) {
+			v.errorf(val.Pos, "%s is not a value of enum %s", describe(val), def.Name)
+		}
+	case InputObject:
+		v.inputObject(val, def, usages)
+	default:
+		v.errorf(val.Pos, "%s is not an input type", def.Name)
+	}
+}
+
+func (v *validator) inputObject(val *Value, def *TypeDefinition, usages *[]variableUsage) {
+	if val.Kind != ObjectLiteral {
+		v.errorf(val.Pos, "%s is not a valid %s", describe(val), def.Name)
+		return
+	}
+	given := make(map[string]bool)
+	for _, field := range val.Fields {
+		if isPlaceholder(field.Name) {
+			continue
+		}
+		if given[field.Name] {
+			v.errorf(field.Pos, "field %s of %s is given more than once", field.Name, def.Name)
+			continue
+		}
+		given[field.Name] = true
+		f := def.InputField(field.Name)
+		if f == nil {
+			v.errorf(field.Pos, "field %s is not defined on input %s", field.Name, def.Name)
+			continue
+		}
+		v.value(field.Value, f.Type, f.DefaultValue != nil, usages)
+	}
+	for _, f := range def.InputFields {
+		if f.Type.NonNull && f.DefaultValue == nil && !given[f.Name] {
+			v.errorf(val.Pos, "field %s of input %s is required", f.Name, def.Name)
+		}
+	}
+}
+
+// scalarLiteral check literal of built-in scalars, custom scalars accept any literal
+func scalarLiteral(scalar string, val *Value) bool {
+	switch scalar {
+	case "Int":
+		if val.Kind != IntLiteral {
+			return false
+		}
+		_, err := strconv.ParseInt(val.Raw, 10, 32)
+		return err == nil
+	case "Float":
+		return val.Kind == IntLiteral || val.Kind == FloatLiteral
+	case "String":
+		return val.Kind == StringLiteral
+	case "Boolean":
+		return val.Kind == BooleanLiteral
+	case "ID":
+		return val.Kind == StringLiteral || val.Kind == IntLiteral
+	}
+	return true
+}
+
+func describe(val *Value) string {
+	switch val.Kind {
+	case StringLiteral:
+		return strconv.Quote(val.Raw)
+	case ListLiteral:
+		return "list"
+	case ObjectLiteral:
+		return "object"
+	}
+	return val.Raw
+}
@@ -0,0 +1,227 @@
+package gql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const starWarsSchema = `
+schema { query: Query mutation: Mutation }
+
+"The query type"
+type Query {
+	hero(episode: Episode): Character
+	human(id: ID!): Human
+	search(text: String!, first: Int = 10): [SearchResult]
+}
+
+type Mutation {
+	createReview(episode: Episode!, review: ReviewInput!): Review
+}
+
+enum Episode { NEWHOPE EMPIRE JEDI }
+
+interface Character {
+	id: ID!
+	name: String!
+	friends: [Character]
+	appearsIn: [Episode]!
+}
+
+type Human implements Character {
+	id: ID!
+	name: String!
+	friends: [Character]
+	appearsIn: [Episode]!
+	height(unit: LengthUnit = METER): Float
+}
+
+type Droid implements Character {
+	id: ID!
+	name: String!
+	friends: [Character]
+	appearsIn: [Episode]!
+	"""
+	Function of droid
+	"""
+	primaryFunction: String
+}
+
+type Starship { id: ID! name: String! }
+
+union SearchResult = Human | Droid | Starship
+
+enum LengthUnit { METER FOOT }
+
+input ReviewInput {
+	stars: Int!
+	commentary: String
+	tags: [String!]
+}
+
+type Review { stars: Int! commentary: String }
+
+scalar Date
+
+directive @cached(ttl: Int) on FIELD | QUERY
+
+extend type Query { today: Date }
+`
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema(starWarsSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Query", s.QueryType)
+	assert.Equal(t, "Mutation", s.MutationType)
+	assert.Equal(t, "", s.SubscriptionType)
+	assert.Equal(t, Interface, s.Types["Character"].Kind)
+	assert.ElementsMatch(t, []string{"Human", "Droid"}, s.Types["Character"].PossibleTypes)
+	assert.Equal(t, []string{"Human", "Droid", "Starship"}, s.Types["SearchResult"].PossibleTypes)
+	assert.Equal(t, []string{"NEWHOPE", "EMPIRE", "JEDI"}, s.Types["Episode"].EnumValues)
+	assert.Equal(t, "[SearchResult]", s.Types["Query"].Field("search").Type.String())
+	assert.Equal(t, "10", s.Types["Query"].Field("search").Args[1].DefaultValue.Raw)
+	assert.NotNil(t, s.Types["Query"].Field("today"))
+	assert.Equal(t, "Int!", s.Types["ReviewInput"].InputField("stars").Type.String())
+	assert.Equal(t, []string{"FIELD", "QUERY"}, s.Directives["cached"].Locations)
+	assert.Equal(t, Scalar, s.Types["Date"].Kind)
+
+	for sdl, message := range map[string]string{
+		`type Query { a: Int } type Query { b: Int }`: "1:28: type Query is defined more than once",
+		`type Query { a: Int a: Int }`:                "1:21: field Query.a is defined more than once",
+		`extend type Query { a: Int }`:                "1:13: extend undefined type Query",
+		`type Query { a: Int } extend input Query`:    "1:36: extend Query with different kind",
+		`type T { a: Int }`:                           "schema has no query type",
+		`type Query implements Node { a: Int }`:       "type Query implements unknown interface Node",
+		`type Query { a: U } union U = Query | Int`:   "member Int of union U is not an object type",
+		`query { a }`:     `1:1: executable definition "query" is not allowed in schema`,
+		`enum E { true }`: "1:10: enum value can't be true",
+	} {
+		_, err := ParseSchema(sdl)
+		if assert.Error(t, err, sdl) {
+			assert.Equal(t, message, err.Error(), sdl)
+		}
+	}
+}
+
+const introspection = `{"data": {"__schema": {
+	"queryType": {"name": "Query"},
+	"mutationType": null,
+	"subscriptionType": null,
+	"types": [
+		{"kind": "OBJECT", "name": "Query", "fields": [
+			{"name": "user", "args": [
+				{"name": "id", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}, "defaultValue": null},
+				{"name": "role", "type": {"kind": "ENUM", "name": "Role", "ofType": null}, "defaultValue": "ADMIN"}
+			], "type": {"kind": "OBJECT", "name": "User", "ofType": null}}
+		], "interfaces": []},
+		{"kind": "OBJECT", "name": "User", "fields": [
+			{"name": "id", "args": [], "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "ID", "ofType": null}}},
+			{"name": "tags", "args": [], "type": {"kind": "LIST", "name": null, "ofType": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "String", "ofType": null}}}}
+		], "interfaces": []},
+		{"kind": "ENUM", "name": "Role", "enumValues": [{"name": "ADMIN"}, {"name": "GUEST"}]},
+		{"kind": "SCALAR", "name": "ID"},
+		{"kind": "SCALAR", "name": "String"}
+	],
+	"directives": [{"name": "skip", "locations": ["FIELD"], "args": [
+		{"name": "if", "type": {"kind": "NON_NULL", "name": null, "ofType": {"kind": "SCALAR", "name": "Boolean", "ofType": null}}, "defaultValue": null}
+	]}]
+}}}`
+
+func TestLoadSchema(t *testing.T) {
+	dir := t.TempDir()
+	sdlFile := filepath.Join(dir, "schema.graphql")
+	jsonFile := filepath.Join(dir, "schema.json")
+	os.WriteFile(sdlFile, []byte(starWarsSchema), 0644)
+	os.WriteFile(jsonFile, []byte(introspection), 0644)
+
+	s, err := LoadSchema(sdlFile)
+	assert.NoError(t, err)
+	assert.NotNil(t, s.Types["Droid"])
+
+	s, err = LoadSchema(jsonFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Query", s.QueryType)
+	user := s.Types["Query"].Field("user")
+	assert.Equal(t, "ID!", user.Args[0].Type.String())
+	assert.Equal(t, &Value{Kind: EnumLiteral, Raw: "ADMIN", Pos: Position{Line: 1, Column: 1}}, user.Args[1].DefaultValue)
+	assert.Equal(t, "[String!]", s.Types["User"].Field("tags").Type.String())
+	assert.Equal(t, []string{"ADMIN", "GUEST"}, s.Types["Role"].EnumValues)
+	assert.NoError(t, Validate(s, MustParse(`{ user(id: 1) { id tags } }`)))
+	assert.Error(t, Validate(s, MustParse(`{ user { id } }`)))
+
+	_, err = LoadSchema(filepath.Join(dir, "missing.graphql"))
+	assert.Error(t, err)
+	_, err = ParseIntrospection([]byte(`{"data": {}}`))
+	assert.EqualError(t, err, "introspection: __schema is not found")
+}
+
+func TestValidate(t *testing.T) {
+	s := MustParseSchema(starWarsSchema)
+
+	for _, query := range []string{
+		`{ hero { name } }`,
+		`query Hero($ep: Episode = JEDI) @cached(ttl: 60) { hero(episode: $ep) { __typename id ...on Human { height(unit: FOOT) } } }`,
+		`query { search(text: "r2") { __typename ... on Droid { primaryFunction } ... on Starship { name } } }`,
+		`query ($id: ID!, $skip: Boolean!) { human(id: $id) { name @skip(if: $skip) ...Friends } }
+		 fragment Friends on Character { friends { name ...Names } }
+		 fragment Names on Character { appearsIn }`,
+		`mutation ($stars: Int!) { createReview(episode: EMPIRE, review: {stars: $stars, tags: "a"}) { stars } }`,
+		`mutation { createReview(episode: JEDI, review: {stars: 5, commentary: null, tags: ["a", "b"]}) { stars commentary } }`,
+		`{ today __schema { types { name } } __type(name: "Droid") { name } }`,
+		`query ($n: Int) { search(text: "x", first: $n) { __typename } }`,
+		`{ human(id: 1) { height } }`,
+		`{ human(id: "1") { __PLACEHOLDER__ name } hero(episode: __PLACEHOLDER__) { ...__PLACEHOLDER__ } }`,
+	} {
+		assert.NoError(t, Validate(s, MustParse(query)), query)
+	}
+
+	for query, message := range map[string]string{
+		`{ hero { age } }`:                                        "1:10: field age is not defined on type Character",
+		`{ hero }`:                                                "1:3: field hero of type Character must have a selection of subfields",
+		`{ hero { name { first } } }`:                             "1:10: field name of type String! must not have a selection",
+		`{ human { id } }`:                                        "1:3: argument id of field human is required",
+		`{ human(id: 1, id: 2) { id } }`:                          "1:16: argument id of field human is given more than once",
+		`{ hero(era: JEDI) { id } }`:                              "1:8: unknown argument era of field hero",
+		`{ hero(episode: CLONES) { id } }`:                        "1:17: CLONES is not a value of enum Episode",
+		`{ hero(episode: "JEDI") { id } }`:                        `1:17: "JEDI" is not a value of enum Episode`,
+		`{ human(id: 1.5) { id } }`:                               "1:13: 1.5 is not a valid ID",
+		`{ human(id: null) { id } }`:                              "1:13: null is not allowed for ID!",
+		`{ search(text: "a", first: 3000000000) { __typename } }`: "1:28: 3000000000 is not a valid Int",
+		`{ search(text: "a") { name } }`:                          "1:23: field name is not defined on type SearchResult",
+		`{ hero { ... on Starship { id } } }`:                     "1:10: fragment on Starship can never be spread in Character",
+		`{ hero { ... on Unknown { id } } }`:                      "1:10: unknown type Unknown",
+		`{ hero { ... on Episode { id } } }`:                      "1:10: fragment can't condition on non composite type Episode",
+		`{ hero { ...Missing } }`:                                 "1:10: unknown fragment Missing",
+		`{ hero { id } } fragment F on Human { id }`:              "1:17: fragment F is never used",
+		`{ hero { ...A } } fragment A on Character { ...B } fragment B on Character { ...A }`: "1:19: fragment A spreads itself; 1:52: fragment B spreads itself",
+		`{ hero { id @unknown } }`:                                                               "1:13: unknown directive @unknown",
+		`{ hero { id @include } }`:                                                               "1:13: argument if of directive @include is required",
+		`query @skip(if: true) { hero { id } }`:                                                  "1:7: directive @skip is not allowed on QUERY",
+		`query ($ep: Episode) { hero { id } }`:                                                   "1:8: variable $ep is never used in operation ",
+		`query Q { hero(episode: $ep) { id } }`:                                                  "1:25: variable $ep is not defined by operation Q",
+		`query ($ep: Episode, $ep: Episode) { hero(episode: $ep) { id } }`:                       "1:22: variable $ep is defined more than once",
+		`query ($r: Review) { hero { id } }`:                                                     "1:12: variable $r type Review is not an input type; 1:8: variable $r is never used in operation ",
+		`query ($x: Foo) { hero { id } }`:                                                        "1:12: unknown type Foo; 1:8: variable $x is never used in operation ",
+		`query ($id: ID) { human(id: $id) { id } }`:                                              "1:29: variable $id of type ID is used where ID! is expected",
+		`query ($e: [Episode]) { hero(episode: $e) { id } }`:                                     "1:39: variable $e of type [Episode] is used where Episode is expected",
+		`query ($e: Episode = 1) { hero(episode: $e) { id } }`:                                   "1:22: 1 is not a value of enum Episode",
+		`mutation { createReview(episode: JEDI, review: {commentary: "x", rate: 1}) { stars } }`: "1:66: field rate is not defined on input ReviewInput; 1:48: field stars of input ReviewInput is required",
+		`mutation { createReview(episode: JEDI, review: "x") { stars } }`:                        `1:48: "x" is not a valid ReviewInput`,
+		`subscription { hero { id } }`:                                                           "1:1: schema doesn't support subscription",
+		`query A { hero { id } } query A { hero { id } }`:                                        "1:25: operation A is defined more than once",
+		`{ hero { id } } query A { hero { id } }`:                                                "1:1: anonymous operation must be the only operation",
+		`{ hero { __typename { a } } }`:                                                          "1:10: field __typename of type String must not have a selection",
+	} {
+		err := Validate(s, MustParse(query))
+		if assert.Error(t, err, query) {
+			assert.Equal(t, message, err.Error(), query)
+		}
+	}
+}
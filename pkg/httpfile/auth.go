@@ -0,0 +1,283 @@
+package httpfile
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// digestCredential is the username and password of digest authentication
+type digestCredential struct {
+	username string
+	password string
+}
+
+// authorize rewrite Authorization helper syntax to real header as REST Client does, it's called
+// after variables are replaced
+//
+//	Authorization: Basic user:password
+//	Authorization: Basic user password
+//	Authorization: Digest user password
+//	Authorization: AWS accessId accessKey [token:sessionToken] [region:regionName] [service:serviceName]
+func (c *Case) authorize() {
+	c.digest = nil
+	value := c.request.Header.Peek("Authorization")
+	if !maybeAuthHelper(value) {
+		return
+	}
+	helper, ok := parseAuthHelper(string(value))
+	if !ok {
+		return
+	}
+
+	switch helper.scheme {
+	case "basic":
+		c.request.Header.Set("Authorization", basicAuth(helper.user, helper.password))
+	case "digest":
+		// digest is sent after server challenge
+		c.digest = &digestCredential{username: helper.user, password: helper.password}
+		c.request.Header.Del("Authorization")
+	case "aws":
+		signer := awsSigner{
+			accessID:  helper.user,
+			accessKey: helper.password,
+			token:     helper.options["token"],
+			region:    helper.options["region"],
+			service:   helper.options["service"],
+		}
+		c.request.Header.Del("Authorization")
+		signer.sign(c.request, time.Now())
+	}
+}
+
+// authHelper is Authorization helper syntax, user and password are access id and key of aws
+type authHelper struct {
+	scheme   string            // basic, digest or aws
+	user     string            // username or access id
+	password string            // password or access key
+	options  map[string]string // token, region and service of aws
+}
+
+// maybeAuthHelper check scheme of Authorization without allocation, so cases
+// with real header like Bearer token or Basic dXNlcjpwYXNz are skipped quickly
+func maybeAuthHelper(value []byte) bool {
+	value = bytes.TrimSpace(value)
+	pos := bytes.IndexAny(value, " \t")
+	if pos < 0 {
+		return false
+	}
+	scheme, rest := value[:pos], bytes.TrimSpace(value[pos:])
+	switch {
+	case bytes.EqualFold(scheme, []byte("basic")):
+		// real credential is a single base64 token
+		return bytes.ContainsAny(rest, " \t:")
+	case bytes.EqualFold(scheme, []byte("digest")), bytes.EqualFold(scheme, []byte("aws")):
+		return true
+	}
+	return false
+}
+
+// parseAuthHelper parse Authorization helper syntax, false if value is a real header
+// like Basic dXNlcjpwYXNz or Bearer token
+func parseAuthHelper(value string) (authHelper, bool) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return authHelper{}, false
+	}
+	helper := authHelper{scheme: strings.ToLower(fields[0])}
+
+	switch helper.scheme {
+	case "basic":
+		if len(fields) == 3 {
+			helper.user, helper.password = fields[1], fields[2]
+			return helper, true
+		} else if len(fields) == 2 && strings.Contains(fields[1], ":") {
+			helper.user, helper.password, _ = strings.Cut(fields[1], ":")
+			return helper, true
+		}
+	case "digest":
+		if len(fields) == 3 {
+			helper.user, helper.password = fields[1], fields[2]
+			return helper, true
+		}
+	case "aws":
+		if len(fields) >= 3 {
+			helper.user, helper.password = fields[1], fields[2]
+			helper.options = make(map[string]string)
+			for _, option := range fields[3:] {
+				key, val, _ := strings.Cut(option, ":")
+				helper.options[key] = val
+			}
+			return helper, true
+		}
+	}
+	return authHelper{}, false
+}
+
+func basicAuth(user, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password))
+}
+
+// digestAuth resend request with digest credential if server challenge it
+func (f *HTTPFile) digestAuth(client *fasthttp.Client, to *Case, jar *CookieJar) error {
+	if to.digest == nil || to.response.StatusCode() != fasthttp.StatusUnauthorized {
+		return nil
+	}
+
+	challenge := parseChallenge(to.response.Header.Peek("WWW-Authenticate"))
+	if challenge == nil {
+		return nil
+	}
+	auth, err := digestAuthorization(to.digest, challenge, to.request)
+	if err != nil {
+		return err
+	}
+	to.request.Header.Set("Authorization", auth)
+
+	if !to.noCookieJar {
+		to.request.Header.DelAllCookies()
+		jar.apply(to.request)
+	}
+
+	to.response.Reset()
+	t1 := time.Now()
+	err = client.Do(to.request, to.response)
+	if err != nil {
+		return fmt.Errorf("request %s failed: %w", string(to.request.RequestURI()), err)
+	}
+	t2 := time.Now()
+
+	if !to.noCookieJar {
+		jar.update(to.request, to.response)
+	}
+
+	to.RespTime += t2.Sub(t1)
+	to.RequestSize += len(to.request.Header.Header()) + len(to.request.Body()) + len(to.request.RequestURI())
+	to.ResponseSize += len(to.response.Header.Header()) + len(to.response.Body())
+	return nil
+}
+
+// parseChallenge parse WWW-Authenticate: Digest realm="a", nonce="b"
+func parseChallenge(header []byte) map[string]string {
+	text := strings.TrimSpace(string(header))
+	if len(text) < 7 || !strings.EqualFold(text[:7], "Digest ") {
+		return nil
+	}
+	text = text[7:]
+
+	params := make(map[string]string)
+	for len(text) > 0 {
+		text = strings.TrimLeft(text, " ,")
+		eq := strings.IndexByte(text, '=')
+		if eq < 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(text[:eq]))
+		text = strings.TrimSpace(text[eq+1:])
+
+		var val string
+		if strings.HasPrefix(text, `"`) {
+			end := 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(text) {
+				end = len(text) - 1
+			}
+			val = strings.ReplaceAll(text[1:end], `\`, "")
+			text = text[end+1:]
+		} else {
+			comma := strings.IndexByte(text, ',')
+			if comma < 0 {
+				comma = len(text)
+			}
+			val = strings.TrimSpace(text[:comma])
+			text = text[comma:]
+		}
+		params[key] = val
+	}
+	return params
+}
+
+func digestHash(algorithm string) (func() hash.Hash, error) {
+	switch strings.TrimSuffix(strings.ToUpper(algorithm), "-SESS") {
+	case "", "MD5":
+		return md5.New, nil
+	case "SHA-256":
+		return sha256.New, nil
+	}
+	return nil, fmt.Errorf("unsupported digest algorithm %s", algorithm)
+}
+
+// digestAuthorization compute Authorization header of digest authentication by RFC 7616
+func digestAuthorization(cred *digestCredential, challenge map[string]string, req *fasthttp.Request) (string, error) {
+	algorithm := challenge["algorithm"]
+	newHash, err := digestHash(algorithm)
+	if err != nil {
+		return "", err
+	}
+	h := func(s string) string {
+		d := newHash()
+		d.Write([]byte(s))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	realm, nonce := challenge["realm"], challenge["nonce"]
+	uri := string(req.URI().RequestURI())
+	method := string(req.Header.Method())
+
+	cnonceBytes := make([]byte, 8)
+	rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := "00000001"
+
+	ha1 := h(cred.username + ":" + realm + ":" + cred.password)
+	if strings.HasSuffix(strings.ToLower(algorithm), "-sess") {
+		ha1 = h(ha1 + ":" + nonce + ":" + cnonce)
+	}
+
+	qop := ""
+	for _, q := range strings.Split(challenge["qop"], ",") {
+		q = strings.TrimSpace(q)
+		if q == "auth" || (q == "auth-int" && qop == "") {
+			qop = q
+		}
+	}
+
+	ha2 := h(method + ":" + uri)
+	if qop == "auth-int" {
+		ha2 = h(method + ":" + uri + ":" + h(string(req.Body())))
+	}
+
+	var response string
+	if qop == "" {
+		response = h(ha1 + ":" + nonce + ":" + ha2)
+	} else {
+		response = h(ha1 + ":" + nonce + ":" + nc + ":" + cnonce + ":" + qop + ":" + ha2)
+	}
+
+	buff := bytes.NewBufferString("Digest ")
+	fmt.Fprintf(buff, `username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`, cred.username, realm, nonce, uri, response)
+	if algorithm != "" {
+		fmt.Fprintf(buff, `, algorithm=%s`, algorithm)
+	}
+	if qop != "" {
+		fmt.Fprintf(buff, `, qop=%s, nc=%s, cnonce="%s"`, qop, nc, cnonce)
+	}
+	if opaque, ok := challenge["opaque"]; ok {
+		fmt.Fprintf(buff, `, opaque="%s"`, opaque)
+	}
+	return buff.String(), nil
+}
@@ -0,0 +1,109 @@
+package httpfile
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func digestServer() *httptest.Server {
+	const realm, nonce = "testrealm@host.com", "dcd98b7102dd2f0e8b11d0f600bfb0c093"
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := parseChallenge([]byte(r.Header.Get("Authorization")))
+		if params != nil {
+			ha1 := md5Hex("Mufasa:" + realm + ":CircleOfLife")
+			ha2 := md5Hex(r.Method + ":" + params["uri"])
+			expected := md5Hex(ha1 + ":" + nonce + ":" + params["nc"] + ":" + params["cnonce"] + ":auth:" + ha2)
+			if params["response"] == expected && params["opaque"] == "5ccc" {
+				w.Write([]byte("welcome"))
+				return
+			}
+		}
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="%s", qop="auth,auth-int", nonce="%s", opaque="5ccc"`, realm, nonce))
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+}
+
+func TestDigestAuth(t *testing.T) {
+	server := digestServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	@user = Mufasa
+	GET %[1]s/dir/index.html?a=b
+	Authorization: Digest {{user}} CircleOfLife
+
+	###
+	GET %[1]s/dir/index.html
+	Authorization: Digest {{user}} wrong
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	assert.Error(t, file.Execute(&fasthttp.Client{}))
+	assert.Equal(t, 200, file.Cases[0].RespCode)
+	assert.Equal(t, "welcome", string(file.Cases[0].response.Body()))
+	assert.Equal(t, 401, file.Cases[1].RespCode)
+}
+
+func TestBasicAuth(t *testing.T) {
+	for _, value := range []string{"Basic foo bar", "Basic foo:bar", "basic   foo   bar", "Basic Zm9vOmJhcg=="} {
+		c := &Case{request: fasthttp.AcquireRequest()}
+		c.request.Header.Set("Authorization", value)
+		c.authorize()
+		assert.Equal(t, "Basic Zm9vOmJhcg==", string(c.request.Header.Peek("Authorization")), value)
+		fasthttp.ReleaseRequest(c.request)
+	}
+}
+
+func TestAWSSignature(t *testing.T) {
+	now, _ := time.Parse("20060102T150405Z", "20150830T123600Z")
+	signer := awsSigner{
+		accessID:  "AKIDEXAMPLE",
+		accessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		region:    "us-east-1",
+		service:   "service",
+	}
+
+	// cases from aws-sig-v4-test-suite
+	cases := map[string]string{
+		"http://example.amazonaws.com/":                             "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		"http://example.amazonaws.com/?Param2=value2&Param1=value1": "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+	}
+	for uri, signature := range cases {
+		req := fasthttp.AcquireRequest()
+		req.Header.SetMethod("GET")
+		req.SetRequestURI(uri)
+		signer.sign(req, now)
+		assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+			"SignedHeaders=host;x-amz-date, Signature="+signature, string(req.Header.Peek("Authorization")), uri)
+		fasthttp.ReleaseRequest(req)
+	}
+
+	for host, scope := range map[string][2]string{
+		"s3.amazonaws.com":                            {"us-east-1", "s3"},
+		"bucket.s3.us-west-2.amazonaws.com":           {"us-west-2", "s3"},
+		"s3-eu-west-1.amazonaws.com":                  {"eu-west-1", "s3"},
+		"abc.execute-api.ap-east-1.amazonaws.com:443": {"ap-east-1", "execute-api"},
+		"minio.local:9000":                            {"us-east-1", "s3"},
+	} {
+		region, service := (&awsSigner{}).scope(host)
+		assert.Equal(t, scope, [2]string{region, service}, host)
+	}
+}
@@ -0,0 +1,166 @@
+package httpfile
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// awsSigner sign request by AWS Signature Version 4
+type awsSigner struct {
+	accessID  string
+	accessKey string
+	token     string
+	region    string
+	service   string
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// awsEscape encode string as RFC 3986, only unreserved characters are kept
+func awsEscape(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('%')
+			b.WriteString(strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return b.String()
+}
+
+var awsRegion, _ = regexp.Compile(`^[a-z]{2}(-gov)?-[a-z]+-\d$`)
+
+// scope infer region and service from host like s3.us-west-2.amazonaws.com
+// when they are not given
+func (s *awsSigner) scope(host string) (string, string) {
+	region, service := s.region, s.service
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if strings.HasSuffix(host, ".amazonaws.com") {
+		parts := strings.Split(strings.TrimSuffix(host, ".amazonaws.com"), ".")
+		last := parts[len(parts)-1]
+		inferService := last
+		if awsRegion.MatchString(last) && len(parts) > 1 {
+			if region == "" {
+				region = last
+			}
+			inferService = parts[len(parts)-2]
+		}
+		if strings.HasPrefix(inferService, "s3-") && region == "" {
+			region = strings.TrimPrefix(inferService, "s3-")
+			inferService = "s3"
+		}
+		if service == "" {
+			service = inferService
+		}
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	if service == "" {
+		service = "s3"
+	}
+	return region, service
+}
+
+// sign add X-Amz-Date, X-Amz-Security-Token and Authorization header to request
+func (s *awsSigner) sign(req *fasthttp.Request, now time.Time) {
+	uri := req.URI()
+	host := string(uri.Host())
+	region, service := s.scope(host)
+
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	payloadHash := sha256Hex(req.Body())
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.token != "" {
+		req.Header.Set("X-Amz-Security-Token", s.token)
+	}
+	if service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	// canonical headers
+	headers := map[string]string{"host": host}
+	req.Header.VisitAll(func(key, val []byte) {
+		k := strings.ToLower(string(key))
+		if strings.HasPrefix(k, "x-amz-") || k == "content-type" {
+			headers[k] = strings.Join(strings.Fields(string(val)), " ")
+		}
+	})
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	// canonical uri, path is encoded twice except s3
+	path := string(uri.Path())
+	if path == "" {
+		path = "/"
+	}
+	path = awsEscape(path, false)
+	if service != "s3" {
+		path = awsEscape(path, false)
+	}
+
+	// canonical query
+	var query []string
+	uri.QueryArgs().VisitAll(func(key, val []byte) {
+		query = append(query, awsEscape(string(key), true)+"="+awsEscape(string(val), true))
+	})
+	sort.Strings(query)
+
+	canonicalRequest := strings.Join([]string{
+		string(req.Header.Method()),
+		path,
+		strings.Join(query, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+s.accessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", "AWS4-HMAC-SHA256 Credential="+s.accessID+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
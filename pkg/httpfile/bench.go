@@ -2,50 +2,79 @@ package httpfile
 
 import (
 	"bytes"
+	"errors"
 	"time"
 
-	"github.com/valyala/fasthttp"
 	"go.uber.org/ratelimit"
 )
 
-var client = &fasthttp.Client{
-	MaxConnsPerHost: 2048,
-	ReadTimeout:     time.Second,
-	WriteTimeout:    time.Second,
-}
-
 var rateLimiter ratelimit.Limiter
 
-func executeN(file *HTTPFile, n int, done chan bool, stats chan Stat) {
+func executeN(file *HTTPFile, n int, cfg *ClientConfig, done chan bool, stats chan Stat) {
+
+	vu := newVirtualUser(cfg)
+
+	// duplicate of virtual user is reset for each iteration, it's released at last
+	w := file.Duplicate(true, false)
+	w.Jar = vu.jar
+	w.MaxRedirects = cfg.MaxRedirects
+	w.AutoClean = false
+	defer w.Release()
 
 	for i := 0; i < n; i++ {
 		if rateLimiter != nil {
 			rateLimiter.Take()
 		}
 
-		w := file.Duplicate(true, true)
-		err := w.Execute(client)
+		opened, closed := vu.counter.Opened(), vu.counter.Closed()
+		handshakes, resumed, handshakeTime := vu.tls.Handshakes(), vu.tls.Resumed(), vu.tls.HandshakeTime()
+
+		if i > 0 {
+			w.reset(file)
+		}
+		err := w.Execute(vu.client)
+
+		// last iteration, close connections owned by virtual user
+		if i == n-1 {
+			vu.Close()
+		}
 
 		var stat Stat
 		if err != nil {
 			stat.Failed = 1
+			var gqlErr *GraphQLError
+			if errors.As(err, &gqlErr) {
+				stat.GraphQLErrors = gqlErr.Classes
+			}
 		} else {
 			stat.Successed = 1
 		}
 		stat.Requests = stat.Successed + stat.Failed
+		sent := 0
 		for _, c := range w.Cases {
+			if c.RespCode != 0 {
+				sent += 1 + c.Redirects
+			}
+			stat.Redirects = stat.Redirects + c.Redirects
 			stat.BytesSend = stat.BytesSend + c.RequestSize
 			stat.BytesReceived = stat.BytesReceived + c.ResponseSize
 			stat.TimeConsuming = stat.TimeConsuming + c.RespTime.Seconds()
 		}
-		w.Release()
+		stat.ConnOpened = vu.counter.Opened() - opened
+		stat.ConnClosed = vu.counter.Closed() - closed
+		stat.TLSHandshakes = vu.tls.Handshakes() - handshakes
+		stat.TLSResumed = vu.tls.Resumed() - resumed
+		stat.HandshakeTime = (vu.tls.HandshakeTime() - handshakeTime).Seconds()
+		if sent > stat.ConnOpened {
+			stat.ConnReused = sent - stat.ConnOpened
+		}
 		stats <- stat
 	}
 	done <- true
 }
 
-// Bench the httpfile
-func Bench(file *HTTPFile, connections, requests, rateLimit int) ([]Stat, float64) {
+// Bench the httpfile, each connection is a virtual user own it's connection
+func Bench(file *HTTPFile, connections, requests, rateLimit int, opts ...ClientOpt) ([]Stat, float64) {
 
 	stats := make(chan Stat, 1024)
 	done := make(chan bool, connections)
@@ -59,8 +88,13 @@ func Bench(file *HTTPFile, connections, requests, rateLimit int) ([]Stat, float6
 		rateLimiter = nil
 	}
 
+	cfg := newClientConfig(opts...)
+	if file.Env != nil {
+		file.Env.configure(cfg)
+	}
+
 	for c := 0; c < connections; c++ {
-		go executeN(file, requestsPerConnection, done, stats)
+		go executeN(file, requestsPerConnection, cfg, done, stats)
 	}
 	results := make([]Stat, 0)
 	t1 := time.Now()
@@ -71,6 +105,10 @@ func Bench(file *HTTPFile, connections, requests, rateLimit int) ([]Stat, float6
 		case <-done:
 			doneCounter = doneCounter + 1
 			if doneCounter == connections {
+				// drain stats sent before done
+				for len(stats) > 0 {
+					results = append(results, <-stats)
+				}
 				t2 := time.Now()
 				return results, t2.Sub(t1).Seconds()
 			}
@@ -79,10 +117,18 @@ func Bench(file *HTTPFile, connections, requests, rateLimit int) ([]Stat, float6
 }
 
 // Execute the file once
-func Execute(file *HTTPFile) string {
-	client := &fasthttp.Client{}
-	w := file.Duplicate(true, true)
-	err := w.Execute(client)
+func Execute(file *HTTPFile, opts ...ClientOpt) string {
+	cfg := newClientConfig(opts...)
+	if file.Env != nil {
+		file.Env.configure(cfg)
+	}
+	vu := newVirtualUser(cfg)
+	defer vu.Close()
+
+	w := file.Duplicate(true, false)
+	w.Jar = vu.jar
+	w.MaxRedirects = cfg.MaxRedirects
+	err := w.Execute(vu.client)
 	if err != nil {
 		return err.Error()
 	}
@@ -0,0 +1,139 @@
+package httpfile
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ClientConfig is the config of clients used by virtual users
+type ClientConfig struct {
+	ReadTimeout  time.Duration // read timeout of a request
+	WriteTimeout time.Duration // write timeout of a request
+	DialTimeout  time.Duration // timeout of establish a connection
+	TLSConfig    *tls.Config   // tls config for https service
+	Proxy        string        // proxy url, empty is from environment
+	MaxRedirects int           // max redirects followed by a case
+}
+
+// ClientOpt is option when create client
+type ClientOpt func(c *ClientConfig)
+
+// WithTimeout set read, write and dial timeout
+func WithTimeout(timeout time.Duration) ClientOpt {
+	return func(c *ClientConfig) {
+		c.ReadTimeout = timeout
+		c.WriteTimeout = timeout
+		c.DialTimeout = timeout
+	}
+}
+
+func newClientConfig(opts ...ClientOpt) *ClientConfig {
+	cfg := &ClientConfig{
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+		DialTimeout:  3 * time.Second,
+		MaxRedirects: DefaultMaxRedirects,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// connCounter count connections opened and closed by a virtual user
+type connCounter struct {
+	opened int64
+	closed int64
+}
+
+func (cc *connCounter) Opened() int {
+	return int(atomic.LoadInt64(&cc.opened))
+}
+
+func (cc *connCounter) Closed() int {
+	return int(atomic.LoadInt64(&cc.closed))
+}
+
+// countedConn is a net.Conn which report it's close to connCounter
+type countedConn struct {
+	net.Conn
+	counter *connCounter
+	once    sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(func() {
+		atomic.AddInt64(&c.counter.closed, 1)
+	})
+	return c.Conn.Close()
+}
+
+// virtualUser is a independent client of service, it own it's connection
+// like ab and wrk, so -c 200 really means 200 connections
+type virtualUser struct {
+	client  *fasthttp.Client
+	counter *connCounter
+	tls     *tlsCounter
+	jar     *CookieJar
+}
+
+func newVirtualUser(cfg *ClientConfig) *virtualUser {
+	vu := &virtualUser{
+		counter: &connCounter{},
+		tls:     &tlsCounter{},
+		jar:     NewCookieJar(),
+	}
+	vu.client = &fasthttp.Client{
+		// one connection per host for each virtual user
+		MaxConnsPerHost: 1,
+		ReadTimeout:     cfg.ReadTimeout,
+		WriteTimeout:    cfg.WriteTimeout,
+		ConfigureClient: vu.configureClient(cfg),
+	}
+	return vu
+}
+
+// configureClient set dialer for each host, the dialer connect through proxy
+// and do tls handshake if needed
+func (vu *virtualUser) configureClient(cfg *ClientConfig) func(hc *fasthttp.HostClient) error {
+	selector := newProxySelector(cfg.Proxy)
+	configureTLS := vu.configureTLS(cfg)
+
+	return func(hc *fasthttp.HostClient) error {
+		proxyURL, err := selector.proxyFor(hc)
+		if err != nil {
+			return fmt.Errorf("select proxy: %w", err)
+		}
+		hc.Dial = vu.dialer(cfg, proxyURL, hc.IsTLS)
+		return configureTLS(hc)
+	}
+}
+
+func (vu *virtualUser) dialer(cfg *ClientConfig, proxyURL *url.URL, isTLS bool) fasthttp.DialFunc {
+	return func(addr string) (net.Conn, error) {
+		var conn net.Conn
+		var err error
+		if proxyURL != nil {
+			conn, err = dialProxy(proxyURL, addr, isTLS, cfg)
+		} else {
+			conn, err = fasthttp.DialTimeout(addr, cfg.DialTimeout)
+		}
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&vu.counter.opened, 1)
+		return &countedConn{Conn: conn, counter: vu.counter}, nil
+	}
+}
+
+// Close the connections owned by virtual user
+func (vu *virtualUser) Close() {
+	vu.client.CloseIdleConnections()
+}
@@ -0,0 +1,42 @@
+package httpfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionPerVirtualUser(t *testing.T) {
+	content := fmt.Sprintf(`
+	@server = %s
+
+	# @name=case1
+	POST {{server}}
+	Content-Type: application/json
+
+	{
+		"a": "b"
+	}
+	###
+
+	POST {{server}}
+	Content-Type: application/json
+
+	{
+		"a1": "{{case1.response.body.$.a}}"
+	}
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := ReportStat(Bench(file, 4, 40, -1))
+
+	assert.Equal(t, 40, report.Successed)
+	assert.Equal(t, 4, report.ConnOpened)
+	assert.Equal(t, 4, report.ConnClosed)
+	assert.Equal(t, 76, report.ConnReused)
+}
@@ -0,0 +1,108 @@
+package httpfile
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// CookieJar remember cookies for subsequent requests like a browser,
+// Set-Cookie domain, path and expiry are honored
+type CookieJar struct {
+	jar *cookiejar.Jar
+}
+
+// NewCookieJar create a empty cookie jar
+func NewCookieJar() *CookieJar {
+	jar, _ := cookiejar.New(nil)
+	return &CookieJar{jar: jar}
+}
+
+func requestURL(req *fasthttp.Request) *url.URL {
+	u, err := url.Parse(req.URI().String())
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// apply add cookies in jar to request
+func (j *CookieJar) apply(req *fasthttp.Request) {
+	u := requestURL(req)
+	if u == nil {
+		return
+	}
+	for _, c := range j.jar.Cookies(u) {
+		// cookie given in case has high priority
+		if len(req.Header.Cookie(c.Name)) == 0 {
+			req.Header.SetCookie(c.Name, c.Value)
+		}
+	}
+}
+
+// update save cookies set by response
+func (j *CookieJar) update(req *fasthttp.Request, resp *fasthttp.Response) {
+	header := http.Header{}
+	resp.Header.VisitAllCookie(func(key, value []byte) {
+		header.Add("Set-Cookie", string(value))
+	})
+	if len(header) == 0 {
+		return
+	}
+
+	u := requestURL(req)
+	if u == nil {
+		return
+	}
+	j.jar.SetCookies(u, (&http.Response{Header: header}).Cookies())
+}
+
+// Cookies return cookies in jar which will be sent to url
+func (j *CookieJar) Cookies(rawURL string) map[string]string {
+	result := make(map[string]string)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return result
+	}
+	for _, c := range j.jar.Cookies(u) {
+		result[c.Name] = c.Value
+	}
+	return result
+}
+
+// getCookieVariable get cookie variable like login.response.cookie.SESSION
+func (f *HTTPFile) getCookieVariable(key string) (string, bool) {
+	args := strings.SplitN(key, ".", 4)
+	if len(args) != 4 || args[2] != "cookie" {
+		return "", false
+	}
+
+	caseName, kind, name := args[0], args[1], args[3]
+	theCase := f.findCaseByName(caseName)
+	if theCase == nil {
+		return "", false
+	}
+
+	switch kind {
+	case "request":
+		if theCase.request != nil {
+			val := theCase.request.Header.Cookie(name)
+			if val != nil {
+				return string(val), true
+			}
+		}
+	case "response":
+		if theCase.response != nil {
+			c := fasthttp.AcquireCookie()
+			defer fasthttp.ReleaseCookie(c)
+			c.SetKey(name)
+			if theCase.response.Header.Cookie(c) {
+				return string(c.Value()), true
+			}
+		}
+	}
+	return "", false
+}
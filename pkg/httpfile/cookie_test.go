@@ -0,0 +1,80 @@
+package httpfile
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func cookieServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "SESSION", Value: "s1", Path: "/"})
+			http.SetCookie(w, &http.Cookie{Name: "ADMIN", Value: "a1", Path: "/admin"})
+			http.SetCookie(w, &http.Cookie{Name: "OLD", Value: "o1", MaxAge: -1})
+		case "/logout":
+			http.SetCookie(w, &http.Cookie{Name: "SESSION", Value: "", Path: "/", MaxAge: -1})
+		}
+		w.Write([]byte(r.Header.Get("Cookie")))
+	}))
+}
+
+func TestCookieJar(t *testing.T) {
+	server := cookieServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @name login
+	GET %[1]s/login
+
+	###
+	# @name profile
+	GET %[1]s/profile
+	X-Session: {{login.response.cookie.SESSION}}
+
+	###
+	# @name admin
+	GET %[1]s/admin/users
+
+	###
+	# @name anonymous
+	# @no-cookie-jar
+	GET %[1]s/profile
+
+	###
+	# @name logout
+	GET %[1]s/logout
+
+	###
+	# @name after
+	GET %[1]s/profile
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "SESSION=s1", string(file.Cases[1].response.Body()))
+	assert.Equal(t, "s1", string(file.Cases[1].request.Header.Peek("X-Session")))
+	assert.Equal(t, "ADMIN=a1; SESSION=s1", string(file.Cases[2].response.Body()))
+	assert.Equal(t, "", string(file.Cases[3].response.Body()))
+	assert.Equal(t, "", string(file.Cases[5].response.Body()))
+
+	val, ok := file.Get("login.response.cookie.ADMIN")
+	assert.True(t, ok)
+	assert.Equal(t, "a1", val)
+	_, ok = file.Get("login.response.cookie.NONE")
+	assert.False(t, ok)
+}
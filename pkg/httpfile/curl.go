@@ -0,0 +1,574 @@
+package httpfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// curl https://example.com -H 'Accept: */*'
+var curlTag, _ = regexp.Compile(`^\s*curl(\s|$)`)
+
+// errIncompleteCommand means command line has unterminated quote or ends with backslash,
+// next line should be joined
+var errIncompleteCommand = errors.New("incomplete command")
+
+// formBoundary is boundary of multipart body built by -F, fixed to make import stable
+const formBoundary = "ftab-form-boundary"
+
+// curl options have a value, short options are mapped to long ones, other options are
+// ignored flags
+var curlOptions = map[string]string{
+	"-X": "--request", "-H": "--header", "-d": "--data", "-F": "--form", "-u": "--user",
+	"-b": "--cookie", "-A": "--user-agent", "-e": "--referer", "-o": "--output",
+	"-m": "--max-time", "-x": "--proxy", "-c": "--cookie-jar", "-E": "--cert",
+	"-w": "--write-out", "-T": "--upload-file", "-U": "--proxy-user", "-r": "--range",
+	"-D": "--dump-header", "-K": "--config", "-C": "--continue-at", "-Y": "--speed-limit",
+	"-y": "--speed-time", "-z": "--time-cond", "-Q": "--quote", "-P": "--ftp-port",
+	"--request": "", "--header": "", "--data": "", "--data-ascii": "", "--data-binary": "",
+	"--data-raw": "", "--data-urlencode": "", "--json": "", "--form": "", "--form-string": "",
+	"--user": "", "--cookie": "", "--user-agent": "", "--referer": "", "--url": "",
+	"--output": "", "--max-time": "", "--connect-timeout": "", "--proxy": "", "--cookie-jar": "",
+	"--cert": "", "--key": "", "--cacert": "", "--write-out": "", "--upload-file": "",
+	"--proxy-user": "", "--range": "", "--retry": "", "--max-redirs": "", "--resolve": "",
+	"--interface": "", "--oauth2-bearer": "", "--aws-sigv4": "",
+	"--dump-header": "", "--config": "", "--continue-at": "", "--speed-limit": "",
+	"--speed-time": "", "--time-cond": "", "--quote": "", "--ftp-port": "", "--limit-rate": "",
+	"--proxy-header": "", "--max-filesize": "", "--retry-delay": "", "--retry-max-time": "",
+	"--keepalive-time": "", "--expect100-timeout": "", "--connect-to": "", "--noproxy": "",
+	"--cert-type": "", "--key-type": "", "--pass": "", "--capath": "", "--ciphers": "",
+	"--pinnedpubkey": "", "--proxy-cacert": "", "--proxy-cert": "", "--proxy-key": "",
+	"--socks5": "", "--socks5-hostname": "", "--preproxy": "", "--unix-socket": "",
+	"--dns-servers": "", "--doh-url": "", "--local-port": "", "--tls-max": "", "--trace": "",
+	"--trace-ascii": "", "--stderr": "", "--netrc-file": "", "--output-dir": "",
+	"--happy-eyeballs-timeout-ms": "", "--etag-save": "", "--etag-compare": "",
+}
+
+// curl flags change request, short flags are mapped to long ones
+var curlFlags = map[string]string{
+	"-G": "--get", "-I": "--head",
+}
+
+// curlFormField is a field of -F, value of --form-string is never read from file
+type curlFormField struct {
+	value   string
+	literal bool
+}
+
+// curlRequest is the request described by options of curl
+type curlRequest struct {
+	method     string
+	url        string
+	headers    [][2]string
+	data       []string
+	form       []curlFormField
+	json       bool
+	get        bool
+	head       bool
+	user       string
+	digest     bool
+	cookies    []string
+	compressed bool
+}
+
+// ParseCurl parse cURL command lines to cases, commands are started by curl and lines
+// are continued by ending backslash
+func ParseCurl(text string) ([]*Case, error) {
+	var cases []*Case
+	var command string
+	for _, line := range strings.Split(text, "\n") {
+		if command == "" {
+			if !curlTag.MatchString(line) {
+				if strings.TrimSpace(line) != "" && !commentTag.MatchString(line) {
+					return nil, fmt.Errorf("curl: %q is not a curl command", line)
+				}
+				continue
+			}
+			command = line
+		} else {
+			command += "\n" + line
+		}
+
+		if _, err := splitCommand(command); errors.Is(err, errIncompleteCommand) {
+			continue
+		}
+		c := newCase()
+		if err := parseCurl(c.request, command); err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+		command = ""
+	}
+	if command != "" {
+		return nil, fmt.Errorf("curl: %w", errIncompleteCommand)
+	}
+	return cases, nil
+}
+
+// parseCurl set request by a curl command line
+func parseCurl(req *fasthttp.Request, command string) error {
+	words, err := splitCommand(command)
+	if err != nil {
+		return err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return errors.New("curl: command must start with curl")
+	}
+
+	cr := &curlRequest{}
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		if !strings.HasPrefix(word, "-") {
+			if err := cr.setURL(word); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var name, value string
+		hasValue := false
+		if strings.HasPrefix(word, "--") {
+			name, value, hasValue = strings.Cut(word, "=")
+			if _, ok := curlOptions[name]; !ok {
+				cr.flag(name)
+				continue
+			}
+		} else {
+			// short flags may be combined like -sSL, a short option takes rest of word
+			// or next word as value like -XPOST
+			for j := 1; j < len(word); j++ {
+				short := "-" + word[j:j+1]
+				if _, ok := curlOptions[short]; ok {
+					name = short
+					value, hasValue = word[j+1:], j+1 < len(word)
+					break
+				}
+				cr.flag(short)
+			}
+			if name == "" {
+				continue
+			}
+		}
+
+		if !hasValue {
+			if i+1 >= len(words) {
+				return fmt.Errorf("curl: option %s requires a value", name)
+			}
+			i++
+			value = words[i]
+		}
+		if long := curlOptions[name]; long != "" {
+			name = long
+		}
+		if err := cr.option(name, value); err != nil {
+			return err
+		}
+	}
+	return cr.build(req)
+}
+
+func (cr *curlRequest) setURL(value string) error {
+	if cr.url != "" {
+		return errors.New("curl: only one url is supported")
+	}
+	cr.url = value
+	return nil
+}
+
+func (cr *curlRequest) flag(name string) {
+	if long, ok := curlFlags[name]; ok {
+		name = long
+	}
+	switch name {
+	case "--get":
+		cr.get = true
+	case "--head":
+		cr.head = true
+	case "--compressed":
+		cr.compressed = true
+	case "--digest":
+		cr.digest = true
+	}
+}
+
+func (cr *curlRequest) option(name, value string) error {
+	switch name {
+	case "--request":
+		cr.method = strings.ToUpper(value)
+	case "--header":
+		key, val, ok := strings.Cut(value, ":")
+		if !ok {
+			// -H 'X-Empty;' send empty header, -H 'Accept:' remove it
+			if !strings.HasSuffix(value, ";") {
+				return fmt.Errorf("curl: invalid header %q", value)
+			}
+			key = strings.TrimSuffix(value, ";")
+		}
+		if val = strings.TrimSpace(val); val != "" || !ok {
+			cr.headers = append(cr.headers, [2]string{strings.TrimSpace(key), val})
+		}
+	case "--data", "--data-ascii", "--data-binary":
+		data, err := readCurlData(value, name != "--data-binary")
+		if err != nil {
+			return err
+		}
+		cr.data = append(cr.data, data)
+	case "--data-raw":
+		cr.data = append(cr.data, value)
+	case "--json":
+		data, err := readCurlData(value, false)
+		if err != nil {
+			return err
+		}
+		cr.data = append(cr.data, data)
+		cr.json = true
+	case "--data-urlencode":
+		data, err := urlencodeCurlData(value)
+		if err != nil {
+			return err
+		}
+		cr.data = append(cr.data, data)
+	case "--form":
+		cr.form = append(cr.form, curlFormField{value: value})
+	case "--form-string":
+		cr.form = append(cr.form, curlFormField{value: value, literal: true})
+	case "--user":
+		cr.user = value
+	case "--cookie":
+		// value without = is a cookie file, it isn't imported
+		if strings.Contains(value, "=") {
+			cr.cookies = append(cr.cookies, value)
+		}
+	case "--user-agent":
+		cr.headers = append(cr.headers, [2]string{"User-Agent", value})
+	case "--referer":
+		if value, _, _ = strings.Cut(value, ";auto"); value != "" {
+			cr.headers = append(cr.headers, [2]string{"Referer", value})
+		}
+	case "--oauth2-bearer":
+		cr.headers = append(cr.headers, [2]string{"Authorization", "Bearer " + value})
+	case "--url":
+		return cr.setURL(value)
+	}
+	return nil
+}
+
+// readCurlData read data from file by @file, line breaks are stripped as -d does
+func readCurlData(value string, strip bool) (string, error) {
+	if !strings.HasPrefix(value, "@") {
+		return value, nil
+	}
+	content, err := os.ReadFile(value[1:])
+	if err != nil {
+		return "", fmt.Errorf("curl: %w", err)
+	}
+	if strip {
+		content = bytes.ReplaceAll(bytes.ReplaceAll(content, []byte("\r"), nil), []byte("\n"), nil)
+	}
+	return string(content), nil
+}
+
+// urlencodeCurlData encode data of --data-urlencode, which is content, =content,
+// name=content, @file or name@file
+func urlencodeCurlData(value string) (string, error) {
+	if i := strings.IndexAny(value, "=@"); i >= 0 {
+		name, content := value[:i], value[i+1:]
+		if value[i] == '@' {
+			data, err := os.ReadFile(content)
+			if err != nil {
+				return "", fmt.Errorf("curl: %w", err)
+			}
+			content = string(data)
+		}
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+	return url.QueryEscape(value), nil
+}
+
+// build set request, method and content type are decided as curl does
+func (cr *curlRequest) build(req *fasthttp.Request) error {
+	if cr.url == "" {
+		return errors.New("curl: no url is given")
+	}
+	uri := cr.url
+	if !strings.Contains(uri, "://") && !strings.HasPrefix(uri, "{{") {
+		uri = "http://" + uri
+	}
+
+	method := fasthttp.MethodGet
+	data := strings.Join(cr.data, "&")
+	switch {
+	case cr.head:
+		method = fasthttp.MethodHead
+	case cr.get:
+		if data != "" {
+			if strings.Contains(uri, "?") {
+				uri += "&" + data
+			} else {
+				uri += "?" + data
+			}
+		}
+	case len(cr.form) > 0:
+		method = fasthttp.MethodPost
+		if err := buildCurlForm(req, cr.form); err != nil {
+			return err
+		}
+	case len(cr.data) > 0:
+		method = fasthttp.MethodPost
+		req.SetBodyString(data)
+		if cr.json {
+			req.Header.SetContentType("application/json")
+			req.Header.Set("Accept", "application/json")
+		} else {
+			req.Header.SetContentType("application/x-www-form-urlencoded")
+		}
+	}
+	if cr.method != "" {
+		method = cr.method
+	}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+
+	for _, kv := range cr.headers {
+		req.Header.Set(kv[0], kv[1])
+	}
+	if cr.user != "" && len(req.Header.Peek("Authorization")) == 0 {
+		auth, err := curlAuthorization(cr.user, cr.digest)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", auth)
+	}
+	if len(cr.cookies) > 0 {
+		cookie := string(req.Header.Peek("Cookie"))
+		if cookie != "" {
+			cookie += "; "
+		}
+		req.Header.Set("Cookie", cookie+strings.Join(cr.cookies, "; "))
+	}
+	if cr.compressed && len(req.Header.Peek("Accept-Encoding")) == 0 {
+		req.Header.Set("Accept-Encoding", "gzip, deflate")
+	}
+	return nil
+}
+
+// curlAuthorization use helper syntax of Authorization when possible, it's rewritten by
+// authorize when case is executed. helper syntax of digest can't hold whitespace, and
+// digest must be computed after server challenge, so such credential is refused
+func curlAuthorization(user string, digest bool) (string, error) {
+	name, password, _ := strings.Cut(user, ":")
+	if strings.ContainsAny(user, " \t") {
+		if digest {
+			return "", fmt.Errorf("curl: digest credential %q with whitespace is not supported", name)
+		}
+		return basicAuth(name, password), nil
+	}
+	if digest {
+		return "Digest " + name + " " + password, nil
+	}
+	return "Basic " + name + ":" + password, nil
+}
+
+// buildCurlForm build multipart body by -F values like name=value, name=@file;type=text/plain
+// and name=<file
+func buildCurlForm(req *fasthttp.Request, form []curlFormField) error {
+	body := bytes.NewBuffer(nil)
+	w := multipart.NewWriter(body)
+	w.SetBoundary(formBoundary)
+	for _, field := range form {
+		name, value, ok := strings.Cut(field.value, "=")
+		if !ok {
+			return fmt.Errorf("curl: invalid form %q", field.value)
+		}
+		if field.literal || (!strings.HasPrefix(value, "@") && !strings.HasPrefix(value, "<")) {
+			w.WriteField(name, value)
+			continue
+		}
+
+		params := strings.Split(value[1:], ";")
+		file, contentType, fileName := params[0], "", filepath.Base(params[0])
+		for _, param := range params[1:] {
+			k, v, _ := strings.Cut(param, "=")
+			switch strings.TrimSpace(k) {
+			case "type":
+				contentType = v
+			case "filename":
+				fileName = strings.Trim(v, `"`)
+			}
+		}
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("curl: %w", err)
+		}
+
+		header := make(textproto.MIMEHeader)
+		if value[0] == '@' {
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, name, fileName))
+			if contentType == "" {
+				contentType = "application/octet-stream"
+			}
+		} else {
+			header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, name))
+		}
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		part, _ := w.CreatePart(header)
+		part.Write(content)
+	}
+	w.Close()
+	req.SetBody(body.Bytes())
+	req.Header.SetContentType(w.FormDataContentType())
+	return nil
+}
+
+// splitCommand split command line to words as shell does, single quotes, double quotes,
+// $'...' and backslash escapes are supported, errIncompleteCommand is returned when a quote
+// is unterminated or line ends with backslash
+func splitCommand(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\':
+			if i+1 >= len(s) {
+				return nil, errIncompleteCommand
+			}
+			i++
+			if s[i] == '\n' {
+				continue
+			}
+			word.WriteByte(s[i])
+			inWord = true
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errIncompleteCommand
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'':
+			n, err := unquoteANSIC(s[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 1
+			inWord = true
+		case c == '"':
+			n, err := unquoteDouble(s[i+1:], &word)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// unquoteDouble write content of "..." to word, s is after the opening quote, length of
+// content and closing quote is returned
+func unquoteDouble(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return i + 1, nil
+		case '\\':
+			if i+1 >= len(s) {
+				return 0, errIncompleteCommand
+			}
+			switch s[i+1] {
+			case '"', '\\', '$', '`':
+				i++
+				word.WriteByte(s[i])
+			case '\n':
+				i++
+			default:
+				word.WriteByte('\\')
+			}
+		default:
+			word.WriteByte(s[i])
+		}
+	}
+	return 0, errIncompleteCommand
+}
+
+// unquoteANSIC write content of $'...' to word, s is after the opening quote, length of
+// content and closing quote is returned
+func unquoteANSIC(s string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			return i + 1, nil
+		}
+		if s[i] != '\\' {
+			word.WriteByte(s[i])
+			continue
+		}
+		if i+1 >= len(s) {
+			return 0, errIncompleteCommand
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			word.WriteByte('\n')
+		case 't':
+			word.WriteByte('\t')
+		case 'r':
+			word.WriteByte('\r')
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[i]]
+			end := i + 1
+			for end < len(s) && end < i+1+size && isHex(s[end]) {
+				end++
+			}
+			if end == i+1 {
+				return 0, fmt.Errorf("curl: invalid escape \\%c", s[i])
+			}
+			code, _ := strconv.ParseUint(s[i+1:end], 16, 32)
+			if s[i] == 'x' {
+				word.WriteByte(byte(code))
+			} else {
+				var buf [utf8.UTFMax]byte
+				word.Write(buf[:utf8.EncodeRune(buf[:], rune(code))])
+			}
+			i = end - 1
+		default:
+			// \\ \' \" and unknown escapes
+			word.WriteByte(s[i])
+		}
+	}
+	return 0, errIncompleteCommand
+}
+
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
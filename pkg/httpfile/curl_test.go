@@ -0,0 +1,194 @@
+package httpfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSplitCommand(t *testing.T) {
+	words, err := splitCommand(`curl 'a b' "c \"d\" \$e \x" $'f\n\'g\x41中' h\ i \
+	  j""k`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"curl", "a b", `c "d" $e \x`, "f\n'gA中", "h i", "jk"}, words)
+
+	for _, command := range []string{`curl 'a`, `curl "a`, `curl $'a`, `curl a \`} {
+		_, err := splitCommand(command)
+		assert.ErrorIs(t, err, errIncompleteCommand, command)
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	dir := t.TempDir()
+	dataFile := filepath.Join(dir, "data.txt")
+	os.WriteFile(dataFile, []byte("a=1\nb=2\n"), 0644)
+
+	cases, err := ParseCurl(fmt.Sprintf(`
+	# copied from browser
+	curl 'https://example.com/api?x=1' -H 'Accept: application/json' \
+	  -H 'X-Empty;' -H 'Authorization:' --compressed -b 'sid=abc; lang=en' -sSL
+
+	curl example.com/login -u admin:secret -d name=a -d @%s --data-urlencode 'q=a b&c'
+	curl -XPUT '{{host}}/items/1' --json '{"a": 1}' -A ftab
+	curl -G http://example.com/search --data-urlencode "q=x y" -d n=10 -k
+	curl -I --url http://example.com --user 'bob:my secret'
+	curl http://example.com/digest -u 'bob:pw' --digest -X DELETE -e 'http://a.com;auto'
+	curl --limit-rate 100K --proxy-header 'X-Proxy: 1' -D - -C - http://example.com/slow
+	`, dataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, cases, 7)
+
+	req := cases[0].request
+	assert.Equal(t, "GET", string(req.Header.Method()))
+	assert.Equal(t, "https://example.com/api?x=1", string(req.RequestURI()))
+	assert.Equal(t, "application/json", string(req.Header.Peek("Accept")))
+	assert.Equal(t, "gzip, deflate", string(req.Header.Peek("Accept-Encoding")))
+	assert.Equal(t, "sid=abc; lang=en", string(req.Header.Peek("Cookie")))
+	assert.Nil(t, req.Header.Peek("Authorization"))
+	assert.NotNil(t, req.Header.Peek("X-Empty"))
+
+	req = cases[1].request
+	assert.Equal(t, "POST", string(req.Header.Method()))
+	assert.Equal(t, "http://example.com/login", string(req.RequestURI()))
+	assert.Equal(t, "Basic admin:secret", string(req.Header.Peek("Authorization")))
+	assert.Equal(t, "application/x-www-form-urlencoded", string(req.Header.ContentType()))
+	assert.Equal(t, "name=a&a=1b=2&q=a+b%26c", string(req.Body()))
+
+	req = cases[2].request
+	assert.Equal(t, "PUT", string(req.Header.Method()))
+	assert.Equal(t, "{{host}}/items/1", string(req.RequestURI()))
+	assert.Equal(t, "application/json", string(req.Header.ContentType()))
+	assert.Equal(t, "ftab", string(req.Header.UserAgent()))
+	assert.Equal(t, `{"a": 1}`, string(req.Body()))
+
+	req = cases[3].request
+	assert.Equal(t, "GET", string(req.Header.Method()))
+	assert.Equal(t, "http://example.com/search?q=x+y&n=10", string(req.RequestURI()))
+	assert.Empty(t, req.Body())
+
+	req = cases[4].request
+	assert.Equal(t, "HEAD", string(req.Header.Method()))
+	assert.Equal(t, basicAuth("bob", "my secret"), string(req.Header.Peek("Authorization")))
+
+	req = cases[5].request
+	assert.Equal(t, "DELETE", string(req.Header.Method()))
+	assert.Equal(t, "Digest bob pw", string(req.Header.Peek("Authorization")))
+	assert.Equal(t, "http://a.com", string(req.Header.Peek("Referer")))
+
+	// values of ignored options are not taken as url
+	req = cases[6].request
+	assert.Equal(t, "http://example.com/slow", string(req.RequestURI()))
+	assert.Nil(t, req.Header.Peek("X-Proxy"))
+
+	for command, message := range map[string]string{
+		`curl`:                           "curl: no url is given",
+		`curl a b`:                       "curl: only one url is supported",
+		`curl a -H`:                      "curl: option -H requires a value",
+		`curl a -H 'bad'`:                `curl: invalid header "bad"`,
+		`curl a -F bad`:                  `curl: invalid form "bad"`,
+		`curl a -d @missing.txt`:         "curl: open missing.txt: no such file or directory",
+		`curl a -d 'x`:                   "curl: incomplete command",
+		"GET http://example.com":         `curl: "GET http://example.com" is not a curl command`,
+		"curl a\ncurl b \\\n  -H 'a: b":  "curl: incomplete command",
+		`curl a --digest -u 'bob:pa ss'`: `curl: digest credential "bob" with whitespace is not supported`,
+	} {
+		_, err := ParseCurl(command)
+		if assert.Error(t, err, command) {
+			assert.Equal(t, message, err.Error(), command)
+		}
+	}
+}
+
+func TestParseCurlForm(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "avatar.png")
+	os.WriteFile(file, []byte("png"), 0644)
+
+	cases, err := ParseCurl(fmt.Sprintf(`curl http://example.com/upload -F name=bob -F 'avatar=@%s;type=image/png' --form-string 'note=@me'`, file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := cases[0].request
+	assert.Equal(t, "POST", string(req.Header.Method()))
+	assert.Equal(t, "multipart/form-data; boundary="+formBoundary, string(req.Header.ContentType()))
+
+	form, err := req.MultipartForm()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"bob"}, form.Value["name"])
+	assert.Equal(t, []string{"@me"}, form.Value["note"])
+	assert.Equal(t, "avatar.png", form.File["avatar"][0].Filename)
+	assert.Equal(t, "image/png", form.File["avatar"][0].Header.Get("Content-Type"))
+}
+
+func TestCaseWriteTo(t *testing.T) {
+	cases, err := ParseCurl(`curl -X POST http://example.com/a -H 'Content-Type: application/json' -H 'X-Token: {{token}}' -d '{"a": 1}'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases[0].Name = "create"
+
+	buff := bytes.NewBuffer(nil)
+	_, err = cases[0].WriteTo(buff)
+	assert.NoError(t, err)
+	assert.Equal(t, `# @name create
+POST http://example.com/a
+Content-Type: application/json
+X-Token: {{token}}
+
+{"a": 1}
+`, buff.String())
+
+	file, err := ParseBytes(buff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "create", file.Cases[0].Name)
+	assert.Equal(t, `{"a": 1}`, string(file.Cases[0].request.Body()))
+}
+
+func TestParseCurlCase(t *testing.T) {
+	content := fmt.Sprintf(`
+	@server = %s
+
+	# @name login
+	curl -X PUT '{{server}}login' \
+	  -H 'Echo-Token: abc' \
+	  --data-raw '{
+	    "a": "b"
+	  }'
+	X-Extra: 1
+	###
+
+	curl {{server}} -d 'a={{login.response.body.$.a}}'
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, file.Cases, 2)
+	assert.Equal(t, "login", file.Cases[0].Name)
+	assert.Equal(t, "PUT", string(file.Cases[0].request.Header.Method()))
+	assert.Equal(t, "1", string(file.Cases[0].request.Header.Peek("X-Extra")))
+
+	if err := file.Execute(&fasthttp.Client{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "abc", string(file.Cases[0].response.Header.Peek("Echo-Token")))
+	assert.Equal(t, "a=b", string(file.Cases[1].response.Body()))
+	file.Release()
+
+	_, err = ParseBytes([]byte("curl http://a.com -d 'x"))
+	assert.EqualError(t, err, "case 1: curl: incomplete command")
+	_, err = ParseBytes([]byte("curl"))
+	assert.EqualError(t, err, "case 1: curl: no url is given")
+}
@@ -0,0 +1,81 @@
+package httpfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Environment is the variables and oauth2 profiles of a environment, env file is like
+// REST Client environment settings, $shared variables are available in all environments
+//
+//	{
+//	    "$shared": {"version": "v1"},
+//	    "local": {"host": "http://127.0.0.1:8080"},
+//	    "$oauth2": {
+//	        "api": {"tokenUrl": "{{host}}/token", "clientId": "id", "clientSecret": "secret"}
+//	    }
+//	}
+type Environment struct {
+	Name      string                    // name of environment
+	Variables MapReplacer               // variables of environment
+	OAuth2    map[string]*OAuth2Profile // oauth2 profiles by name
+}
+
+// LoadEnvironment load environment by name from env file
+func LoadEnvironment(fileName, name string) (*Environment, error) {
+	content, err := os.ReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("read env file: %w", err)
+	}
+	return ParseEnvironment(content, name)
+}
+
+// ParseEnvironment parse environment by name from env file content
+func ParseEnvironment(content []byte, name string) (*Environment, error) {
+	var settings map[string]json.RawMessage
+	if err := json.Unmarshal(content, &settings); err != nil {
+		return nil, fmt.Errorf("parse env file: %w", err)
+	}
+
+	env := &Environment{
+		Name:      name,
+		Variables: make(MapReplacer),
+		OAuth2:    make(map[string]*OAuth2Profile),
+	}
+
+	for _, key := range []string{"$shared", name} {
+		raw, ok := settings[key]
+		if !ok {
+			if key == name && name != "" {
+				return nil, fmt.Errorf("environment %s not found", name)
+			}
+			continue
+		}
+		var vars map[string]string
+		if err := json.Unmarshal(raw, &vars); err != nil {
+			return nil, fmt.Errorf("parse environment %s: %w", key, err)
+		}
+		for k, v := range vars {
+			env.Variables[k] = v
+		}
+	}
+
+	if raw, ok := settings["$oauth2"]; ok {
+		if err := json.Unmarshal(raw, &env.OAuth2); err != nil {
+			return nil, fmt.Errorf("parse oauth2 profiles: %w", err)
+		}
+		for profileName, profile := range env.OAuth2 {
+			profile.expand(env.Variables)
+			profile.Name = profileName
+		}
+	}
+
+	return env, nil
+}
+
+// Get is required by Replacer interface
+func (env *Environment) Get(key string) (string, bool) {
+	val, ok := env.Variables[key]
+	return val, ok
+}
@@ -0,0 +1,145 @@
+package httpfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// expander replace {{...}} placeholders, placeholders can be nested like
+// {{$randomInt {{min}} {{max}}}}, inner ones are expanded first, value of variable
+// definition is expanded again, so @url = {{host}}/api works, filter before key
+// choose how value is inserted, {{json key}} insert json encoded value like "text"
+// or 10, {{raw key}} insert value as is
+type expander struct {
+	ve        Replacer
+	strict    bool                 // undefined variable is error, otherwise it's kept
+	stack     []string             // variables being expanded, for cycle detection
+	templates map[string]*template // compiled values of variables, nil is not cached
+	kept      int                  // placeholders kept, like signature functions
+	buf       []byte               // buffer of rendering request, reused by next one
+}
+
+// errKept mean placeholder is kept as is even if expander is strict
+var errKept = errors.New("placeholder is kept")
+
+var openTag = []byte("{{")
+
+// closeTag return position of }} matching {{ at start, -1 if not found
+func closeTag(text []byte, start int) int {
+	depth := 0
+	for i := start; i < len(text)-1; i++ {
+		if text[i] == '{' && text[i+1] == '{' {
+			depth++
+			i++
+			continue
+		}
+		if text[i] == '}' && text[i+1] == '}' {
+			// }}} closes by the last two if the first belongs to content like ${x},
+			// otherwise the last one is after placeholder like {"a":{{x}}}
+			if depth == 1 && i+2 < len(text) && text[i+2] == '}' &&
+				bytes.Count(text[start:i], []byte("{")) > bytes.Count(text[start:i], []byte("}"))+2 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return i
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+func (e *expander) expand(text []byte) ([]byte, error) {
+	if bytes.Index(text, openTag) < 0 {
+		return text, nil
+	}
+	return compile(text).render(e, make([]byte, 0, len(text)))
+}
+
+// value append value of placeholder key to out, nested placeholders in key are
+// expanded already
+func (e *expander) value(out []byte, key string) ([]byte, error) {
+	if filter, name, ok := strings.Cut(key, " "); ok && (filter == "json" || filter == "raw") {
+		return e.filter(out, filter, strings.TrimSpace(name))
+	}
+
+	val, ok := e.ve.Get(key)
+	if !ok {
+		name := key
+		if pos := strings.IndexAny(key, " \t"); pos > 0 {
+			name = key[:pos]
+		}
+		// signature functions are evaluated after request is expanded
+		if signatureFunctions[name] || !e.strict {
+			return out, errKept
+		}
+		return out, fmt.Errorf("undefined variable %s", key)
+	}
+
+	if !strings.Contains(val, "{{") || !isTemplate(e.ve, key) {
+		return append(out, val...), nil
+	}
+
+	for _, k := range e.stack {
+		if k == key {
+			return out, fmt.Errorf("variable cycle %s -> %s", strings.Join(e.stack, " -> "), key)
+		}
+	}
+	e.stack = append(e.stack, key)
+	defer func() {
+		e.stack = e.stack[:len(e.stack)-1]
+	}()
+
+	t := e.templates[val]
+	if t == nil {
+		t = compile([]byte(val))
+		if e.templates != nil {
+			e.templates[val] = t
+		}
+	}
+	return t.render(e, out)
+}
+
+// filter append value of key as filter given
+func (e *expander) filter(out []byte, filter, key string) ([]byte, error) {
+	if _, ok := e.ve.Get(key); !ok {
+		if !e.strict {
+			return out, errKept
+		}
+		return out, fmt.Errorf("undefined variable %s", key)
+	}
+
+	if filter == "raw" {
+		return e.value(out, key)
+	}
+	val, err := e.value(nil, key)
+	if err != nil {
+		return out, err
+	}
+	typed, _ := typedValue(e.ve, key)
+	if _, ok := typed.(string); ok {
+		typed = string(val)
+	}
+	return append(out, marshalJSON(typed)...), nil
+}
+
+// ExpandVariable replace variable placeholder to value, undefined variable is a error
+func ExpandVariable(text []byte, ve Replacer) ([]byte, error) {
+	e := &expander{ve: ve, strict: true}
+	return e.expand(text)
+}
+
+// ReplaceVariable replace variable placeholder to value, undefined variable is kept
+func ReplaceVariable(text []byte, ve Replacer) []byte {
+	e := &expander{ve: ve}
+	replaced, _ := e.expand(text)
+	return replaced
+}
+
+// ReplaceVariableString replace variable placeholder to value string
+func ReplaceVariableString(text string, ve Replacer) string {
+	return string(ReplaceVariable([]byte(text), ve))
+}
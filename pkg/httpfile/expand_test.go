@@ -0,0 +1,90 @@
+package httpfile
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestExpandVariable(t *testing.T) {
+	file, err := ParseBytes([]byte(`
+	@host = http://127.0.0.1
+	@url = {{host}}/api
+	@users = {{url}}/users
+	@min = 10
+	@max = 11
+	@a = {{b}}
+	@b = {{c}}
+	@c = {{a}}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	val, err := ExpandVariable([]byte("GET {{users}}/1"), file)
+	assert.NoError(t, err)
+	assert.Equal(t, "GET http://127.0.0.1/api/users/1", string(val))
+
+	val, err = ExpandVariable([]byte("{{$randomInt {{min}} 1}}"), file)
+	assert.NoError(t, err)
+	assert.Equal(t, "10", string(val))
+
+	val, err = ExpandVariable([]byte(`{{$md5 "${body}"}} {{$hmac sha256 {{min}} "${x}"}}`), file)
+	assert.NoError(t, err)
+	assert.Equal(t, `{{$md5 "${body}"}} {{$hmac sha256 10 "${x}"}}`, string(val))
+
+	val, err = ExpandVariable([]byte(`{"a":{{min}}}`), file)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":10}`, string(val))
+
+	val, err = ExpandVariable([]byte(`{"min": {{min}}, "max": {"v": {{json max}}}}`), file)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"min": 10, "max": {"v": "11"}}`, string(val))
+
+	_, err = ExpandVariable([]byte("{{a}}"), file)
+	assert.EqualError(t, err, "variable cycle a -> b -> c -> a")
+
+	_, err = ExpandVariable([]byte("{{url}}/{{id}}"), file)
+	assert.EqualError(t, err, "undefined variable id")
+
+	assert.Equal(t, "http://127.0.0.1/api/{{id}} {{ unclosed", ReplaceVariableString("{{url}}/{{id}} {{ unclosed", file))
+	assert.Equal(t, "{{a}}", ReplaceVariableString("{{a}}", file))
+}
+
+// literalReplacer is values from response
+type literalReplacer map[string]string
+
+func (lr literalReplacer) Get(key string) (string, bool) {
+	val, ok := lr[key]
+	return val, ok
+}
+
+func (lr literalReplacer) IsTemplate(key string) bool {
+	return false
+}
+
+func TestExpandLiteral(t *testing.T) {
+	ve := ListReplacer{literalReplacer{"resp": "{{b}}"}, MapReplacer{"var": "{{resp}}"}}
+	val, err := ExpandVariable([]byte("{{var}} {{resp}}"), ve)
+	assert.NoError(t, err)
+	assert.Equal(t, "{{b}} {{b}}", string(val))
+
+	content := fmt.Sprintf(`
+	POST %s
+	Content-Type: application/json
+
+	{"a": "{{undefined}}"}
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	assert.EqualError(t, err, "case 1: undefined variable undefined")
+	assert.Equal(t, 0, file.Cases[0].RespCode)
+}
@@ -0,0 +1,474 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// word is kept as is by shell
+var shellSafeTag, _ = regexp.Compile(`^[\w@%+=:,./-]+$`)
+
+// $.a[0]['b-c'] is also a javascript accessor
+var simpleJSONPathTag, _ = regexp.Compile(`^\$((\.[A-Za-z_$][\w$]*)|(\[\d+\])|(\['[^'\\]*'\]))*$`)
+
+// runs of non word and _ are _ in names of Postman variables
+var postmanNameTag, _ = regexp.Compile(`[\W_]+`)
+
+// postmanDynamic map system variables to Postman dynamic variables
+var postmanDynamic = map[string]string{
+	"$guid":              "$guid",
+	"$randomInt":         "$randomInt",
+	"$timestamp":         "$timestamp",
+	"$datetime iso8601":  "$isoTimestamp",
+	"$datetime rfc1123":  "$isoTimestamp",
+	"$localDatetime iso": "$isoTimestamp",
+}
+
+// WriteCurl write cases as cURL command lines, variables are replaced, request
+// variables are kept as they are only resolved by Execute, Authorization helpers
+// become options of curl
+func (f *HTTPFile) WriteCurl(w io.Writer) error {
+	d := f.Duplicate(false, true)
+	defer d.Release()
+
+	buff := bytes.NewBuffer(nil)
+	for i, c := range d.Cases {
+		if i > 0 {
+			buff.WriteString("\n")
+		}
+		if c.Name != "" {
+			buff.WriteString("# " + c.Name + "\n")
+		}
+		writeCurl(buff, c.request)
+	}
+	_, err := buff.WriteTo(w)
+	return err
+}
+
+// writeCurl write request as a curl command, each option is in a line
+func writeCurl(buff *bytes.Buffer, req *fasthttp.Request) {
+	method, body := string(req.Header.Method()), req.Body()
+	first := "curl "
+	switch {
+	case method == fasthttp.MethodHead && len(body) == 0:
+		first += "-I "
+	case method == fasthttp.MethodGet && len(body) == 0:
+	case method == fasthttp.MethodPost && len(body) > 0:
+	default:
+		first += "-X " + method + " "
+	}
+	lines := []string{first + shellQuote(string(req.RequestURI()))}
+
+	helper, authorized := parseAuthHelper(string(req.Header.Peek("Authorization")))
+	if authorized {
+		credential := shellQuote(helper.user + ":" + helper.password)
+		switch helper.scheme {
+		case "basic":
+			lines = append(lines, "-u "+credential)
+		case "digest":
+			lines = append(lines, "--digest -u "+credential)
+		case "aws":
+			provider := "aws:amz:" + helper.options["region"] + ":" + helper.options["service"]
+			lines = append(lines, "--aws-sigv4 "+shellQuote(strings.TrimRight(provider, ":")), "-u "+credential)
+			if token := helper.options["token"]; token != "" {
+				lines = append(lines, "-H "+shellQuote("X-Amz-Security-Token: "+token))
+			}
+		}
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if strings.EqualFold(name, fasthttp.HeaderContentLength) ||
+			(authorized && strings.EqualFold(name, fasthttp.HeaderAuthorization)) {
+			return
+		}
+		if len(value) == 0 {
+			lines = append(lines, "-H "+shellQuote(name+";"))
+			return
+		}
+		lines = append(lines, "-H "+shellQuote(name+": "+string(value)))
+	})
+	if len(body) > 0 {
+		lines = append(lines, "--data-raw "+shellQuote(string(body)))
+	}
+	buff.WriteString(strings.Join(lines, " \\\n  "))
+	buff.WriteString("\n")
+}
+
+// shellQuote quote word by single quotes if it's not safe in shell
+func shellQuote(word string) string {
+	if shellSafeTag.MatchString(word) {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// WriteHAR write cases as entries of HAR 1.2, variables are replaced and
+// Authorization helpers are applied, responses are empty with status 0 as cases
+// are not sent, entries are started one after another by think time of cases
+func (f *HTTPFile) WriteHAR(w io.Writer) error {
+	d := f.Duplicate(false, true)
+	defer d.Release()
+
+	h := har{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "ftab"},
+		Entries: make([]*harEntry, 0, len(d.Cases)),
+	}}
+	started := time.Now()
+	for _, c := range d.Cases {
+		c.authorize()
+		started = started.Add(c.think)
+		h.Log.Entries = append(h.Log.Entries, harExportEntry(c.request, started))
+	}
+	data, err := json.MarshalIndent(&h, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// harExportEntry convert request to HAR entry
+func harExportEntry(req *fasthttp.Request, started time.Time) *harEntry {
+	body := req.Body()
+	e := &harEntry{
+		StartedDateTime: started,
+		Request: harRequest{
+			Method:      string(req.Header.Method()),
+			URL:         string(req.RequestURI()),
+			HTTPVersion: "HTTP/1.1",
+			Cookies:     make([]harNameValue, 0),
+			Headers:     make([]harNameValue, 0),
+			QueryString: make([]harNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+		Response: harResponse{
+			Cookies:     make([]harNameValue, 0),
+			Headers:     make([]harNameValue, 0),
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+	}
+
+	req.Header.VisitAll(func(key, value []byte) {
+		if strings.EqualFold(string(key), fasthttp.HeaderContentLength) {
+			return
+		}
+		e.Request.Headers = append(e.Request.Headers, harNameValue{Name: string(key), Value: string(value)})
+	})
+	req.Header.VisitAllCookie(func(key, value []byte) {
+		e.Request.Cookies = append(e.Request.Cookies, harNameValue{Name: string(key), Value: string(value)})
+	})
+
+	uri := e.Request.URL
+	if pos := strings.IndexByte(uri, '#'); pos >= 0 {
+		uri = uri[:pos]
+	}
+	if pos := strings.IndexByte(uri, '?'); pos >= 0 {
+		args := fasthttp.AcquireArgs()
+		args.Parse(uri[pos+1:])
+		args.VisitAll(func(key, value []byte) {
+			e.Request.QueryString = append(e.Request.QueryString, harNameValue{Name: string(key), Value: string(value)})
+		})
+		fasthttp.ReleaseArgs(args)
+	}
+
+	if len(body) > 0 {
+		mimeType := string(req.Header.ContentType())
+		e.Request.PostData = &harPostData{MimeType: mimeType, Text: string(body)}
+		if strings.HasPrefix(mimeType, "application/x-www-form-urlencoded") {
+			args := fasthttp.AcquireArgs()
+			args.ParseBytes(body)
+			args.VisitAll(func(key, value []byte) {
+				e.Request.PostData.Params = append(e.Request.PostData.Params, harParam{Name: string(key), Value: string(value)})
+			})
+			fasthttp.ReleaseArgs(args)
+		} else if !utf8.Valid(body) {
+			e.Request.PostData.Text = ""
+		}
+	}
+	return e
+}
+
+// postmanExporter convert cases to items of Postman collection, request variables
+// become collection variables set by test scripts of cases they refer
+type postmanExporter struct {
+	variables map[string]bool     // collection variables set by scripts
+	scripts   map[string][]string // lines of test script by case name
+}
+
+// WritePostman write file as Postman collection v2.1 with name, variables of file are
+// collection variables, values of request variables are set by test scripts of cases
+// they refer, Authorization helpers become auth of requests, GraphQL cases are sent
+// by graphql body
+func (f *HTTPFile) WritePostman(w io.Writer, name string) error {
+	pe := &postmanExporter{
+		variables: make(map[string]bool),
+		scripts:   make(map[string][]string),
+	}
+	collection := postmanCollection{
+		Info: postmanInfo{Name: name, Schema: postmanSchema},
+		Item: make([]*postmanItem, 0, len(f.Cases)),
+	}
+
+	keys := make([]string, 0, len(f.Variables))
+	for key := range f.Variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		collection.Variable = append(collection.Variable, postmanKeyValue{Key: key, Value: pe.text(f.Variables[key])})
+	}
+
+	named := make(map[string]*postmanItem)
+	for _, c := range f.Cases {
+		item := pe.item(c)
+		if c.Name != "" {
+			named[c.Name] = item
+		}
+		collection.Item = append(collection.Item, item)
+	}
+	for _, c := range f.Cases {
+		if item := named[c.Name]; item != nil {
+			addTestScript(item, pe.scripts[c.Name])
+			delete(pe.scripts, c.Name)
+		}
+	}
+
+	data, err := json.MarshalIndent(&collection, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// item convert case to request item, variables extracted by case are set by test script
+func (pe *postmanExporter) item(c *Case) *postmanItem {
+	req := c.request
+	item := &postmanItem{
+		Name: c.Name,
+		Request: &postmanRequest{
+			Method: string(req.Header.Method()),
+			Header: make([]postmanKeyValue, 0),
+			URL:    postmanURL{Raw: pe.text(string(req.RequestURI()))},
+		},
+	}
+	if item.Name == "" {
+		item.Name = item.Request.Method + " " + item.Request.URL.Raw
+	}
+
+	helper, authorized := parseAuthHelper(string(req.Header.Peek("Authorization")))
+	if authorized {
+		item.Request.Auth = pe.auth(helper)
+	}
+	req.Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		if strings.EqualFold(name, fasthttp.HeaderContentLength) ||
+			(authorized && strings.EqualFold(name, fasthttp.HeaderAuthorization)) {
+			return
+		}
+		item.Request.Header = append(item.Request.Header, postmanKeyValue{Key: name, Value: pe.text(string(value))})
+	})
+	if body := req.Body(); len(body) > 0 {
+		item.Request.Body = pe.body(c, body)
+	}
+
+	lines := make([]string, 0, len(c.extractors))
+	for _, e := range c.extractors {
+		if expr, ok := postmanExtractor(e); ok {
+			lines = append(lines, fmt.Sprintf("pm.collectionVariables.set(%s, %s);", marshalJSON(e.name), expr))
+		}
+	}
+	addTestScript(item, lines)
+	return item
+}
+
+// addTestScript append lines to test script of item
+func addTestScript(item *postmanItem, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	for _, event := range item.Event {
+		if event.Listen == "test" {
+			event.Script.Exec = append(event.Script.Exec, lines...)
+			return
+		}
+	}
+	item.Event = append(item.Event, &postmanEvent{
+		Listen: "test",
+		Script: postmanScript{Type: "text/javascript", Exec: lines},
+	})
+}
+
+// body of request, query and variables of GraphQL case are sent by graphql body,
+// body is raw if variables are not json before replacing placeholders
+func (pe *postmanExporter) body(c *Case, body []byte) *postmanBody {
+	if c.isGraphQL() {
+		var payload struct {
+			Query     string          `json:"query"`
+			Variables json.RawMessage `json:"variables"`
+		}
+		if err := json.Unmarshal(body, &payload); err == nil {
+			graphql := &postmanGraphQL{Query: pe.text(payload.Query)}
+			if len(payload.Variables) > 0 {
+				graphql.Variables = pe.text(string(payload.Variables))
+			}
+			return &postmanBody{Mode: "graphql", GraphQL: graphql}
+		}
+	}
+
+	result := &postmanBody{Mode: "raw", Raw: pe.text(string(body))}
+	if strings.Contains(string(c.request.Header.ContentType()), "json") {
+		result.Options = &postmanBodyOptions{}
+		result.Options.Raw.Language = "json"
+	}
+	return result
+}
+
+// auth convert Authorization helper to auth of Postman
+func (pe *postmanExporter) auth(helper authHelper) *postmanAuth {
+	attribute := func(key, value string) postmanKeyValue {
+		return postmanKeyValue{Key: key, Value: pe.text(value), Type: "string"}
+	}
+	credential := []postmanKeyValue{attribute("username", helper.user), attribute("password", helper.password)}
+
+	switch helper.scheme {
+	case "basic":
+		return &postmanAuth{Type: "basic", Basic: credential}
+	case "digest":
+		return &postmanAuth{Type: "digest", Digest: credential}
+	}
+	auth := &postmanAuth{Type: "awsv4", AWSv4: []postmanKeyValue{
+		attribute("accessKey", helper.user),
+		attribute("secretKey", helper.password),
+	}}
+	for _, option := range []string{"token", "region", "service"} {
+		if value, ok := helper.options[option]; ok {
+			key := option
+			if option == "token" {
+				key = "sessionToken"
+			}
+			auth.AWSv4 = append(auth.AWSv4, attribute(key, value))
+		}
+	}
+	return auth
+}
+
+// text replace placeholders to Postman variables
+func (pe *postmanExporter) text(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	var buff strings.Builder
+	for _, seg := range compile([]byte(s)).segments {
+		if seg.inner == nil {
+			buff.Write(seg.text)
+			continue
+		}
+		buff.WriteString(pe.placeholder(strings.TrimSpace(string(seg.text))))
+	}
+	return buff.String()
+}
+
+// placeholder map placeholder to Postman variable, values of json filter are quoted
+// as variables are strings, request variable is a collection variable set by test
+// script of the case it refers, unknown system variable is kept
+func (pe *postmanExporter) placeholder(key string) string {
+	filter, name, ok := strings.Cut(key, " ")
+	if !ok || (filter != "json" && filter != "raw") {
+		filter, name = "", key
+	}
+	name = strings.TrimSpace(name)
+	if dynamic, ok := postmanDynamic[name]; ok {
+		return "{{" + dynamic + "}}"
+	}
+	if fun, env, ok := strings.Cut(name, " "); ok && (fun == "$processEnv" || fun == "$dotenv") {
+		name = strings.TrimPrefix(strings.TrimSpace(env), "%")
+	}
+
+	rv, ok := parseRequestVariable(name)
+	if !ok {
+		if filter == "json" {
+			return `"{{` + name + `}}"`
+		}
+		return "{{" + name + "}}"
+	}
+	expr, ok := postmanRequestValue(rv)
+	if !ok {
+		return "{{" + key + "}}"
+	}
+	// typed value of request variable is encoded by script
+	name = strings.Trim(postmanNameTag.ReplaceAllString(strings.Join([]string{rv.caseName, rv.kind, rv.part, rv.path}, "_"), "_"), "_")
+	name = strings.Replace(name, "_response_", "_", 1)
+	if filter == "json" {
+		name, expr = name+"_json", "JSON.stringify("+expr+")"
+	}
+	if !pe.variables[name] {
+		pe.variables[name] = true
+		pe.scripts[rv.caseName] = append(pe.scripts[rv.caseName], fmt.Sprintf("pm.collectionVariables.set(%s, %s);", marshalJSON(name), expr))
+	}
+	return "{{" + name + "}}"
+}
+
+// postmanRequestValue is javascript expression of request variable in test script,
+// XPath and JSONPath with filters are not supported
+func postmanRequestValue(rv requestVariable) (string, bool) {
+	if rv.part == "headers" {
+		return fmt.Sprintf("pm.%s.headers.get(%s)", rv.kind, marshalJSON(rv.path)), true
+	}
+
+	text, object := "pm.response.text()", "pm.response.json()"
+	if rv.kind == "request" {
+		text, object = "pm.request.body.raw", "JSON.parse(pm.request.body.raw)"
+	}
+	if rv.part == "data" {
+		object += ".data"
+	} else if rv.path == "*" {
+		return text, true
+	}
+	if strings.HasPrefix(rv.path, "/") {
+		return "", false
+	}
+	accessor, ok := javascriptAccessor(rv.path)
+	if !ok {
+		return "", false
+	}
+	return object + accessor, true
+}
+
+// postmanExtractor is javascript expression of extractor in test script
+func postmanExtractor(e *extractor) (string, bool) {
+	switch {
+	case e.re != nil:
+		return fmt.Sprintf("(pm.response.text().match(new RegExp(%s)) || [])[%d]", marshalJSON(e.re.String()), e.group), true
+	case e.path != nil:
+		accessor, ok := javascriptAccessor(e.query)
+		return "pm.response.json().data" + accessor, ok
+	}
+	expr := fmt.Sprintf("pm.response.text().split(%[1]s).slice(1).join(%[1]s)", marshalJSON(string(e.left)))
+	if len(e.right) > 0 {
+		expr += fmt.Sprintf(".split(%s)[0]", marshalJSON(string(e.right)))
+	}
+	return expr, true
+}
+
+// javascriptAccessor convert JSONPath selecting a single value to javascript accessor
+// like .a[0]['b-c']
+func javascriptAccessor(path string) (string, bool) {
+	path = normalizeJSONPath(path)
+	if !simpleJSONPathTag.MatchString(path) {
+		return "", false
+	}
+	return path[1:], true
+}
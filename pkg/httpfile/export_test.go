@@ -0,0 +1,144 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const exportContent = `
+@host = http://127.0.0.1:8080
+@user = admin
+
+# @name login
+POST {{host}}/login?next=/home&a=1
+Content-Type: application/x-www-form-urlencoded
+Authorization: Basic {{user}} it's
+
+name={{user}}&pass=x
+
+###
+# @name profile
+# @think 1s
+# @extract nick = regex "nick=(\w+)"
+PUT {{host}}/users/{{login.response.body.$.id}}
+Content-Type: application/json
+X-Token: {{login.response.headers.X-Token}}
+Authorization: AWS id key region:us-east-1 service:s3
+
+{"nick": {{json user}}, "xml": "{{login.response.body./a/b}}", "id": {{json login.response.body.id}}}
+
+###
+POST {{host}}/graphql
+X-Request-Type: GraphQL
+
+query user($id: ID) { user(id: $id) { name } }
+
+{"id": "{{$guid}}"}
+`
+
+func TestWriteCurl(t *testing.T) {
+	file, err := ParseBytes([]byte(exportContent), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buff := bytes.NewBuffer(nil)
+	assert.NoError(t, file.WriteCurl(buff))
+	assert.Contains(t, buff.String(), `# login
+curl 'http://127.0.0.1:8080/login?next=/home&a=1' \
+  -u 'admin:it'\''s' \
+  -H 'Content-Type: application/x-www-form-urlencoded' \
+  --data-raw 'name=admin&pass=x'
+`)
+	assert.Contains(t, buff.String(), `# profile
+curl -X PUT 'http://127.0.0.1:8080/users/{{login.response.body.$.id}}' \
+  --aws-sigv4 aws:amz:us-east-1:s3 \
+  -u id:key \
+  -H 'Content-Type: application/json' \
+  -H 'X-Token: {{login.response.headers.X-Token}}' \
+  --data-raw '{"nick": "admin", "xml": "{{login.response.body./a/b}}", "id": {{json login.response.body.id}}}'
+`)
+
+	cases, err := ParseCurl(buff.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, cases, 3)
+	assert.Equal(t, "Basic admin:it's", string(cases[0].request.Header.Peek("Authorization")))
+	assert.Equal(t, "PUT", string(cases[1].request.Header.Method()))
+	assert.Equal(t, string(file.Cases[2].request.Body()), string(cases[2].request.Body()))
+}
+
+func TestWriteHAR(t *testing.T) {
+	file, err := ParseBytes([]byte(exportContent), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buff := bytes.NewBuffer(nil)
+	assert.NoError(t, file.WriteHAR(buff))
+
+	var h har
+	if err := json.Unmarshal(buff.Bytes(), &h); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1.2", h.Log.Version)
+	assert.Len(t, h.Log.Entries, 3)
+
+	login := h.Log.Entries[0].Request
+	assert.Equal(t, "http://127.0.0.1:8080/login?next=/home&a=1", login.URL)
+	assert.Equal(t, []harNameValue{{Name: "next", Value: "/home"}, {Name: "a", Value: "1"}}, login.QueryString)
+	assert.Contains(t, login.Headers, harNameValue{Name: "Authorization", Value: basicAuth("admin", "it's")})
+	assert.Equal(t, []harParam{{Name: "name", Value: "admin"}, {Name: "pass", Value: "x"}}, login.PostData.Params)
+
+	profile := h.Log.Entries[1]
+	assert.Equal(t, "1s", profile.StartedDateTime.Sub(h.Log.Entries[0].StartedDateTime).String())
+	assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=id/\d{8}/us-east-1/s3/aws4_request`, profile.Request.Headers[len(profile.Request.Headers)-1].Value)
+	assert.Equal(t, 0, profile.Response.Status)
+	assert.Contains(t, buff.String(), `"cookies": []`)
+}
+
+func TestWritePostman(t *testing.T) {
+	file, err := ParseBytes([]byte(exportContent), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buff := bytes.NewBuffer(nil)
+	assert.NoError(t, file.WritePostman(buff, "cases"))
+
+	var collection postmanCollection
+	if err := json.Unmarshal(buff.Bytes(), &collection); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, postmanInfo{Name: "cases", Schema: postmanSchema}, collection.Info)
+	assert.Equal(t, []postmanKeyValue{{Key: "host", Value: "http://127.0.0.1:8080"}, {Key: "user", Value: "admin"}}, collection.Variable)
+	assert.Len(t, collection.Item, 3)
+
+	login := collection.Item[0]
+	assert.Equal(t, "{{host}}/login?next=/home&a=1", login.Request.URL.Raw)
+	assert.Equal(t, &postmanAuth{Type: "basic", Basic: []postmanKeyValue{
+		{Key: "username", Value: "{{user}}", Type: "string"},
+		{Key: "password", Value: "it's", Type: "string"},
+	}}, login.Request.Auth)
+	assert.Equal(t, []postmanKeyValue{{Key: "Content-Type", Value: "application/x-www-form-urlencoded"}}, login.Request.Header)
+	assert.Equal(t, postmanExec{
+		`pm.collectionVariables.set("login_body_id", pm.response.json().id);`,
+		`pm.collectionVariables.set("login_headers_X_Token", pm.response.headers.get("X-Token"));`,
+		`pm.collectionVariables.set("login_body_id_json", JSON.stringify(pm.response.json().id));`,
+	}, login.Event[0].Script.Exec)
+
+	profile := collection.Item[1]
+	assert.Equal(t, "{{host}}/users/{{login_body_id}}", profile.Request.URL.Raw)
+	assert.Equal(t, "{{login_headers_X_Token}}", profile.Request.Header[1].Value)
+	assert.Equal(t, "awsv4", profile.Request.Auth.Type)
+	assert.Equal(t, `{"nick": "{{user}}", "xml": "{{login.response.body./a/b}}", "id": {{login_body_id_json}}}`, profile.Request.Body.Raw)
+	assert.Equal(t, "json", profile.Request.Body.Options.Raw.Language)
+	assert.Equal(t, postmanExec{`pm.collectionVariables.set("nick", (pm.response.text().match(new RegExp("nick=(\\w+)")) || [])[1]);`}, profile.Event[0].Script.Exec)
+
+	graphql := collection.Item[2]
+	assert.Equal(t, "POST {{host}}/graphql", graphql.Name)
+	assert.Equal(t, "graphql", graphql.Request.Body.Mode)
+	assert.Equal(t, "query user($id: ID) { user(id: $id) { name } }", graphql.Request.Body.GraphQL.Query)
+	assert.Equal(t, `{"id": "{{$guid}}"}`, graphql.Request.Body.GraphQL.Variables)
+}
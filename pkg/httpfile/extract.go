@@ -0,0 +1,146 @@
+package httpfile
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/fantai/ftab/pkg/jsonpath"
+)
+
+// extractor extract a variable from response body of case for later cases, by
+//
+//	# @extract name = regex "pattern" [group]
+//	# @extract name = boundary "left" "right"
+//	# @extract name = data JSONPath
+type extractor struct {
+	name  string
+	re    *regexp.Regexp // regex extractor
+	group int            // sub match of regex, default is 1 if pattern has group, otherwise 0
+	left  []byte         // left boundary
+	right []byte         // right boundary
+	path  *jsonpath.Path // JSONPath selecting from data of GraphQL response
+	query string         // source of path
+}
+
+// parseExtractor parse value of # @extract directive
+func parseExtractor(value string) (*extractor, error) {
+	name, def, ok := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if !ok || len(name) == 0 {
+		return nil, fmt.Errorf("extract %s: name = kind args is expected", value)
+	}
+
+	args := splitArgs(def)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("extract %s: kind is expected", name)
+	}
+
+	e := &extractor{name: name}
+	switch args[0].text {
+	case "regex":
+		if len(args) < 2 || len(args) > 3 {
+			return nil, fmt.Errorf("extract %s: regex \"pattern\" [group] is expected", name)
+		}
+		re, err := regexp.Compile(args[1].text)
+		if err != nil {
+			return nil, fmt.Errorf("extract %s: %w", name, err)
+		}
+		e.re = re
+		if re.NumSubexp() > 0 {
+			e.group = 1
+		}
+		if len(args) == 3 {
+			group, err := strconv.Atoi(args[2].text)
+			if err != nil || group < 0 || group > re.NumSubexp() {
+				return nil, fmt.Errorf("extract %s: invalid group %s", name, args[2].text)
+			}
+			e.group = group
+		}
+	case "boundary":
+		if len(args) != 3 || len(args[1].text) == 0 {
+			return nil, fmt.Errorf("extract %s: boundary \"left\" \"right\" is expected", name)
+		}
+		e.left, e.right = []byte(args[1].text), []byte(args[2].text)
+	case "data":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("extract %s: data JSONPath is expected", name)
+		}
+		p, err := (*pathCache)(nil).compile(args[1].text)
+		if err != nil {
+			return nil, fmt.Errorf("extract %s: %w", name, err)
+		}
+		e.path, e.query = p, args[1].text
+	default:
+		return nil, fmt.Errorf("extract %s: unknown kind %s", name, args[0].text)
+	}
+	return e, nil
+}
+
+// extract value from text, empty right boundary extract to the end
+func (e *extractor) extract(text []byte) (string, bool) {
+	if e.re != nil {
+		groups := e.re.FindSubmatch(text)
+		if groups == nil {
+			return "", false
+		}
+		return string(groups[e.group]), true
+	}
+
+	start := bytes.Index(text, e.left)
+	if start < 0 {
+		return "", false
+	}
+	text = text[start+len(e.left):]
+	if len(e.right) == 0 {
+		return string(text), true
+	}
+	end := bytes.Index(text, e.right)
+	if end < 0 {
+		return "", false
+	}
+	return string(text[:end]), true
+}
+
+// extractData select value from data of GraphQL response
+func (e *extractor) extractData(c *Case) (string, bool) {
+	body, ok := c.jsonBody("response")
+	if !ok {
+		return "", false
+	}
+	obj, _ := body.(map[string]interface{})
+	values := e.path.Query(obj["data"])
+	switch len(values) {
+	case 0:
+		return "", false
+	case 1:
+		return jsonValueString(values[0]), true
+	}
+	return jsonValueString(values), true
+}
+
+// extract variables from response of case, variable not found is undefined,
+// so later case refer it is failed
+func (f *HTTPFile) extract(c *Case) {
+	if len(c.extractors) == 0 {
+		return
+	}
+	body := c.body("response")
+	for _, e := range c.extractors {
+		var val string
+		var ok bool
+		if e.path != nil {
+			val, ok = e.extractData(c)
+		} else {
+			val, ok = e.extract(body)
+		}
+		if ok {
+			if f.extracted == nil {
+				f.extracted = make(map[string]string)
+			}
+			f.extracted[e.name] = val
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package httpfile
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func formServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/form":
+			w.Header().Set("Content-Type", "text/html")
+			w.Write([]byte(`<form><input type="hidden" name="csrf" value="c-123"/><span id="user">Alice</span></form>`))
+		default:
+			w.Write([]byte(r.Header.Get("X-CSRF") + "|" + r.Header.Get("X-User") + "|" + r.Header.Get("X-Tag")))
+		}
+	}))
+}
+
+func TestParseExtractor(t *testing.T) {
+	e, err := parseExtractor(`csrf = regex "name=\"csrf\" value=\"([^\"]+)\""`)
+	assert.NoError(t, err)
+	assert.Equal(t, "csrf", e.name)
+	assert.Equal(t, 1, e.group)
+
+	e, err = parseExtractor(`tag = regex '<(\w+)>' 0`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, e.group)
+
+	e, err = parseExtractor(`user = boundary '<span id="user">' "</span>"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `<span id="user">`, string(e.left))
+	assert.Equal(t, "</span>", string(e.right))
+
+	for _, value := range []string{
+		`csrf`,
+		`= regex "a"`,
+		`csrf = xpath "a"`,
+		`csrf = regex "("`,
+		`csrf = regex "(a)" 2`,
+		`csrf = boundary "a"`,
+		`id = data`,
+		`id = data "$["`,
+	} {
+		_, err := parseExtractor(value)
+		assert.Error(t, err, value)
+	}
+
+	e, err = parseExtractor(`id = data user.id`)
+	assert.NoError(t, err)
+	assert.NotNil(t, e.path)
+
+	_, err = ParseBytes([]byte("# @extract csrf = regex \"(\"\nGET http://localhost/"))
+	assert.Error(t, err)
+}
+
+func TestExtract(t *testing.T) {
+	server := formServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	@csrf = none
+
+	# @name form
+	# @extract csrf = regex "name=\"csrf\" value=\"([^\"]+)\""
+	# @extract user = boundary '<span id="user">' "</span>"
+	# @extract tag = regex '<(\w+)' 0
+	# @extract missing = boundary "<table>" "</table>"
+	GET %[1]s/form
+
+	###
+	# @name submit
+	GET %[1]s/submit
+	X-CSRF: {{csrf}}
+	X-User: {{user}}
+	X-Tag: {{tag}}
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	w := file.Duplicate(false, true)
+	w.AutoClean = false
+	defer w.Release()
+	err = w.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "c-123|Alice|<form", string(w.Cases[1].response.Body()))
+	_, ok := w.Get("missing")
+	assert.False(t, ok)
+
+	// extracted variables belong to the iteration
+	val, _ := file.Get("csrf")
+	assert.Equal(t, "none", val)
+}
@@ -9,6 +9,9 @@ import (
 	"strconv"
 	"sync"
 	"time"
+
+	"github.com/fantai/ftab/pkg/mock"
+	"github.com/fantai/ftab/pkg/mock/helper"
 )
 
 func init() {
@@ -171,6 +174,20 @@ func funRandomInt(args []string) string {
 	return fmt.Sprintf("%d", n)
 }
 
+var mockLetters = []rune("abcdefghijklmnopqrstuvwxyz")
+
+// funMock is {{$mock name}}, value is mocked by words in name like email, mobile and name,
+// other names give name-xxxxxx
+func funMock(args []string) string {
+	if len(args) < 2 {
+		return helper.RandString(mockLetters, 6)
+	}
+	if value, ok := mock.Hint(args[1]); ok {
+		return value
+	}
+	return args[1] + "-" + helper.RandString(mockLetters, 6)
+}
+
 var fileListCache = &sync.Map{}
 
 func funRandomFromFile(args []string) string {
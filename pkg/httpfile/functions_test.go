@@ -12,12 +12,29 @@ func TestTimeLayout(t *testing.T) {
 	assert.Equal(t, "2006-01-02T03:04:05.000 -0700", layoutFromTimeFormat("YYYY-MM-DDTHH:mm:ss.SSS ZZ"))
 }
 
+func TestMock(t *testing.T) {
+	assert.Contains(t, funMock([]string{"$mock", "email"}), "@")
+	assert.Regexp(t, `^text-[a-z]{6}$`, funMock([]string{"$mock", "text"}))
+	assert.Regexp(t, `^[a-z]{6}$`, funMock([]string{"$mock"}))
+}
+
 func TestJSONPath(t *testing.T) {
 	text := `
 	{
-		"a": "b"
+		"a": "b",
+		"items": [{"id": 1, "n": 1.5}, {"id": 2, "n": 1e6}]
 	}
 	`
 
 	assert.Equal(t, "b", JSONPathGet(text, "$.a"))
+	assert.Equal(t, "b", JSONPathGet(text, ".a"))
+	assert.Equal(t, "[1,2]", JSONPathGet(text, "$.items[*].id"))
+	assert.Equal(t, "1000000", JSONPathGet(text, "$.items[?@.id > 1].n"))
+	assert.Equal(t, "", JSONPathGet(text, "$.items[?"))
+
+	cache := &pathCache{}
+	p1, err := cache.compile("$.items[0].n")
+	assert.NoError(t, err)
+	p2, _ := cache.compile("$.items[0].n")
+	assert.Same(t, p1, p2)
 }
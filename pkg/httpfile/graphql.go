@@ -0,0 +1,212 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fantai/ftab/pkg/gql"
+)
+
+// GraphQLRequestType is value of X-Request-Type header of GraphQL case, like REST Client,
+// body of case is a query followed by an empty line and a variables json
+const GraphQLRequestType = "GraphQL"
+
+// requestTypeHeader mark case as GraphQL, it's not sent
+const requestTypeHeader = "X-Request-Type"
+
+// isGraphQL check case is marked as GraphQL by X-Request-Type header
+func (c *Case) isGraphQL() bool {
+	return c.graphql || strings.EqualFold(string(c.request.Header.Peek(requestTypeHeader)), GraphQLRequestType)
+}
+
+// splitGraphQL split body to query and variables, variables is the json object after
+// last empty line, empty if not given, so shorthand query like { a } isn't taken as it
+func splitGraphQL(body string) (string, string) {
+	lines := strings.Split(body, "\n")
+	for i := len(lines) - 1; i > 0; i-- {
+		if len(strings.TrimSpace(lines[i])) > 0 {
+			continue
+		}
+		query := strings.TrimSpace(strings.Join(lines[:i], "\n"))
+		variables := strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+		if len(query) > 0 && strings.HasPrefix(variables, "{") && isJSONObject(variables) {
+			return query, variables
+		}
+	}
+	return strings.TrimSpace(body), ""
+}
+
+// isJSONObject check text is a json object with placeholders replaced by 0, placeholders
+// may be in strings or values
+func isJSONObject(text string) bool {
+	var buf bytes.Buffer
+	for _, seg := range compile([]byte(text)).segments {
+		if seg.inner != nil {
+			buf.WriteString("0")
+		} else {
+			buf.Write(seg.text)
+		}
+	}
+	var obj map[string]interface{}
+	return json.Unmarshal(buf.Bytes(), &obj) == nil
+}
+
+// parseGraphQL parse query with placeholders replaced by gql.Placeholder, so syntax errors
+// are found before execution, query is validated if schema is given
+func parseGraphQL(query string, schema *gql.Schema) (*gql.Document, error) {
+	var buf bytes.Buffer
+	for _, seg := range compile([]byte(query)).segments {
+		if seg.inner != nil {
+			buf.WriteString(gql.Placeholder)
+		} else {
+			buf.Write(seg.text)
+		}
+	}
+	doc, err := gql.Parse(buf.String())
+	if err != nil {
+		return nil, fmt.Errorf("graphql: %w", err)
+	}
+	if len(doc.Operations) == 0 {
+		return nil, errors.New("graphql: document has no operation")
+	}
+	if schema != nil {
+		if err := gql.Validate(schema, doc); err != nil {
+			return nil, fmt.Errorf("graphql: %w", err)
+		}
+	}
+	return doc, nil
+}
+
+// wrapGraphQL wrap query and variables in body to standard GraphQL payload,
+// {"query": "...", "operationName": "...", "variables": {...}}, the returned body
+// template escape values expanded in query as json string, variables is kept as is
+// so placeholders in it are expanded like other json body
+func (c *Case) wrapGraphQL(schema *gql.Schema) (*template, error) {
+	c.graphql = true
+	c.request.Header.Del(requestTypeHeader)
+	if len(c.request.Header.ContentType()) == 0 {
+		c.request.Header.SetContentType("application/json")
+	}
+
+	query, variables := splitGraphQL(string(c.request.Body()))
+	doc, err := parseGraphQL(query, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	body := &template{}
+	body.segments = append(body.segments, segment{text: []byte(`{"query":"`)})
+	for _, seg := range compile([]byte(query)).segments {
+		if seg.inner == nil {
+			seg.text = appendJSONEscaped(nil, seg.text)
+		} else {
+			seg.escape = true
+			body.dynamic = true
+		}
+		body.segments = append(body.segments, seg)
+	}
+	tail := []byte(`"`)
+	// name of the first operation
+	if name := doc.Operations[0].Name; name != "" && !strings.Contains(name, gql.Placeholder) {
+		tail = append(append(tail, `,"operationName":`...), marshalJSON(name)...)
+	}
+	if len(variables) > 0 {
+		tail = append(tail, `,"variables":`...)
+		vars := compile([]byte(variables))
+		body.segments = append(body.segments, segment{text: tail})
+		body.segments = append(body.segments, vars.segments...)
+		body.dynamic = body.dynamic || vars.dynamic
+		tail = nil
+	}
+	body.segments = append(body.segments, segment{text: append(tail, '}')})
+
+	// placeholders are kept in request body until it's rendered
+	buf := bytes.NewBuffer(nil)
+	for _, seg := range body.segments {
+		if seg.inner == nil {
+			buf.Write(seg.text)
+			continue
+		}
+		placeholder := append(append([]byte("{{"), seg.text...), "}}"...)
+		if seg.escape {
+			placeholder = appendJSONEscaped(nil, placeholder)
+		}
+		buf.Write(placeholder)
+	}
+	c.request.SetBody(buf.Bytes())
+	return body, nil
+}
+
+// GraphQLError is errors in response of GraphQL case, a GraphQL server response errors
+// with status 200, so the case is failed by them unless errors are allowed
+type GraphQLError struct {
+	Case     string   // name of case
+	Messages []string // message of each error
+	Classes  []string // class of each error by extension code and path, like NOT_FOUND at user.posts, or unclassified
+}
+
+func (e *GraphQLError) Error() string {
+	return fmt.Sprintf("case %s: graphql errors: %s", e.Case, strings.Join(e.Messages, "; "))
+}
+
+// unclassifiedGraphQLError is class of error without extensions.code and path
+const unclassifiedGraphQLError = "unclassified"
+
+// checkGraphQL check errors of GraphQL response, response not in json is not checked
+func (c *Case) checkGraphQL(index int) error {
+	body, ok := c.jsonBody("response")
+	if !ok {
+		return nil
+	}
+	obj, _ := body.(map[string]interface{})
+	errs, _ := obj["errors"].([]interface{})
+	if len(errs) == 0 {
+		return nil
+	}
+
+	e := &GraphQLError{Case: c.label(index)}
+	for _, item := range errs {
+		entry, _ := item.(map[string]interface{})
+		message, _ := entry["message"].(string)
+		class := classifyGraphQLError(entry)
+		if class == "" {
+			// message has ids or values usually, it would make too many classes
+			class = unclassifiedGraphQLError
+		}
+		e.Messages = append(e.Messages, message)
+		e.Classes = append(e.Classes, class)
+	}
+	return e
+}
+
+// classifyGraphQLError join extensions.code and path of error, empty if both are missing
+func classifyGraphQLError(entry map[string]interface{}) string {
+	var code, path string
+	if ext, ok := entry["extensions"].(map[string]interface{}); ok && ext["code"] != nil {
+		code = jsonValueString(ext["code"])
+	}
+	if items, ok := entry["path"].([]interface{}); ok {
+		var sb strings.Builder
+		for _, item := range items {
+			if index, ok := item.(float64); ok {
+				sb.WriteString("[" + jsonValueString(index) + "]")
+				continue
+			}
+			if sb.Len() > 0 {
+				sb.WriteString(".")
+			}
+			sb.WriteString(jsonValueString(item))
+		}
+		path = sb.String()
+	}
+	switch {
+	case code != "" && path != "":
+		return code + " at " + path
+	case path != "":
+		return "at " + path
+	}
+	return code
+}
@@ -0,0 +1,301 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fantai/ftab/pkg/gql"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSplitGraphQL(t *testing.T) {
+	query, variables := splitGraphQL("query {\n\n  a\n}\n\n{\n  \"x\": 1,\n\n  \"y\": 2\n}\n")
+	assert.Equal(t, "query {\n\n  a\n}", query)
+	assert.Equal(t, "{\n  \"x\": 1,\n\n  \"y\": 2\n}", variables)
+
+	query, variables = splitGraphQL("\n{\n  a\n}\n")
+	assert.Equal(t, "{\n  a\n}", query)
+	assert.Equal(t, "", variables)
+
+	// shorthand query after fragment isn't variables, placeholders in variables are allowed
+	query, variables = splitGraphQL("fragment f on User { id }\n\n{ user { ...f } }\n")
+	assert.Equal(t, "fragment f on User { id }\n\n{ user { ...f } }", query)
+	assert.Equal(t, "", variables)
+	query, variables = splitGraphQL("{ a }\n\n{\"id\": {{id}}, \"name\": \"{{name}}\"}")
+	assert.Equal(t, "{ a }", query)
+	assert.Equal(t, `{"id": {{id}}, "name": "{{name}}"}`, variables)
+}
+
+func TestGraphQL(t *testing.T) {
+	content := fmt.Sprintf(`
+	@owner = Huachao
+	@first = 10
+
+	# @name repo
+	POST %[1]s
+	X-REQUEST-TYPE: GraphQL
+
+	query Repo($name: String!, $owner: String!) {
+	  repository(name: $name, owner: $owner) {
+	    name
+	    issues(first: {{first}}) { totalCount }
+	  }
+	}
+
+	{
+	  "name": "vscode-restclient",
+	  "owner": "{{owner}}"
+	}
+
+	###
+	# @name anonymous
+	POST %[1]s
+	x-request-type: graphql
+	Content-Type: application/graphql+json
+
+	{
+	  viewer { login }
+	}
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	assert.True(t, file.Cases[0].graphql)
+	assert.Nil(t, file.Cases[0].request.Header.Peek("X-Request-Type"))
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	err = json.Unmarshal(file.Cases[0].response.Body(), &payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "Repo", payload.OperationName)
+	assert.Contains(t, payload.Query, "issues(first: 10) { totalCount }")
+	assert.Contains(t, payload.Query, "\n")
+	assert.Equal(t, map[string]interface{}{"name": "vscode-restclient", "owner": "Huachao"}, payload.Variables)
+	assert.Equal(t, "application/json", string(file.Cases[0].request.Header.ContentType()))
+
+	assert.JSONEq(t, `{"query": "{\n\t  viewer { login }\n\t}"}`, string(file.Cases[1].response.Body()))
+	assert.Equal(t, "application/graphql+json", string(file.Cases[1].request.Header.ContentType()))
+
+	val, ok := file.Get("repo.request.body.$.variables.owner")
+	assert.True(t, ok)
+	assert.Equal(t, "Huachao", val)
+}
+
+func TestGraphQLEscape(t *testing.T) {
+	content := fmt.Sprintf(`
+	@name = say "hi"\path
+	@id = 7
+
+	POST %s
+	X-Request-Type: GraphQL
+
+	query {
+	  search(text: "{{name}}", name: {{json name}}, id: {{id}}) { id }
+	}
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payload struct {
+		Query string `json:"query"`
+	}
+	assert.NoError(t, json.Unmarshal(file.Cases[0].response.Body(), &payload))
+	assert.Contains(t, payload.Query, `search(text: "say "hi"\path", name: "say \"hi\"\\path", id: 7)`)
+}
+
+func TestGraphQLSyntax(t *testing.T) {
+	_, err := ParseBytes([]byte(`
+	POST http://127.0.0.1/graphql
+	X-REQUEST-TYPE: GraphQL
+
+	query {{op}} {
+	  user(id: {{id}}, name: "{{name}}") { {{field}} }
+	}
+
+	###
+	POST http://127.0.0.1/graphql
+	X-REQUEST-TYPE: GraphQL
+
+	query Users {
+	  users(first: 10 { id }
+	}
+	`))
+	assert.EqualError(t, err, `case 2: graphql: 2:20: expected Name, found Punctuator "{"`)
+
+	_, err = ParseBytes([]byte(`
+	POST http://127.0.0.1/graphql
+	X-REQUEST-TYPE: GraphQL
+
+	fragment f on User { id }
+	`))
+	assert.EqualError(t, err, "case 1: graphql: document has no operation")
+
+	file, err := ParseBytes([]byte(`
+	POST http://127.0.0.1/graphql
+	X-REQUEST-TYPE: GraphQL
+
+	fragment f on User { id }
+
+	{ user { ...f } }
+	`))
+	if assert.NoError(t, err) {
+		assert.Equal(t, `{"query":"fragment f on User { id }\n\n\t{ user { ...f } }"}`, string(file.Cases[0].request.Body()))
+	}
+}
+
+func TestGraphQLSchema(t *testing.T) {
+	schema := gql.MustParseSchema(`
+	type Query { user(id: ID!): User }
+	type User { id: ID! name: String }
+	`)
+	content := `
+	POST http://127.0.0.1/graphql
+	X-REQUEST-TYPE: GraphQL
+
+	query User($id: ID!) {
+	  user(id: $id) { id {{field}} }
+	}
+
+	{"id": "{{id}}"}
+	`
+
+	file, err := ParseBytes([]byte(content), WithGraphQLSchema(schema))
+	assert.NoError(t, err)
+	assert.Equal(t, schema, file.Schema)
+	file.Release()
+
+	_, err = ParseBytes([]byte(strings.Replace(content, "id {{field}}", "id email", 1)), WithGraphQLSchema(schema))
+	assert.EqualError(t, err, "case 1: graphql: 2:23: field email is not defined on type User")
+}
+
+func graphQLServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/ok":
+			w.Write([]byte(`{"data": {"user": {"id": "u1", "posts": [{"id": 1}, {"id": 2}]}}}`))
+		case "/errors":
+			w.Write([]byte(`{"data": {"user": null}, "errors": [
+				{"message": "user not found", "path": ["user", "posts", 0, "author"], "extensions": {"code": "NOT_FOUND"}},
+				{"message": "forbidden", "extensions": {"code": "FORBIDDEN"}},
+				{"message": "bad", "path": ["user"]},
+				{"message": "internal"}
+			]}`))
+		case "/invalid":
+			// GraphQL over HTTP response invalid request with 4xx
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"errors": [{"message": "variable id 7 is invalid", "extensions": {"code": "BAD_USER_INPUT"}}]}`))
+		default:
+			w.Write([]byte(r.Header.Get("X-User") + "|" + r.Header.Get("X-Post")))
+		}
+	}))
+}
+
+func TestGraphQLResponse(t *testing.T) {
+	server := graphQLServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @name user
+	# @extract posts = data user.posts[*].id
+	POST %[1]s/ok
+	X-REQUEST-TYPE: GraphQL
+
+	{ user { id posts { id } } }
+
+	###
+	# @name check
+	GET %[1]s/check
+	X-User: {{user.response.data.user.id}}
+	X-Post: {{posts}}
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+	err = file.Execute(&fasthttp.Client{})
+	assert.NoError(t, err)
+	assert.Equal(t, "u1|[1,2]", string(file.Cases[1].response.Body()))
+
+	content = fmt.Sprintf(`
+	# @name user
+	POST %[1]s/errors
+	X-REQUEST-TYPE: GraphQL
+
+	{ user { id posts { author } } }
+	`, server.URL)
+
+	file, err = ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = file.Execute(&fasthttp.Client{})
+	var gqlErr *GraphQLError
+	if assert.ErrorAs(t, err, &gqlErr) {
+		assert.Equal(t, "user", gqlErr.Case)
+		assert.Equal(t, []string{"NOT_FOUND at user.posts[0].author", "FORBIDDEN", "at user", "unclassified"}, gqlErr.Classes)
+		assert.Equal(t, "case user: graphql errors: user not found; forbidden; bad; internal", err.Error())
+	}
+
+	file, err = ParseBytes([]byte(content), AllowGraphQLErrors)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, file.Execute(&fasthttp.Client{}))
+
+	file, err = ParseBytes([]byte("# @allow-graphql-errors\n" + content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, file.Execute(&fasthttp.Client{}))
+
+	file, err = ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := ReportStat(Bench(file, 2, 4, 0))
+	assert.Equal(t, 4, report.Failed)
+	assert.Equal(t, map[string]int{"NOT_FOUND at user.posts[0].author": 4, "FORBIDDEN": 4, "at user": 4, "unclassified": 4},
+		report.GraphQLErrors)
+
+	buf := bytes.NewBuffer(nil)
+	HumanOutput(&report, buf)
+	assert.Contains(t, buf.String(), "GraphQL Errors\nFORBIDDEN           : 4\n")
+
+	invalid, err := ParseBytes([]byte(strings.Replace(content, "/errors", "/invalid", 1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report = ReportStat(Bench(invalid, 1, 2, 0))
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, map[string]int{"BAD_USER_INPUT": 2}, report.GraphQLErrors)
+}
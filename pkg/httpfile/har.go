@@ -0,0 +1,406 @@
+package httpfile
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// har is HTTP Archive 1.2, only fields used by ftab are declared
+type har struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string     `json:"mimeType"`
+	Text     string     `json:"text"`
+	Params   []harParam `json:"params,omitempty"`
+}
+
+type harParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// DefaultHARExclude match url of static assets, they are skipped by HAR import
+var DefaultHARExclude, _ = regexp.Compile(`(?i)\.(js|mjs|css|png|jpe?g|gif|svg|ico|webp|bmp|woff2?|ttf|otf|eot|map|mp4|webm|mp3|wav)([?#]|$)`)
+
+// DefaultMinThinkTime is the shortest think time kept by HAR import
+const DefaultMinThinkTime = 100 * time.Millisecond
+
+// minReusedLength is the shortest response value detected as reused by later requests
+const minReusedLength = 8
+
+// HAROptions is options of HAR import
+type HAROptions struct {
+	Exclude      *regexp.Regexp // requests with matched url are skipped, nil is not skip
+	MinThinkTime time.Duration  // shorter think time between requests is ignored
+}
+
+// harStep is a request imported from HAR, response is the final one after redirects
+type harStep struct {
+	entry    *harEntry
+	response *harResponse
+	end      time.Time
+}
+
+// harImporter convert steps to cases, values of responses are reused by later requests
+// as request variables
+type harImporter struct {
+	host   string
+	names  map[string]int
+	sent   strings.Builder   // text of imported requests
+	values map[string]string // value in response to request variable refer it
+}
+
+// ImportHAR convert a HAR to http file, requests are kept in order with think time between
+// them, the most used origin is variable host, values of responses which are used by later
+// requests are replaced by request variables, redirects are followed by cases
+func ImportHAR(data []byte, opts HAROptions) (*HTTPFile, error) {
+	var h har
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("har: %w", err)
+	}
+
+	file := &HTTPFile{
+		Variables:    make(map[string]string),
+		Cases:        make([]*Case, 0),
+		MaxRedirects: DefaultMaxRedirects,
+	}
+	steps := harSteps(h.Log.Entries, opts.Exclude)
+	im := &harImporter{
+		host:   harOrigin(steps),
+		names:  make(map[string]int),
+		values: make(map[string]string),
+	}
+	if im.host != "" {
+		file.Variables["host"] = im.host
+	}
+
+	for i, step := range steps {
+		c := newCase()
+		c.Name = im.name(step.entry.Request.URL)
+		if i > 0 {
+			think := step.entry.StartedDateTime.Sub(steps[i-1].end)
+			if think >= opts.MinThinkTime && think > 0 {
+				c.think = think.Round(time.Millisecond)
+			}
+		}
+		im.request(c.request, &step.entry.Request)
+		im.record(c.Name, step.response)
+		file.Cases = append(file.Cases, c)
+	}
+	return file, nil
+}
+
+// harSteps sort entries by start time and skip excluded, incomplete and non http requests,
+// request redirected to is merged to the redirecting one
+func harSteps(entries []*harEntry, exclude *regexp.Regexp) []*harStep {
+	sorted := append([]*harEntry{}, entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].StartedDateTime.Before(sorted[j].StartedDateTime)
+	})
+
+	var steps []*harStep
+	redirected := make(map[string]*harStep)
+	for _, e := range sorted {
+		u, err := url.Parse(e.Request.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || e.Response.Status == 0 {
+			continue
+		}
+		if exclude != nil && exclude.MatchString(e.Request.URL) {
+			continue
+		}
+
+		end := e.StartedDateTime.Add(time.Duration(e.Time * float64(time.Millisecond)))
+		step, ok := redirected[e.Request.URL]
+		if ok {
+			delete(redirected, e.Request.URL)
+			step.response, step.end = &e.Response, end
+		} else {
+			step = &harStep{entry: e, response: &e.Response, end: end}
+			steps = append(steps, step)
+		}
+		if e.Response.RedirectURL != "" {
+			if next, err := u.Parse(e.Response.RedirectURL); err == nil {
+				redirected[next.String()] = step
+			}
+		}
+	}
+	return steps
+}
+
+// harOrigin return the most used origin of requests
+func harOrigin(steps []*harStep) string {
+	counts := make(map[string]int)
+	origin := ""
+	for _, step := range steps {
+		u, _ := url.Parse(step.entry.Request.URL)
+		o := u.Scheme + "://" + u.Host
+		counts[o]++
+		if counts[o] > counts[origin] {
+			origin = o
+		}
+	}
+	return origin
+}
+
+var nonWordTag, _ = regexp.Compile(`\W+`)
+
+// name of case is the last path segment without digit, it's unique in file
+func (im *harImporter) name(rawURL string) string {
+	name := "index"
+	u, _ := url.Parse(rawURL)
+	segments := strings.Split(u.Path, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		segment := strings.Trim(nonWordTag.ReplaceAllString(segments[i], "_"), "_")
+		if segment != "" && !strings.ContainsAny(segment, "0123456789") {
+			name = segment
+			break
+		}
+	}
+
+	im.names[name]++
+	if n := im.names[name]; n > 1 {
+		name += "_" + strconv.Itoa(n)
+	}
+	return name
+}
+
+// headers are set by client or cookie jar
+var harSkippedHeaders = map[string]bool{
+	"host": true, "content-length": true, "cookie": true, "connection": true,
+}
+
+// request set request by HAR request, reused values are replaced by request variables
+func (im *harImporter) request(req *fasthttp.Request, hr *harRequest) {
+	uri, _, _ := strings.Cut(hr.URL, "#")
+	im.sent.WriteString(uri + "\n")
+	if im.host != "" && strings.HasPrefix(uri, im.host) {
+		if rest := uri[len(im.host):]; rest == "" || rest[0] == '/' || rest[0] == '?' {
+			uri = "{{host}}" + rest
+		}
+	}
+	req.Header.SetMethod(hr.Method)
+	req.SetRequestURI(im.reuse(uri))
+
+	for _, h := range hr.Headers {
+		name := strings.ToLower(h.Name)
+		if strings.HasPrefix(name, ":") || harSkippedHeaders[name] {
+			continue
+		}
+		im.sent.WriteString(h.Value + "\n")
+		value := h.Value
+		if name == "accept-encoding" {
+			// response body of br and zstd can't be decoded for request variables
+			if value = harAcceptEncoding(value); value == "" {
+				continue
+			}
+		}
+		req.Header.Set(h.Name, im.reuse(value))
+	}
+
+	if pd := hr.PostData; pd != nil {
+		body := pd.Text
+		if body == "" && len(pd.Params) > 0 {
+			form := make([]string, 0, len(pd.Params))
+			for _, p := range pd.Params {
+				form = append(form, url.QueryEscape(p.Name)+"="+url.QueryEscape(p.Value))
+			}
+			body = strings.Join(form, "&")
+		}
+		im.sent.WriteString(body + "\n")
+		req.SetBodyString(im.reuse(body))
+		if len(req.Header.ContentType()) == 0 && pd.MimeType != "" {
+			req.Header.SetContentType(pd.MimeType)
+		}
+	}
+}
+
+func harAcceptEncoding(value string) string {
+	var encodings []string
+	for _, encoding := range strings.Split(value, ",") {
+		name, _, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		switch name {
+		case "gzip", "deflate", "identity":
+			encodings = append(encodings, strings.TrimSpace(encoding))
+		}
+	}
+	return strings.Join(encodings, ", ")
+}
+
+// reuse replace values given by earlier responses, longer values are replaced first
+func (im *harImporter) reuse(text string) string {
+	if len(im.values) == 0 || text == "" {
+		return text
+	}
+	values := make([]string, 0, len(im.values))
+	for value := range im.values {
+		if strings.Contains(text, value) {
+			values = append(values, value)
+		}
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if len(values[i]) != len(values[j]) {
+			return len(values[i]) > len(values[j])
+		}
+		return values[i] < values[j]
+	})
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, im.values[value])
+	}
+	return text
+}
+
+// headers of response are not values of application
+var harIgnoredHeaders = map[string]bool{
+	"date": true, "server": true, "content-type": true, "content-length": true, "content-encoding": true,
+	"transfer-encoding": true, "connection": true, "keep-alive": true, "cache-control": true,
+	"expires": true, "pragma": true, "last-modified": true, "etag": true, "vary": true, "age": true,
+	"set-cookie": true, "location": true, "via": true, "alt-svc": true, "strict-transport-security": true,
+	"content-security-policy": true, "referrer-policy": true, "x-content-type-options": true,
+	"x-frame-options": true, "x-xss-protection": true,
+}
+
+// record values of response by request variables of case name, values sent by earlier
+// requests are not given by response
+func (im *harImporter) record(name string, resp *harResponse) {
+	for _, h := range resp.Headers {
+		lower := strings.ToLower(h.Name)
+		if !harIgnoredHeaders[lower] && !strings.HasPrefix(lower, "access-control-") {
+			im.add(h.Value, fmt.Sprintf("{{%s.response.headers.%s}}", name, h.Name))
+		}
+	}
+
+	text := resp.Content.Text
+	if resp.Content.Encoding == "base64" {
+		data, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return
+		}
+		text = string(data)
+	}
+	trimmed := strings.TrimSpace(text)
+	if !strings.Contains(resp.Content.MimeType, "json") && !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(trimmed), &body); err != nil {
+		return
+	}
+	walkJSON(body, "$", func(path, value string) {
+		im.add(value, fmt.Sprintf("{{%s.response.body.%s}}", name, path))
+	})
+}
+
+var jsonPathNameTag, _ = regexp.Compile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// walk visit string values of json with JSONPath, keys can't be written in path are skipped
+func walkJSON(v interface{}, path string, visit func(path, value string)) {
+	switch val := v.(type) {
+	case string:
+		visit(path, val)
+	case []interface{}:
+		for i, item := range val {
+			walkJSON(item, path+"["+strconv.Itoa(i)+"]", visit)
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			switch {
+			case jsonPathNameTag.MatchString(key):
+				walkJSON(val[key], path+"."+key, visit)
+			case !strings.ContainsAny(key, `'\{}`):
+				walkJSON(val[key], path+"['"+key+"']", visit)
+			}
+		}
+	}
+}
+
+// add a value of response, short value and value without digit are like constants, first
+// response given the value is referred
+func (im *harImporter) add(value, ref string) {
+	if len(value) < minReusedLength || !strings.ContainsAny(value, "0123456789") ||
+		strings.ContainsAny(value, " \t\r\n") || strings.Contains(im.sent.String(), value) {
+		return
+	}
+	if _, ok := im.values[value]; !ok {
+		im.values[value] = ref
+	}
+}
@@ -0,0 +1,147 @@
+package httpfile
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// sessionHAR is a recorded session, login echo a token used by later requests
+var sessionHAR = fmt.Sprintf(`{"log": {"version": "1.2", "creator": {"name": "test", "version": "1"}, "entries": [
+	{"startedDateTime": "2024-01-01T10:00:00.000Z", "time": 50,
+	 "request": {"method": "GET", "url": "%[1]s", "httpVersion": "HTTP/1.1",
+		"headers": [{"name": ":authority", "value": "127.0.0.1"}, {"name": "Accept-Encoding", "value": "gzip, deflate, br"}, {"name": "Cookie", "value": "a=b"}]},
+	 "response": {"status": 200, "headers": [], "content": {"mimeType": "text/html", "text": "<html></html>"}}},
+	{"startedDateTime": "2024-01-01T10:00:00.060Z", "time": 10,
+	 "request": {"method": "GET", "url": "%[1]sstatic/app.js?v=1", "headers": []},
+	 "response": {"status": 200, "headers": [], "content": {"mimeType": "application/javascript"}}},
+	{"startedDateTime": "2024-01-01T10:00:01.250Z", "time": 30,
+	 "request": {"method": "POST", "url": "%[1]sapi/login", "headers": [{"name": "Content-Type", "value": "application/json"}],
+		"postData": {"mimeType": "application/json", "text": "{\"data\": {\"token\": \"tk-20240101\", \"user-id\": \"u-00000042\"}}"}},
+	 "response": {"status": 302, "redirectURL": "/api/session", "headers": [], "content": {}}},
+	{"startedDateTime": "2024-01-01T10:00:01.290Z", "time": 20,
+	 "request": {"method": "GET", "url": "%[1]sapi/session", "headers": []},
+	 "response": {"status": 200, "headers": [{"name": "X-Session", "value": "s-123456789"}, {"name": "Date", "value": "Mon, 01 Jan 2024 10:00:01 GMT"}],
+		"content": {"mimeType": "application/json", "text": "eyJ0b2tlbiI6ICJ0ay05OTk5OTk5OSIsICJpZCI6ICJ1LTAwMDAwMDQyIn0=", "encoding": "base64"}}},
+	{"startedDateTime": "2024-01-01T10:00:01.320Z", "time": 20,
+	 "request": {"method": "PUT", "url": "%[1]susers/u-00000042/profile#top",
+		"headers": [{"name": "Authorization", "value": "Bearer tk-99999999"}, {"name": "X-Session", "value": "s-123456789"}],
+		"postData": {"mimeType": "application/x-www-form-urlencoded", "params": [{"name": "nick", "value": "a b"}]}},
+	 "response": {"status": 200, "headers": [], "content": {"mimeType": "application/json", "text": "{\"nick\": \"a b\"}"}}},
+	{"startedDateTime": "2024-01-01T10:00:02.000Z", "time": 10,
+	 "request": {"method": "GET", "url": "https://cdn.example.com/profile", "headers": []},
+	 "response": {"status": 0, "headers": [], "content": {}}},
+	{"startedDateTime": "2024-01-01T10:00:01.400Z", "time": 10,
+	 "request": {"method": "GET", "url": "data:image/png;base64,AAAA", "headers": []},
+	 "response": {"status": 200, "headers": [], "content": {}}}
+]}}`, echoServer)
+
+func TestImportHAR(t *testing.T) {
+	file, err := ImportHAR([]byte(sessionHAR), HAROptions{Exclude: DefaultHARExclude, MinThinkTime: DefaultMinThinkTime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]string{"host": strings.TrimSuffix(echoServer, "/")}, file.Variables)
+	assert.Len(t, file.Cases, 3)
+
+	buff := bytes.NewBuffer(nil)
+	for _, c := range file.Cases {
+		c.WriteTo(buff)
+	}
+	assert.Equal(t, `# @name index
+GET {{host}}/
+Accept-Encoding: gzip, deflate
+# @name login
+# @think 1.2s
+POST {{host}}/api/login
+Content-Type: application/json
+
+{"data": {"token": "tk-20240101", "user-id": "u-00000042"}}
+# @name profile
+PUT {{host}}/users/u-00000042/profile
+Content-Type: application/x-www-form-urlencoded
+Authorization: Bearer {{login.response.body.$.token}}
+X-Session: {{login.response.headers.X-Session}}
+
+nick=a+b
+`, buff.String())
+
+	file, err = ImportHAR([]byte(sessionHAR), HAROptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, file.Cases, 4)
+	assert.Equal(t, "app_js", file.Cases[1].Name)
+	assert.Equal(t, 1180*time.Millisecond, file.Cases[2].think)
+
+	_, err = ImportHAR([]byte(`{"log": `), HAROptions{})
+	assert.EqualError(t, err, "har: unexpected end of JSON input")
+}
+
+func TestImportHARReuse(t *testing.T) {
+	entries := `{"log": {"entries": [
+		{"startedDateTime": "2024-01-01T10:00:00Z", "time": 1,
+		 "request": {"method": "POST", "url": "%[1]slogin", "headers": [], "postData": {"mimeType": "application/json", "text": "{\"list\": [{\"access-token\": \"abc-12345678\"}]}"}},
+		 "response": {"status": 200, "headers": [], "content": {"mimeType": "application/json", "text": "{\"list\": [{\"access-token\": \"new-87654321\"}], \"short\": \"a1\", \"word\": \"abcdefghij\"}"}}},
+		{"startedDateTime": "2024-01-01T10:00:00.100Z", "time": 1,
+		 "request": {"method": "POST", "url": "%[1]sitems?token=new-87654321&s=a1", "headers": [{"name": "Content-Type", "value": "application/json"}],
+		  "postData": {"mimeType": "application/json", "text": "{\"t\": \"new-87654321\", \"w\": \"abcdefghij\", \"old\": \"abc-12345678\"}"}},
+		 "response": {"status": 200, "headers": [], "content": {"mimeType": "application/json", "text": "{}"}}}
+	]}}`
+	file, err := ImportHAR([]byte(fmt.Sprintf(entries, echoServer)), HAROptions{MinThinkTime: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := file.Cases[1]
+	assert.Equal(t, "items", items.Name)
+	assert.Equal(t, time.Duration(0), items.think)
+	assert.Equal(t, "{{host}}/items?token={{login.response.body.$.list[0]['access-token']}}&s=a1", string(items.request.RequestURI()))
+	assert.Equal(t, `{"t": "{{login.response.body.$.list[0]['access-token']}}", "w": "abcdefghij", "old": "abc-12345678"}`, string(items.request.Body()))
+
+	// value is from response of execution, echo server respond the request body
+	buff := bytes.NewBufferString("@host = " + file.Variables["host"] + "\n")
+	for i, c := range file.Cases {
+		if i > 0 {
+			buff.WriteString("###\n")
+		}
+		c.WriteTo(buff)
+	}
+	parsed, err := ParseBytes(buff.Bytes(), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.Execute(&fasthttp.Client{}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "/items?token=abc-12345678&s=a1", string(parsed.Cases[1].request.RequestURI()))
+	parsed.Release()
+}
+
+func TestThinkTime(t *testing.T) {
+	content := fmt.Sprintf(`
+	# @think 200ms
+	GET %s
+	`, echoServer)
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 200*time.Millisecond, file.Cases[0].think)
+
+	start := time.Now()
+	assert.NoError(t, file.Duplicate(false, false).Execute(&fasthttp.Client{}))
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+
+	file.NoThinkTime = true
+	start = time.Now()
+	assert.NoError(t, file.Duplicate(false, false).Execute(&fasthttp.Client{}))
+	assert.Less(t, time.Since(start), 200*time.Millisecond)
+
+	_, err = ParseBytes([]byte("# @think soon\nGET " + echoServer))
+	assert.EqualError(t, err, `case 1: invalid think time "soon"`)
+}
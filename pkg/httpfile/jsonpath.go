@@ -3,9 +3,12 @@ package httpfile
 import (
 	"bytes"
 	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
 
+	"github.com/fantai/ftab/pkg/jsonpath"
 	"go.uber.org/zap"
-	"k8s.io/client-go/util/jsonpath"
 )
 
 func objFromString(text []byte) interface{} {
@@ -18,12 +21,71 @@ func objFromString(text []byte) interface{} {
 	return parsed
 }
 
+// pathCache keep compiled JSONPath of a case, it's shared by duplicated cases
+type pathCache struct {
+	paths sync.Map
+}
+
+// normalizeJSONPath add $ to path like .a or a.b for compatibility
+func normalizeJSONPath(path string) string {
+	switch {
+	case strings.HasPrefix(path, "$"):
+		return path
+	case strings.HasPrefix(path, ".") || strings.HasPrefix(path, "["):
+		return "$" + path
+	}
+	return "$." + path
+}
+
+// compile path, compiled path is cached if cache is not nil
+func (c *pathCache) compile(path string) (*jsonpath.Path, error) {
+	if c != nil {
+		if p, ok := c.paths.Load(path); ok {
+			return p.(*jsonpath.Path), nil
+		}
+	}
+	p, err := jsonpath.Compile(normalizeJSONPath(path))
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		c.paths.Store(path, p)
+	}
+	return p, nil
+}
+
+// marshalJSON encode value as json without escaping html characters
+func marshalJSON(val interface{}) []byte {
+	buf := bytes.NewBuffer(nil)
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(val); err != nil {
+		zap.L().Error("json encode failed", zap.Error(err))
+		return nil
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n")
+}
+
+// jsonValueString format json value, string is not quoted, array and object are json encoded
+func jsonValueString(val interface{}) string {
+	switch v := val.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	}
+	return string(marshalJSON(val))
+}
+
 // JSONPathGet get a pathed value from data
 func JSONPathGet(data interface{}, path string) string {
 	if data == nil {
 		return ""
 	}
-	var err error
 
 	switch text := data.(type) {
 	case string:
@@ -35,18 +97,17 @@ func JSONPathGet(data interface{}, path string) string {
 		return ""
 	}
 
-	p := jsonpath.New("")
-	p.AllowMissingKeys(true)
-	err = p.Parse("{" + path + "}")
+	p, err := (*pathCache)(nil).compile(path)
 	if err != nil {
 		zap.L().Error("jsonpath parse failed", zap.String("path", path), zap.Error(err))
 		return ""
 	}
-	buf := new(bytes.Buffer)
-	err = p.Execute(buf, data)
-	if err != nil {
-		zap.L().Error("jsonpath exectue failed", zap.Error(err))
+	values := p.Query(data)
+	switch len(values) {
+	case 0:
 		return ""
+	case 1:
+		return jsonValueString(values[0])
 	}
-	return buf.String()
+	return jsonValueString(values)
 }
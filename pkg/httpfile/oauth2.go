@@ -0,0 +1,203 @@
+package httpfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// OAuth2Profile is the config of a oauth2 token endpoint, token is cached and
+// refreshed before expiry, it's shared by all virtual users
+type OAuth2Profile struct {
+	Name         string `json:"-"`
+	TokenURL     string `json:"tokenUrl"`
+	GrantType    string `json:"grantType"` // client_credentials or password, default is client_credentials
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	Scope        string `json:"scope"`
+	Audience     string `json:"audience"`
+	Username     string `json:"username"`  // username of password grant
+	Password     string `json:"password"`  // password of password grant
+	AuthStyle    string `json:"authStyle"` // header or body, how client credentials are sent, default is header
+
+	mutex        sync.Mutex
+	client       *fasthttp.Client // client of token requests, nil is oauth2Client
+	accessToken  string
+	refreshToken string
+	expiry       time.Time
+}
+
+// oauth2Token is token endpoint response
+type oauth2Token struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Error        string `json:"error"`
+	Description  string `json:"error_description"`
+}
+
+// oauth2Client is the client to request token endpoint when client config of
+// virtual users is not given
+var oauth2Client = &fasthttp.Client{
+	ReadTimeout:  10 * time.Second,
+	WriteTimeout: 10 * time.Second,
+}
+
+// expand variables of environment in profile
+func (p *OAuth2Profile) expand(ve Replacer) {
+	for _, field := range []*string{
+		&p.TokenURL, &p.GrantType, &p.ClientID, &p.ClientSecret, &p.Scope,
+		&p.Audience, &p.Username, &p.Password, &p.AuthStyle,
+	} {
+		*field = ReplaceVariableString(*field, ve)
+	}
+}
+
+// expiring is true if token should be refreshed, it's refreshed before expiry
+func (p *OAuth2Profile) expiring(now time.Time) bool {
+	if p.accessToken == "" {
+		return true
+	}
+	if p.expiry.IsZero() {
+		return false
+	}
+	return now.After(p.expiry)
+}
+
+// Token return a valid access token, a new one is requested if expiring
+func (p *OAuth2Profile) Token() (string, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := time.Now()
+	if !p.expiring(now) {
+		return p.accessToken, nil
+	}
+
+	var token *oauth2Token
+	var err error
+	if p.refreshToken != "" {
+		token, err = p.requestToken(url.Values{
+			"grant_type":    {"refresh_token"},
+			"refresh_token": {p.refreshToken},
+		})
+		if err != nil {
+			zap.L().Warn("oauth2 refresh token failed", zap.String("profile", p.Name), zap.Error(err))
+		}
+	}
+	if token == nil {
+		token, err = p.requestToken(p.grant())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	p.accessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		p.refreshToken = token.RefreshToken
+	}
+	p.expiry = time.Time{}
+	if token.ExpiresIn > 0 {
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		// refresh early, at most 1 minute, for requests in flight
+		early := lifetime / 10
+		if early > time.Minute {
+			early = time.Minute
+		}
+		p.expiry = now.Add(lifetime - early)
+	}
+	return p.accessToken, nil
+}
+
+func (p *OAuth2Profile) grant() url.Values {
+	form := url.Values{}
+	switch p.GrantType {
+	case "password":
+		form.Set("grant_type", "password")
+		form.Set("username", p.Username)
+		form.Set("password", p.Password)
+	default:
+		form.Set("grant_type", "client_credentials")
+	}
+	if p.Scope != "" {
+		form.Set("scope", p.Scope)
+	}
+	if p.Audience != "" {
+		form.Set("audience", p.Audience)
+	}
+	return form
+}
+
+func (p *OAuth2Profile) requestToken(form url.Values) (*oauth2Token, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	if p.AuthStyle == "body" {
+		form.Set("client_id", p.ClientID)
+		form.Set("client_secret", p.ClientSecret)
+	} else if p.ClientID != "" {
+		req.Header.Set("Authorization", basicAuth(url.QueryEscape(p.ClientID), url.QueryEscape(p.ClientSecret)))
+	}
+
+	req.SetRequestURI(p.TokenURL)
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBodyString(form.Encode())
+
+	client := p.client
+	if client == nil {
+		client = oauth2Client
+	}
+	if err := client.Do(req, resp); err != nil {
+		return nil, fmt.Errorf("oauth2 %s token request: %w", p.Name, err)
+	}
+
+	token := &oauth2Token{}
+	if err := json.Unmarshal(resp.Body(), token); err != nil {
+		return nil, fmt.Errorf("oauth2 %s token response %d: %w", p.Name, resp.StatusCode(), err)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2 %s token response %d: %s %s", p.Name, resp.StatusCode(), token.Error, token.Description)
+	}
+	return token, nil
+}
+
+// configure let token requests use client config of virtual users, so proxy
+// and tls options apply to token endpoints too
+func (env *Environment) configure(cfg *ClientConfig) {
+	client := newVirtualUser(cfg).client
+	// profiles may request the same token endpoint concurrently
+	client.MaxConnsPerHost = 0
+	for _, profile := range env.OAuth2 {
+		profile.mutex.Lock()
+		profile.client = client
+		profile.mutex.Unlock()
+	}
+}
+
+// funOAuth2 is {{$oauth2 profile}}, return access token of profile
+func funOAuth2(env *Environment, args []string) (string, bool) {
+	if env == nil || len(args) < 2 {
+		return "", false
+	}
+	profile, ok := env.OAuth2[args[1]]
+	if !ok {
+		zap.L().Error("oauth2 profile not found", zap.String("profile", args[1]))
+		return "", false
+	}
+	token, err := profile.Token()
+	if err != nil {
+		zap.L().Error("oauth2 token failed", zap.String("profile", args[1]), zap.Error(err))
+		return "", false
+	}
+	return token, true
+}
@@ -0,0 +1,115 @@
+package httpfile
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// tokenServer is a stub token endpoint, token expires in 1 second
+func tokenServer(issued *int64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		user, secret, _ := r.BasicAuth()
+		grant := r.PostForm.Get("grant_type")
+		ok := (grant == "client_credentials" && user == "id" && secret == "secret") ||
+			(grant == "password" && r.PostForm.Get("username") == "tom" && r.PostForm.Get("client_id") == "id")
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		n := atomic.AddInt64(issued, 1)
+		fmt.Fprintf(w, `{"access_token": "token%d", "token_type": "bearer", "expires_in": 1}`, n)
+	}))
+}
+
+func TestOAuth2(t *testing.T) {
+	issued := new(int64)
+	server := tokenServer(issued)
+	defer server.Close()
+
+	env, err := ParseEnvironment([]byte(fmt.Sprintf(`{
+		"$shared": {"auth": "%s", "secret": "secret"},
+		"local": {"server": "%s"},
+		"$oauth2": {
+			"api": {"tokenUrl": "{{auth}}/token", "clientId": "id", "clientSecret": "{{secret}}"},
+			"user": {"tokenUrl": "{{auth}}/token", "grantType": "password", "clientId": "id",
+				"username": "tom", "password": "cat", "authStyle": "body"},
+			"bad": {"tokenUrl": "{{auth}}/token", "clientId": "id", "clientSecret": "wrong"}
+		}
+	}`, server.URL, echoServer)), "local")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// token is shared by workers
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := env.OAuth2["api"].Token()
+			assert.NoError(t, err)
+			assert.Equal(t, "token1", token)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(1), atomic.LoadInt64(issued))
+
+	// refresh before expiry
+	time.Sleep(950 * time.Millisecond)
+	token, _ := env.OAuth2["api"].Token()
+	assert.Equal(t, "token2", token)
+
+	_, err = env.OAuth2["bad"].Token()
+	assert.Error(t, err)
+
+	file, err := ParseBytes([]byte(`
+	POST {{server}}
+	Content-Type: application/json
+
+	{"api": "{{$oauth2 api}}", "user": "{{$oauth2 user}}"}
+	`), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+	file.Env = env
+
+	err = file.Execute(&fasthttp.Client{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"api": "token2", "user": "token3"}`, string(file.Cases[0].response.Body()))
+
+	// token endpoint is requested through proxy of virtual users
+	proxyURL, _, forwards := startProxy(t, false)
+	proxied, err := ParseEnvironment([]byte(fmt.Sprintf(`{
+		"local": {"server": "%s"},
+		"$oauth2": {"api": {"tokenUrl": "http://oauth.test:%s/token", "clientId": "id", "clientSecret": "secret"}}
+	}`, echoServer, server.URL[strings.LastIndex(server.URL, ":")+1:])), "local")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err = ParseBytes([]byte(`
+	GET {{server}}
+	Authorization: Bearer {{$oauth2 api}}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Env = proxied
+	report := ReportStat(Bench(file, 1, 2, -1, WithProxy(proxyURL)))
+	assert.Equal(t, 2, report.Successed)
+	assert.Equal(t, int64(1), atomic.LoadInt64(forwards))
+
+	_, err = ParseEnvironment([]byte(`{"local": {}}`), "prod")
+	assert.Error(t, err)
+}
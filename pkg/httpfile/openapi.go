@@ -0,0 +1,773 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"mime/multipart"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fantai/ftab/pkg/mock"
+	"github.com/fantai/ftab/pkg/mock/helper"
+	"github.com/valyala/fasthttp"
+	"gopkg.in/yaml.v3"
+)
+
+// openAPISpec is OpenAPI 3 document, only fields used by ftab are declared
+type openAPISpec struct {
+	OpenAPI    string                `yaml:"openapi"`
+	Servers    []oaServer            `yaml:"servers"`
+	Paths      oaPaths               `yaml:"paths"`
+	Components oaComponents          `yaml:"components"`
+	Security   []map[string][]string `yaml:"security"`
+}
+
+type oaServer struct {
+	URL       string `yaml:"url"`
+	Variables map[string]struct {
+		Default string `yaml:"default"`
+	} `yaml:"variables"`
+}
+
+// oaPaths keep paths in order of document
+type oaPaths []oaPath
+
+type oaPath struct {
+	path string
+	item *oaPathItem
+}
+
+func (p *oaPaths) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("paths must be a map")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		item := &oaPathItem{}
+		if err := node.Content[i+1].Decode(item); err != nil {
+			return err
+		}
+		*p = append(*p, oaPath{path: node.Content[i].Value, item: item})
+	}
+	return nil
+}
+
+type oaPathItem struct {
+	Parameters []*oaParameter `yaml:"parameters"`
+	Get        *oaOperation   `yaml:"get"`
+	Put        *oaOperation   `yaml:"put"`
+	Post       *oaOperation   `yaml:"post"`
+	Delete     *oaOperation   `yaml:"delete"`
+	Options    *oaOperation   `yaml:"options"`
+	Head       *oaOperation   `yaml:"head"`
+	Patch      *oaOperation   `yaml:"patch"`
+	Trace      *oaOperation   `yaml:"trace"`
+}
+
+type oaOperation struct {
+	OperationID string                 `yaml:"operationId"`
+	Parameters  []*oaParameter         `yaml:"parameters"`
+	RequestBody *oaRequestBody         `yaml:"requestBody"`
+	Security    *[]map[string][]string `yaml:"security"`
+}
+
+type oaParameter struct {
+	Ref      string                `yaml:"$ref"`
+	Name     string                `yaml:"name"`
+	In       string                `yaml:"in"`
+	Required bool                  `yaml:"required"`
+	Schema   *oaSchema             `yaml:"schema"`
+	Example  interface{}           `yaml:"example"`
+	Examples map[string]*oaExample `yaml:"examples"`
+}
+
+type oaExample struct {
+	Ref   string      `yaml:"$ref"`
+	Value interface{} `yaml:"value"`
+}
+
+type oaRequestBody struct {
+	Ref     string    `yaml:"$ref"`
+	Content oaContent `yaml:"content"`
+}
+
+// oaContent keep media types in order of document
+type oaContent []oaMedia
+
+type oaMedia struct {
+	mediaType string
+	media     *oaMediaType
+}
+
+func (c *oaContent) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("content must be a map")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		media := &oaMediaType{}
+		if err := node.Content[i+1].Decode(media); err != nil {
+			return err
+		}
+		*c = append(*c, oaMedia{mediaType: node.Content[i].Value, media: media})
+	}
+	return nil
+}
+
+type oaMediaType struct {
+	Schema   *oaSchema             `yaml:"schema"`
+	Example  interface{}           `yaml:"example"`
+	Examples map[string]*oaExample `yaml:"examples"`
+}
+
+type oaSchema struct {
+	Ref        string        `yaml:"$ref"`
+	Type       interface{}   `yaml:"type"`
+	Format     string        `yaml:"format"`
+	Enum       []interface{} `yaml:"enum"`
+	Default    interface{}   `yaml:"default"`
+	Example    interface{}   `yaml:"example"`
+	Examples   []interface{} `yaml:"examples"`
+	Properties oaProperties  `yaml:"properties"`
+	Items      *oaSchema     `yaml:"items"`
+	AllOf      []*oaSchema   `yaml:"allOf"`
+	OneOf      []*oaSchema   `yaml:"oneOf"`
+	AnyOf      []*oaSchema   `yaml:"anyOf"`
+	ReadOnly   bool          `yaml:"readOnly"`
+	Minimum    *float64      `yaml:"minimum"`
+}
+
+// oaProperties keep properties in order of document
+type oaProperties []oaProperty
+
+type oaProperty struct {
+	name   string
+	schema *oaSchema
+}
+
+func (p *oaProperties) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return errors.New("properties must be a map")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		schema := &oaSchema{}
+		if err := node.Content[i+1].Decode(schema); err != nil {
+			return err
+		}
+		*p = append(*p, oaProperty{name: node.Content[i].Value, schema: schema})
+	}
+	return nil
+}
+
+// typ is type of schema, type of OpenAPI 3.1 may be a list with null
+func (s *oaSchema) typ() string {
+	switch t := s.Type.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if name, ok := item.(string); ok && name != "null" {
+				return name
+			}
+		}
+	}
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	return ""
+}
+
+type oaComponents struct {
+	Schemas         map[string]*oaSchema         `yaml:"schemas"`
+	Parameters      map[string]*oaParameter      `yaml:"parameters"`
+	RequestBodies   map[string]*oaRequestBody    `yaml:"requestBodies"`
+	Examples        map[string]*oaExample        `yaml:"examples"`
+	SecuritySchemes map[string]*oaSecurityScheme `yaml:"securitySchemes"`
+}
+
+type oaSecurityScheme struct {
+	Ref    string `yaml:"$ref"`
+	Type   string `yaml:"type"`
+	Name   string `yaml:"name"`
+	In     string `yaml:"in"`
+	Scheme string `yaml:"scheme"`
+}
+
+// orderedObject is json object keep order of fields
+type orderedObject []orderedField
+
+type orderedField struct {
+	name  string
+	value interface{}
+}
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	buff := bytes.NewBufferString("{")
+	for i, f := range o {
+		if i > 0 {
+			buff.WriteString(",")
+		}
+		name, _ := json.Marshal(f.name)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(name)
+		buff.WriteString(":")
+		buff.Write(value)
+	}
+	buff.WriteString("}")
+	return buff.Bytes(), nil
+}
+
+// DefaultBaseURL is base url of cases when spec has no absolute server url
+const DefaultBaseURL = "http://localhost"
+
+// maxSchemaDepth limit nested objects of mocked body, recursive schemas stop at it
+const maxSchemaDepth = 6
+
+// placeholderValue is value of variables can't be given by spec, like credentials
+const placeholderValue = "changeme"
+
+// OpenAPIOptions is options of OpenAPI import
+type OpenAPIOptions struct {
+	BaseURL string // base url of cases, default is url of first server
+}
+
+// openAPIImporter convert operations to cases
+type openAPIImporter struct {
+	spec     *openAPISpec
+	file     *HTTPFile
+	names    map[string]int
+	explicit map[string]bool // variable is defined by example of spec
+}
+
+// ImportOpenAPI convert an OpenAPI 3 spec in yaml or json to http file, each operation is
+// a named case, path and query parameters are variables, body is example or mocked from
+// schema, credentials of security schemes are variables too
+func ImportOpenAPI(data []byte, opts OpenAPIOptions) (*HTTPFile, error) {
+	spec := &openAPISpec{}
+	if err := yaml.Unmarshal(data, spec); err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, errors.New("openapi: only OpenAPI 3 is supported")
+	}
+
+	im := &openAPIImporter{
+		spec: spec,
+		file: &HTTPFile{
+			Variables:    make(map[string]string),
+			Cases:        make([]*Case, 0),
+			MaxRedirects: DefaultMaxRedirects,
+		},
+		names:    make(map[string]int),
+		explicit: make(map[string]bool),
+	}
+	im.file.Variables["baseUrl"] = spec.baseURL(opts.BaseURL)
+
+	for _, p := range spec.Paths {
+		for _, op := range []struct {
+			method string
+			op     *oaOperation
+		}{
+			{fasthttp.MethodGet, p.item.Get}, {fasthttp.MethodPut, p.item.Put},
+			{fasthttp.MethodPost, p.item.Post}, {fasthttp.MethodDelete, p.item.Delete},
+			{fasthttp.MethodOptions, p.item.Options}, {fasthttp.MethodHead, p.item.Head},
+			{fasthttp.MethodPatch, p.item.Patch}, {fasthttp.MethodTrace, p.item.Trace},
+		} {
+			if op.op == nil {
+				continue
+			}
+			c, err := im.operation(op.method, p.path, p.item, op.op)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s %s: %w", op.method, p.path, err)
+			}
+			im.file.Cases = append(im.file.Cases, c)
+		}
+	}
+	return im.file, nil
+}
+
+// baseURL is url of first server with default values of variables
+func (spec *openAPISpec) baseURL(given string) string {
+	if given != "" {
+		return strings.TrimSuffix(given, "/")
+	}
+	if len(spec.Servers) == 0 {
+		return DefaultBaseURL
+	}
+	server := spec.Servers[0]
+	u := server.URL
+	for name, v := range server.Variables {
+		u = strings.ReplaceAll(u, "{"+name+"}", v.Default)
+	}
+	if !strings.Contains(u, "://") {
+		u = DefaultBaseURL + "/" + strings.TrimPrefix(u, "/")
+	}
+	return strings.TrimSuffix(u, "/")
+}
+
+// operation build a case of operation
+func (im *openAPIImporter) operation(method, path string, item *oaPathItem, op *oaOperation) (*Case, error) {
+	c := newCase()
+	c.Name = im.name(method, path, op.OperationID)
+	req := c.request
+	req.Header.SetMethod(method)
+
+	params, err := im.parameters(item.Parameters, op.Parameters)
+	if err != nil {
+		return nil, err
+	}
+	var query, cookies []string
+	for _, p := range params {
+		value, explicit := im.parameterValue(p)
+		switch p.In {
+		case "path":
+			name := im.variable(c.Name, p.Name, url.PathEscape(value), explicit)
+			path = strings.ReplaceAll(path, "{"+p.Name+"}", "{{"+name+"}}")
+		case "query":
+			if p.Required || explicit {
+				name := im.variable(c.Name, p.Name, url.QueryEscape(value), explicit)
+				query = append(query, url.QueryEscape(p.Name)+"={{"+name+"}}")
+			}
+		case "header":
+			// Accept, Content-Type and Authorization are described by spec otherwise
+			switch strings.ToLower(p.Name) {
+			case "accept", "content-type", "authorization":
+			default:
+				req.Header.Set(p.Name, value)
+			}
+		case "cookie":
+			cookies = append(cookies, p.Name+"="+value)
+		}
+	}
+
+	security := im.spec.Security
+	if op.Security != nil {
+		security = *op.Security
+	}
+	if len(security) > 0 {
+		// the first requirement is used if there are alternatives
+		q, c, err := im.security(req, security[0])
+		if err != nil {
+			return nil, err
+		}
+		query, cookies = append(query, q...), append(cookies, c...)
+	}
+	if len(cookies) > 0 {
+		req.Header.Set("Cookie", strings.Join(cookies, "; "))
+	}
+
+	uri := "{{baseUrl}}" + path
+	if len(query) > 0 {
+		uri += "?" + strings.Join(query, "&")
+	}
+	req.SetRequestURI(uri)
+
+	if op.RequestBody != nil {
+		if err := im.requestBody(req, op.RequestBody); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// name of case is operationId or method and words of path, it's unique in file
+func (im *openAPIImporter) name(method, path, operationID string) string {
+	name := strings.Trim(nonWordTag.ReplaceAllString(operationID, "_"), "_")
+	if name == "" {
+		words := []string{strings.ToLower(method)}
+		for _, segment := range strings.Split(path, "/") {
+			if segment = strings.Trim(nonWordTag.ReplaceAllString(segment, "_"), "_"); segment != "" {
+				words = append(words, segment)
+			}
+		}
+		name = strings.Join(words, "_")
+	}
+
+	im.names[name]++
+	if n := im.names[name]; n > 1 {
+		name += "_" + strconv.Itoa(n)
+	}
+	return name
+}
+
+// parameters merge parameters of path item and operation, operation override the same one
+func (im *openAPIImporter) parameters(common, own []*oaParameter) ([]*oaParameter, error) {
+	var params []*oaParameter
+	index := make(map[string]int)
+	for _, p := range append(append([]*oaParameter{}, common...), own...) {
+		if p.Ref != "" {
+			name, err := refName(p.Ref, "parameters")
+			if err != nil {
+				return nil, err
+			}
+			if p = im.spec.Components.Parameters[name]; p == nil {
+				return nil, fmt.Errorf("parameter %s is not defined", name)
+			}
+		}
+		key := p.In + ":" + p.Name
+		if i, ok := index[key]; ok {
+			params[i] = p
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, p)
+	}
+	return params, nil
+}
+
+// refName return name of local reference like #/components/schemas/Pet
+func refName(ref, kind string) (string, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf("reference %s is not supported", ref)
+	}
+	return ref[len(prefix):], nil
+}
+
+// parameterValue is example of parameter or mocked from schema, explicit is false if
+// value is mocked
+func (im *openAPIImporter) parameterValue(p *oaParameter) (string, bool) {
+	if value, ok := im.example(p.Example, p.Examples); ok {
+		return formatParameter(value), true
+	}
+	schema := im.schema(p.Schema)
+	if schema != nil && (schema.Example != nil || schema.Default != nil) {
+		return formatParameter(im.mock(schema, p.Name, maxSchemaDepth)), true
+	}
+	return formatParameter(im.mock(schema, p.Name, maxSchemaDepth)), false
+}
+
+// example is example value or value of the first example in order of name
+func (im *openAPIImporter) example(example interface{}, examples map[string]*oaExample) (interface{}, bool) {
+	if example != nil {
+		return example, true
+	}
+	names := make([]string, 0, len(examples))
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		e := examples[name]
+		if e.Ref != "" {
+			ref, err := refName(e.Ref, "examples")
+			if err != nil || im.spec.Components.Examples[ref] == nil {
+				continue
+			}
+			e = im.spec.Components.Examples[ref]
+		}
+		if e.Value != nil {
+			return e.Value, true
+		}
+	}
+	return nil, false
+}
+
+// formatParameter format value of simple style, array items are separated by comma
+func formatParameter(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatParameter(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}, orderedObject:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(value)
+}
+
+// variable define variable of parameter, the same name is shared by operations unless
+// examples of them are different, then name of case is prefixed
+func (im *openAPIImporter) variable(caseName, param, value string, explicit bool) string {
+	name := strings.Trim(nonWordTag.ReplaceAllString(param, "_"), "_")
+	if old, ok := im.file.Variables[name]; ok {
+		if old == value || !explicit {
+			return name
+		}
+		if !im.explicit[name] {
+			// mocked value is replaced by example
+			im.file.Variables[name] = value
+			im.explicit[name] = true
+			return name
+		}
+		name = caseName + "_" + name
+	}
+	if value == "" {
+		// empty value can't be defined in http file
+		value = placeholderValue
+	}
+	im.file.Variables[name] = value
+	im.explicit[name] = explicit
+	return name
+}
+
+// security set credentials of a security requirement, query parameters and cookies of api key
+// are returned to be merged with parameters
+func (im *openAPIImporter) security(req *fasthttp.Request, requirement map[string][]string) (query, cookies []string, err error) {
+	names := make([]string, 0, len(requirement))
+	for name := range requirement {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		scheme := im.spec.Components.SecuritySchemes[name]
+		if scheme == nil {
+			return nil, nil, fmt.Errorf("security scheme %s is not defined", name)
+		}
+		variable := strings.Trim(nonWordTag.ReplaceAllString(name, "_"), "_")
+		credential := func(name string) string {
+			if _, ok := im.file.Variables[name]; !ok {
+				im.file.Variables[name] = placeholderValue
+			}
+			return "{{" + name + "}}"
+		}
+
+		switch scheme.Type {
+		case "apiKey":
+			switch scheme.In {
+			case "header":
+				req.Header.Set(scheme.Name, credential(variable))
+			case "query":
+				query = append(query, url.QueryEscape(scheme.Name)+"="+credential(variable))
+			case "cookie":
+				cookies = append(cookies, scheme.Name+"="+credential(variable))
+			}
+		case "http":
+			switch strings.ToLower(scheme.Scheme) {
+			case "basic":
+				req.Header.Set("Authorization", "Basic "+credential("username")+":"+credential("password"))
+			case "digest":
+				req.Header.Set("Authorization", "Digest "+credential("username")+" "+credential("password"))
+			default:
+				req.Header.Set("Authorization", "Bearer "+credential(variable))
+			}
+		case "oauth2", "openIdConnect":
+			// token is given by oauth2 profile of env file
+			req.Header.Set("Authorization", "Bearer {{$oauth2 "+variable+"}}")
+		}
+	}
+	return query, cookies, nil
+}
+
+// requestBody set body by the first json media type or the first one
+func (im *openAPIImporter) requestBody(req *fasthttp.Request, body *oaRequestBody) error {
+	if body.Ref != "" {
+		name, err := refName(body.Ref, "requestBodies")
+		if err != nil {
+			return err
+		}
+		if body = im.spec.Components.RequestBodies[name]; body == nil {
+			return fmt.Errorf("request body %s is not defined", name)
+		}
+	}
+	if len(body.Content) == 0 {
+		return nil
+	}
+	chosen := body.Content[0]
+	for _, m := range body.Content {
+		if strings.Contains(m.mediaType, "json") {
+			chosen = m
+			break
+		}
+	}
+
+	value, ok := im.example(chosen.media.Example, chosen.media.Examples)
+	if !ok {
+		value = im.mock(im.schema(chosen.media.Schema), "", maxSchemaDepth)
+	}
+
+	mediaType := chosen.mediaType
+	switch {
+	case strings.Contains(mediaType, "json"):
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return err
+		}
+		req.SetBody(data)
+	case mediaType == "application/x-www-form-urlencoded":
+		var form []string
+		visitFields(value, func(name string, value interface{}) {
+			form = append(form, url.QueryEscape(name)+"="+url.QueryEscape(formatParameter(value)))
+		})
+		req.SetBodyString(strings.Join(form, "&"))
+	case mediaType == "multipart/form-data":
+		buff := bytes.NewBuffer(nil)
+		w := multipart.NewWriter(buff)
+		w.SetBoundary(formBoundary)
+		visitFields(value, func(name string, value interface{}) {
+			w.WriteField(name, formatParameter(value))
+		})
+		w.Close()
+		req.SetBody(buff.Bytes())
+		mediaType = w.FormDataContentType()
+	default:
+		req.SetBodyString(formatParameter(value))
+	}
+	if !strings.Contains(mediaType, "*") {
+		req.Header.SetContentType(mediaType)
+	}
+	return nil
+}
+
+// visitFields visit fields of object value in order
+func visitFields(value interface{}, visit func(name string, value interface{})) {
+	switch v := value.(type) {
+	case orderedObject:
+		for _, f := range v {
+			visit(f.name, f.value)
+		}
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			visit(name, v[name])
+		}
+	}
+}
+
+// schema resolve reference of schema, nil if it's not defined
+func (im *openAPIImporter) schema(s *oaSchema) *oaSchema {
+	for i := 0; s != nil && s.Ref != "" && i < maxSchemaDepth; i++ {
+		name, err := refName(s.Ref, "schemas")
+		if err != nil {
+			return nil
+		}
+		s = im.spec.Components.Schemas[name]
+	}
+	if s != nil && s.Ref != "" {
+		return nil
+	}
+	return s
+}
+
+// mock a value of schema, example, default and the first enum are used if they are given,
+// string is mocked by format and name of property
+func (im *openAPIImporter) mock(s *oaSchema, name string, depth int) interface{} {
+	if s = im.schema(s); s == nil {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case len(s.Examples) > 0:
+		return s.Examples[0]
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		merged := orderedObject{}
+		if depth <= 0 {
+			return merged
+		}
+		for _, part := range s.AllOf {
+			if obj, ok := im.mock(part, name, depth-1).(orderedObject); ok {
+				merged = append(merged, obj...)
+			}
+		}
+		return merged
+	case len(s.OneOf) > 0, len(s.AnyOf) > 0:
+		// composition may reference itself, so it takes a level as nested object
+		if depth <= 0 {
+			return nil
+		} else if len(s.OneOf) > 0 {
+			return im.mock(s.OneOf[0], name, depth-1)
+		}
+		return im.mock(s.AnyOf[0], name, depth-1)
+	}
+
+	switch s.typ() {
+	case "object":
+		obj := orderedObject{}
+		if depth <= 0 {
+			return obj
+		}
+		for _, p := range s.Properties {
+			if ps := im.schema(p.schema); ps != nil && !ps.ReadOnly {
+				obj = append(obj, orderedField{name: p.name, value: im.mock(ps, p.name, depth-1)})
+			}
+		}
+		return obj
+	case "array":
+		if depth <= 0 {
+			return []interface{}{}
+		}
+		if item := im.mock(s.Items, name, depth-1); item != nil {
+			return []interface{}{item}
+		}
+		return []interface{}{}
+	case "integer":
+		if s.Minimum != nil {
+			return int64(*s.Minimum)
+		}
+		return rand.Intn(100) + 1
+	case "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return float64(rand.Intn(10000)) / 100
+	case "boolean":
+		return true
+	case "string":
+		return mockString(name, s.Format)
+	}
+	return nil
+}
+
+var letters = []rune("abcdefghijklmnopqrstuvwxyz")
+var hexLetters = []rune("0123456789abcdef")
+
+// mockString mock string by format, or by name like email and mobile
+func mockString(name, format string) string {
+	switch format {
+	case "date":
+		return mock.DateTime("2006-01-02")
+	case "date-time":
+		return mock.DateTime(time.RFC3339)
+	case "time":
+		return mock.DateTime("15:04:05")
+	case "email":
+		return mock.Default().EMail()
+	case "uuid":
+		return helper.RandString(hexLetters, 8) + "-" + helper.RandString(hexLetters, 4) + "-4" +
+			helper.RandString(hexLetters, 3) + "-a" + helper.RandString(hexLetters, 3) + "-" + helper.RandString(hexLetters, 12)
+	case "uri", "url":
+		return "https://example.com/" + helper.RandString(letters, 6)
+	case "hostname":
+		return "example.com"
+	case "ipv4":
+		return "127.0.0.1"
+	case "ipv6":
+		return "::1"
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(helper.RandString(letters, 6)))
+	case "binary":
+		return ""
+	}
+	if value, ok := mock.Hint(name); ok {
+		return value
+	}
+	if name == "" {
+		name = "value"
+	}
+	return name + "-" + helper.RandString(letters, 6)
+}
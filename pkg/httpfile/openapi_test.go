@@ -0,0 +1,174 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const petStoreSpec = `
+openapi: 3.0.3
+info: {title: pet store, version: "1"}
+servers:
+  - url: https://{env}.example.com/v1/
+    variables:
+      env: {default: api}
+security:
+  - apiKey: []
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      parameters:
+        - {name: limit, in: query, required: true, schema: {type: integer, minimum: 10}}
+        - {name: tag, in: query, schema: {type: string}}
+        - {name: sort, in: query, example: name}
+        - {name: X-Trace, in: header, example: trace-1}
+    post:
+      operationId: createPet
+      security: [{basic: []}]
+      requestBody:
+        content:
+          application/xml: {schema: {$ref: '#/components/schemas/Pet'}}
+          application/json: {schema: {$ref: '#/components/schemas/Pet'}}
+  /pets/{petId}:
+    parameters:
+      - $ref: '#/components/parameters/PetId'
+    get:
+      operationId: get-pet
+      security: []
+    put:
+      parameters:
+        - {name: petId, in: path, required: true, example: 42}
+      security: [{oauth: [write]}, {apiKey: []}]
+      requestBody:
+        $ref: '#/components/requestBodies/PetForm'
+  /owners/{petId}:
+    delete:
+      security: [{bearer: [], token: []}, {query: []}]
+      parameters:
+        - {name: petId, in: path, required: true, example: 7}
+        - {name: session, in: cookie, example: s1}
+components:
+  parameters:
+    PetId: {name: petId, in: path, required: true, schema: {type: integer}}
+  requestBodies:
+    PetForm:
+      content:
+        application/x-www-form-urlencoded:
+          examples:
+            b: {value: {name: b}}
+            a: {$ref: '#/components/examples/Pet'}
+  examples:
+    Pet: {value: {name: kitty, age: 2}}
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        id: {type: integer, readOnly: true}
+        name: {type: string, example: kitty}
+        status: {type: string, enum: [available, sold]}
+        born: {type: string, format: date}
+        owner: {$ref: '#/components/schemas/Owner'}
+        tags: {type: array, items: {type: string, default: cute}}
+        parent: {$ref: '#/components/schemas/Pet'}
+        loop: {$ref: '#/components/schemas/Loop'}
+    Owner:
+      allOf:
+        - {type: object, properties: {email: {type: string}}}
+        - {type: object, properties: {vip: {type: boolean}}}
+    Loop:
+      anyOf:
+        - {$ref: '#/components/schemas/Loop'}
+  securitySchemes:
+    apiKey: {type: apiKey, in: header, name: X-API-Key}
+    query: {type: apiKey, in: query, name: key}
+    token: {type: apiKey, in: cookie, name: token}
+    basic: {type: http, scheme: basic}
+    bearer: {type: http, scheme: bearer}
+    oauth: {type: oauth2}
+`
+
+func TestImportOpenAPI(t *testing.T) {
+	file, err := ImportOpenAPI([]byte(petStoreSpec), OpenAPIOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "https://api.example.com/v1", file.Variables["baseUrl"])
+	assert.Equal(t, "10", file.Variables["limit"])
+	assert.Equal(t, "name", file.Variables["sort"])
+	assert.Equal(t, "42", file.Variables["petId"])
+	assert.Equal(t, "7", file.Variables["delete_owners_petId_petId"])
+	assert.Equal(t, "changeme", file.Variables["apiKey"])
+	assert.Equal(t, "changeme", file.Variables["username"])
+	assert.NotContains(t, file.Variables, "tag")
+
+	names := []string{}
+	for _, c := range file.Cases {
+		names = append(names, c.Name)
+	}
+	assert.Equal(t, []string{"listPets", "createPet", "get_pet", "put_pets_petId", "delete_owners_petId"}, names)
+
+	buff := bytes.NewBuffer(nil)
+	file.Cases[0].WriteTo(buff)
+	assert.Equal(t, `# @name listPets
+GET {{baseUrl}}/pets?limit={{limit}}&sort={{sort}}
+X-Trace: trace-1
+X-Api-Key: {{apiKey}}
+`, buff.String())
+
+	create := file.Cases[1].request
+	assert.Equal(t, "POST", string(create.Header.Method()))
+	assert.Equal(t, "application/json", string(create.Header.ContentType()))
+	assert.Equal(t, "Basic {{username}}:{{password}}", string(create.Header.Peek("Authorization")))
+	assert.Nil(t, create.Header.Peek("X-API-Key"))
+	var pet map[string]interface{}
+	if err := json.Unmarshal(create.Body(), &pet); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "kitty", pet["name"])
+	assert.Equal(t, "available", pet["status"])
+	assert.Regexp(t, `^\d{4}-\d\d-\d\d$`, pet["born"])
+	assert.Equal(t, []interface{}{"cute"}, pet["tags"])
+	assert.Contains(t, pet["owner"].(map[string]interface{})["email"], "@")
+	assert.Equal(t, true, pet["owner"].(map[string]interface{})["vip"])
+	assert.NotContains(t, pet, "id")
+	assert.Contains(t, pet["parent"], "parent")
+	assert.Contains(t, pet, "loop")
+	assert.Nil(t, pet["loop"])
+	assert.Regexp(t, `^{\n  "name": "kitty",\n  "status"`, string(create.Body()))
+
+	get := file.Cases[2].request
+	assert.Equal(t, "{{baseUrl}}/pets/{{petId}}", string(get.RequestURI()))
+	assert.Nil(t, get.Header.Peek("X-API-Key"))
+
+	put := file.Cases[3].request
+	assert.Equal(t, "Bearer {{$oauth2 oauth}}", string(put.Header.Peek("Authorization")))
+	assert.Equal(t, "application/x-www-form-urlencoded", string(put.Header.ContentType()))
+	assert.Equal(t, "age=2&name=kitty", string(put.Body()))
+
+	del := file.Cases[4].request
+	assert.Equal(t, "{{baseUrl}}/owners/{{delete_owners_petId_petId}}", string(del.RequestURI()))
+	assert.Equal(t, "Bearer {{bearer}}", string(del.Header.Peek("Authorization")))
+	assert.Equal(t, "session=s1; token={{token}}", string(del.Header.Peek("Cookie")))
+
+	file, err = ImportOpenAPI([]byte(petStoreSpec), OpenAPIOptions{BaseURL: "http://127.0.0.1:8080/"})
+	assert.NoError(t, err)
+	assert.Equal(t, "http://127.0.0.1:8080", file.Variables["baseUrl"])
+
+	for spec, message := range map[string]string{
+		`swagger: "2.0"`: "openapi: only OpenAPI 3 is supported",
+		`openapi: [`:     "openapi: yaml: line 1: did not find expected node content",
+		`{"openapi": "3.1.0", "paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/X"}]}}}}`: "openapi: GET /a: parameter X is not defined",
+		`{"openapi": "3.1.0", "paths": {"/a": {"get": {"security": [{"auth": []}]}}}}`:                            "openapi: GET /a: security scheme auth is not defined",
+		`{"openapi": "3.1.0", "paths": {"/a": {"post": {"requestBody": {"$ref": "other.yaml#/components/x"}}}}}`:  "openapi: POST /a: reference other.yaml#/components/x is not supported",
+	} {
+		_, err := ImportOpenAPI([]byte(spec), OpenAPIOptions{})
+		if assert.Error(t, err, spec) {
+			assert.Equal(t, message, err.Error(), spec)
+		}
+	}
+}
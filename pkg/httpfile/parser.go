@@ -3,14 +3,16 @@ package httpfile
 import (
 	"bufio"
 	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/fantai/ftab/pkg/gql"
 	"github.com/fantai/ftab/pkg/mock"
 	"github.com/valyala/fasthttp"
 )
@@ -22,23 +24,51 @@ type Case struct {
 	RequestSize    int                // request body length
 	ResponseSize   int                // resoonse bytes length
 	RespTime       time.Duration      // response time
+	Redirects      int                // redirects followed
 	request        *fasthttp.Request  // the request object
 	response       *fasthttp.Response // the responsee object
 	parsedReqBody  interface{}        // parsed request body
 	parsedRespBody interface{}        // parsed response body
+	noCookieJar    bool               // don't use cookie jar, by # @no-cookie-jar
+	noRedirect     bool               // don't follow redirects, by # @no-redirect
+	digest         *digestCredential  // digest authentication credential
+	soap           *soapEnvelope      // send as SOAP request, by # @soap
+	extractors     []*extractor       // extract variables from response, by # @extract
+	paths          *pathCache         // compiled JSONPath of request variables refer this case
+	tpl            *requestTemplate   // compiled request, nil if case is not parsed
+	graphql        bool               // GraphQL case, by X-Request-Type: GraphQL
+	allowErrors    bool               // errors of GraphQL response don't fail case, by # @allow-graphql-errors
+	think          time.Duration      // wait before sending request, by # @think 1.5s
+	lines          []string           // comments, directives and variables (@name) of case in order, kept by WriteTo
+	source         *fasthttp.Request  // request before wrapped as GraphQL or SOAP, kept by WriteTo
+	text           []byte             // lines of body as written with comment lines, kept by WriteTo
+	curl           string             // curl command and headers following it as written, kept by WriteTo
+	stray          bool               // comment between headers, WriteTo can't keep it in place
+	empty          bool               // no request line in case, like the one before first ###
 }
 
 const (
 	parseFileStage = iota
+	parseCurlStage
 	parseHeaderStage
 	parseBodyStage
 )
 
 // HTTPFile is a .http or .rest file parse result
 type HTTPFile struct {
-	Variables map[string]string // variable in this file
-	Cases     []*Case           // all cases
-	AutoClean bool              // automatic release resource, otherwise caller should do Release after use, default is true
+	Variables    map[string]string // variable in this file
+	Cases        []*Case           // all cases
+	AutoClean    bool              // automatic release resource, otherwise caller should do Release after use, default is true
+	Jar          *CookieJar        // cookie jar shared by cases, a new one is used by Execute if nil
+	MaxRedirects int               // max redirects followed by a case, 0 is not follow
+	Env          *Environment      // environment from env file, variables in file have high priority
+	Namespaces   map[string]string // xml namespaces used by xpath, by # @namespace prefix=uri
+	Schema       *gql.Schema       // schema to validate GraphQL cases, nil is only checking syntax
+	AllowErrors  bool              // errors of GraphQL responses don't fail cases, default is false
+	NoThinkTime  bool              // don't wait think time of cases, default is false
+	extracted    map[string]string // variables extracted from responses by # @extract
+	mocked       []string          // variables mocked by Duplicate, they are mocked again by reset
+	renderer     expander          // expander of Execute, it's reused by iterations
 }
 
 // ###
@@ -47,8 +77,11 @@ var newCaseTag, _ = regexp.Compile(`^\s*###\s*$`)
 // # @name=value
 var nameTag, _ = regexp.Compile(`^\s*#\s+@name\s*=?\s*(\w+)\s*$`)
 
+// # @no-cookie-jar
+var directiveTag, _ = regexp.Compile(`^\s*(?:#|//)\s*@([\w-]+)\s*(.*?)\s*$`)
+
 // GET url
-var firstLineTag, _ = regexp.Compile(`^\s*(GET|POST)\s+(.+?)\s*$`)
+var firstLineTag, _ = regexp.Compile(`^\s*(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|TRACE|CONNECT)\s+(.+?)\s*$`)
 
 // @key=value
 var variableDefineTag, _ = regexp.Compile(`^\s*@([[:graph:]]+)\s*=\s*(.+?)\s*$`)
@@ -59,9 +92,6 @@ var headerDefineTag, _ = regexp.Compile(`^\s*([[:graph:]]+)\s*:\s*(.+?)\s*$`)
 // # this is comment
 var commentTag, _ = regexp.Compile(`^\s*(#|//).*$`)
 
-// {{abc}}
-var variableRef, _ = regexp.Compile(`{{.+?}}`)
-
 // DisaableAutoClean disable clear after Exectue
 func DisaableAutoClean(f *HTTPFile) {
 	f.AutoClean = false
@@ -70,48 +100,118 @@ func DisaableAutoClean(f *HTTPFile) {
 // Opt is option when parse HTTPFile
 type Opt func(f *HTTPFile)
 
+// AllowGraphQLErrors treat GraphQL response with errors as success
+func AllowGraphQLErrors(f *HTTPFile) {
+	f.AllowErrors = true
+}
+
+// IgnoreThinkTime send requests without waiting think time of cases
+func IgnoreThinkTime(f *HTTPFile) {
+	f.NoThinkTime = true
+}
+
+// WithGraphQLSchema validate queries of GraphQL cases against schema when parsing
+func WithGraphQLSchema(schema *gql.Schema) Opt {
+	return func(f *HTTPFile) {
+		f.Schema = schema
+	}
+}
+
 // ParseReader parse httpfile from a reader
 func ParseReader(r io.Reader, opts ...Opt) (*HTTPFile, error) {
 	s := bufio.NewScanner(r)
 
 	file := &HTTPFile{
-		Variables: make(map[string]string),
-		Cases:     make([]*Case, 0),
+		Variables:    make(map[string]string),
+		Cases:        make([]*Case, 0),
+		MaxRedirects: DefaultMaxRedirects,
 	}
 	for _, opt := range opts {
 		opt(file)
 	}
 
-	thisCase := &Case{
-		request: fasthttp.AcquireRequest(),
-	}
+	thisCase := newCase()
 	stage := parseFileStage
 
 	var groups [][]byte
+	var curl string
+	requested := false
 	for s.Scan() {
 		line := s.Bytes()
 
+		// request is written as curl command, lines are joined until command is complete
+		if curl != "" || (stage == parseFileStage && curlTag.Match(line)) {
+			if curl == "" {
+				curl = string(line)
+			} else {
+				curl += "\n" + string(line)
+			}
+			err := parseCurl(thisCase.request, curl)
+			if errors.Is(err, errIncompleteCommand) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("case %d: %w", len(file.Cases)+1, err)
+			}
+			thisCase.curl, curl = strings.TrimSpace(curl), ""
+			requested = true
+			// headers may follow curl command, body is given by curl only
+			stage = parseCurlStage
+			continue
+		}
+
 		if newCaseTag.Match(line) {
-			file.Cases = append(file.Cases, thisCase)
-			thisCase = &Case{
-				request: fasthttp.AcquireRequest(),
+			thisCase.empty = !requested
+			if err := thisCase.finish(file); err != nil {
+				return nil, fmt.Errorf("case %d: %w", len(file.Cases)+1, err)
 			}
+			file.Cases = append(file.Cases, thisCase)
+			thisCase = newCase()
 			stage = parseFileStage
+			requested = false
 			continue
 		}
 
 		groups = variableDefineTag.FindSubmatch(line)
 		if groups != nil {
 			file.Variables[string(groups[1])] = string(groups[2])
+			if !requested {
+				thisCase.lines = append(thisCase.lines, "@"+string(groups[1]))
+			}
 			continue
 		}
 
+		// comments after request line are kept where they are, directives are recorded
+		// and written before request line as they apply to the whole case
+		if commentTag.Match(line) {
+			switch {
+			case !requested || nameTag.Match(line) || directiveTag.Match(line):
+				thisCase.lines = append(thisCase.lines, string(bytes.TrimSpace(line)))
+			case stage == parseBodyStage:
+				thisCase.text = append(append(thisCase.text, line...), '\n')
+			case stage == parseCurlStage:
+				thisCase.curl += "\n" + string(bytes.TrimSpace(line))
+			default:
+				thisCase.stray = true
+			}
+		}
+
 		groups = nameTag.FindSubmatch(line)
 		if groups != nil {
 			thisCase.Name = string(groups[1])
 			continue
 		}
 
+		groups = directiveTag.FindSubmatch(line)
+		if groups != nil {
+			if string(groups[1]) == "namespace" {
+				file.declareNamespace(string(groups[2]))
+			} else if err := thisCase.setDirective(string(groups[1]), string(groups[2])); err != nil {
+				return nil, fmt.Errorf("case %d: %w", len(file.Cases)+1, err)
+			}
+			continue
+		}
+
 		if commentTag.Match(line) {
 			continue
 		}
@@ -120,7 +220,9 @@ func ParseReader(r io.Reader, opts ...Opt) (*HTTPFile, error) {
 		if groups != nil {
 			thisCase.request.Header.SetMethod(string(groups[1]))
 			thisCase.request.SetRequestURI(string(groups[2]))
-
+			requested = true
+			// headers follow request line, empty line start body
+			stage = parseHeaderStage
 			continue
 		}
 
@@ -128,7 +230,11 @@ func ParseReader(r io.Reader, opts ...Opt) (*HTTPFile, error) {
 			groups = headerDefineTag.FindSubmatch(line)
 			if groups != nil {
 				thisCase.request.Header.SetBytesKV(groups[1], groups[2])
-				stage = parseHeaderStage
+				if stage != parseCurlStage {
+					stage = parseHeaderStage
+				} else {
+					thisCase.curl += "\n" + string(bytes.TrimSpace(line))
+				}
 				continue
 			}
 
@@ -138,15 +244,85 @@ func ParseReader(r io.Reader, opts ...Opt) (*HTTPFile, error) {
 		}
 
 		if stage == parseBodyStage {
+			thisCase.text = append(append(thisCase.text, line...), '\n')
 			thisCase.request.AppendBody(line)
+			// line breaks of GraphQL are kept, query and variables are separated by empty line
+			if thisCase.isGraphQL() {
+				thisCase.request.AppendBody([]byte("\n"))
+			}
 		}
 	}
 
+	if curl != "" {
+		return nil, fmt.Errorf("case %d: curl: %w", len(file.Cases)+1, errIncompleteCommand)
+	}
+	thisCase.empty = !requested
+	if err := thisCase.finish(file); err != nil {
+		return nil, fmt.Errorf("case %d: %w", len(file.Cases)+1, err)
+	}
 	file.Cases = append(file.Cases, thisCase)
 
 	return file, nil
 }
 
+// newCase create a empty case
+func newCase() *Case {
+	return &Case{
+		request: fasthttp.AcquireRequest(),
+		paths:   &pathCache{},
+	}
+}
+
+// setDirective set case option by directive like # @no-cookie-jar
+func (c *Case) setDirective(name, value string) error {
+	switch name {
+	case "no-cookie-jar":
+		c.noCookieJar = true
+	case "no-redirect":
+		c.noRedirect = true
+	case "allow-graphql-errors":
+		c.allowErrors = true
+	case "think":
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid think time %q", value)
+		}
+		c.think = d
+	case "soap":
+		c.soap = parseSOAPDirective(value)
+	case "extract":
+		e, err := parseExtractor(value)
+		if err != nil {
+			return err
+		}
+		c.extractors = append(c.extractors, e)
+	}
+	return nil
+}
+
+// finish the case after all lines of it are parsed
+func (c *Case) finish(f *HTTPFile) error {
+	if c.soap != nil || c.isGraphQL() {
+		c.source = fasthttp.AcquireRequest()
+		c.request.CopyTo(c.source)
+	}
+	if c.soap != nil {
+		c.soap.wrap(c)
+	}
+	var body *template
+	if c.isGraphQL() {
+		var err error
+		if body, err = c.wrapGraphQL(f.Schema); err != nil {
+			return err
+		}
+	}
+	c.tpl = compileRequest(c.request)
+	if body != nil {
+		c.tpl.body = body
+	}
+	return nil
+}
+
 // ParseFile parse httpfile from a file
 func ParseFile(fileName string, opts ...Opt) (*HTTPFile, error) {
 	fp, err := os.Open(fileName)
@@ -163,35 +339,27 @@ func ParseBytes(content []byte, opts ...Opt) (*HTTPFile, error) {
 	return ParseReader(r, opts...)
 }
 
-// ReplaceVariable replace variable placeholder to value
-func ReplaceVariable(text []byte, ve Replacer) []byte {
-	replaced := variableRef.ReplaceAllFunc([]byte(text), func(key []byte) []byte {
-		rkey := bytes.TrimSpace(key[2 : len(key)-2])
-		val, ok := ve.Get(string(rkey))
-		if ok {
-			return []byte(val)
-		}
-		return key
-	})
-	return replaced
-}
-
-// ReplaceVariableString replace variable placeholder to value string
-func ReplaceVariableString(text string, ve Replacer) string {
-	return string(ReplaceVariable([]byte(text), ve))
-}
-
-// Duplicate this file, if mock is used, variable will be mocked
+// Duplicate this file, if mock is used, variable will be mocked, requests of parsed cases
+// are rendered from compiled templates by Execute, expand render them right now and
+// undefined variables are kept
 func (f *HTTPFile) Duplicate(useMock bool, expand bool) *HTTPFile {
 
 	result := HTTPFile{
-		Variables: make(map[string]string),
-		Cases:     make([]*Case, len(f.Cases)),
-		AutoClean: f.AutoClean,
+		Variables:    make(map[string]string),
+		Cases:        make([]*Case, len(f.Cases)),
+		AutoClean:    f.AutoClean,
+		Jar:          f.Jar,
+		MaxRedirects: f.MaxRedirects,
+		Env:          f.Env,
+		Namespaces:   f.Namespaces,
+		Schema:       f.Schema,
+		AllowErrors:  f.AllowErrors,
+		NoThinkTime:  f.NoThinkTime,
 	}
 	for key, val := range f.Variables {
-		if useMock {
+		if useMock && mock.Mockable(key, val) {
 			result.Variables[key] = mock.Value(key, val)
+			result.mocked = append(result.mocked, key)
 		} else {
 			result.Variables[key] = val
 		}
@@ -201,20 +369,63 @@ func (f *HTTPFile) Duplicate(useMock bool, expand bool) *HTTPFile {
 		from := f.Cases[i]
 
 		to.Name = from.Name
+		to.noCookieJar = from.noCookieJar
+		to.noRedirect = from.noRedirect
+		to.graphql = from.graphql
+		to.allowErrors = from.allowErrors
+		to.think = from.think
+		to.lines = from.lines
+		to.empty = from.empty
+		to.extractors = from.extractors
+		to.paths = from.paths
+		to.tpl = from.tpl
 		to.request = fasthttp.AcquireRequest()
-		from.request.CopyTo(to.request)
-
-		if expand {
-			to.request.Header.SetMethodBytes(ReplaceVariable(to.request.Header.Method(), f))
-			to.request.SetRequestURIBytes(ReplaceVariable(to.request.RequestURI(), f))
-			to.request.SetBody(ReplaceVariable(to.request.Body(), f))
+		if to.tpl == nil {
+			from.request.CopyTo(to.request)
 		}
 
 		result.Cases[i] = to
 	}
+	if expand {
+		// request variables are kept, they are resolved by Execute of the duplicated file
+		for _, to := range result.Cases {
+			if to.tpl != nil {
+				to.tpl.render(to.request, &expander{ve: &result})
+				continue
+			}
+			to.request.Header.SetMethodBytes(ReplaceVariable(to.request.Header.Method(), &result))
+			to.request.SetRequestURIBytes(ReplaceVariable(to.request.RequestURI(), &result))
+			to.request.SetBody(ReplaceVariable(to.request.Body(), &result))
+		}
+	}
 	return &result
 }
 
+// reset file duplicated from origin for next iteration, mocked variables are
+// mocked again, requests and responses are kept for reuse, so an iteration
+// without mocked variables don't allocate
+func (f *HTTPFile) reset(origin *HTTPFile) {
+	for _, key := range f.mocked {
+		f.Variables[key] = mock.Value(key, origin.Variables[key])
+	}
+	for key := range f.extracted {
+		delete(f.extracted, key)
+	}
+	for i, to := range f.Cases {
+		if to.tpl == nil {
+			origin.Cases[i].request.CopyTo(to.request)
+		} else {
+			to.request.Reset()
+		}
+		if to.response != nil {
+			to.response.Reset()
+		}
+		to.RespCode, to.RequestSize, to.ResponseSize, to.RespTime, to.Redirects = 0, 0, 0, 0, 0
+		to.parsedReqBody, to.parsedRespBody = nil, nil
+		to.digest = nil
+	}
+}
+
 // Release resource
 func (f *HTTPFile) Release() {
 	for _, c := range f.Cases {
@@ -224,15 +435,26 @@ func (f *HTTPFile) Release() {
 		if c.response != nil {
 			fasthttp.ReleaseResponse(c.response)
 		}
+		if c.source != nil {
+			fasthttp.ReleaseRequest(c.source)
+		}
 		c.request = nil
 		c.response = nil
+		c.source = nil
 	}
 }
 
 // Execute the httfile
 func (f *HTTPFile) Execute(client *fasthttp.Client, ve ...Replacer) error {
-	lists := append(make(ListReplacer, 0), ve...)
-	lists = append(lists, f)
+	var replacer Replacer = f
+	if len(ve) > 0 {
+		replacer = append(append(make(ListReplacer, 0), ve...), f)
+	}
+
+	jar := f.Jar
+	if jar == nil {
+		jar = NewCookieJar()
+	}
 
 	defer func() {
 		if f.AutoClean {
@@ -240,17 +462,25 @@ func (f *HTTPFile) Execute(client *fasthttp.Client, ve ...Replacer) error {
 		}
 	}()
 
-	for _, to := range f.Cases {
+	for i, to := range f.Cases {
 
-		to.request.Header.SetMethodBytes(ReplaceVariable(to.request.Header.Method(), lists))
-		to.request.SetRequestURIBytes(ReplaceVariable(to.request.RequestURI(), lists))
-		to.request.SetBody(ReplaceVariable(to.request.Body(), lists))
+		if to.think > 0 && !f.NoThinkTime {
+			time.Sleep(to.think)
+		}
+		if err := f.prepare(i, to, replacer); err != nil {
+			return err
+		}
 
-		to.request.Header.VisitAll(func(key, val []byte) {
-			to.request.Header.SetBytesKV(key, ReplaceVariable(val, lists))
-		})
+		if !to.noCookieJar {
+			jar.apply(to.request)
+		}
 
-		to.response = fasthttp.AcquireResponse()
+		if to.response == nil {
+			to.response = fasthttp.AcquireResponse()
+		} else {
+			to.response.Reset()
+		}
+		to.parsedReqBody, to.parsedRespBody = nil, nil
 
 		t1 := time.Now()
 		err := client.Do(to.request, to.response)
@@ -259,40 +489,181 @@ func (f *HTTPFile) Execute(client *fasthttp.Client, ve ...Replacer) error {
 		}
 		t2 := time.Now()
 
+		if !to.noCookieJar {
+			jar.update(to.request, to.response)
+		}
+
 		// will keep valid after Execute
-		to.RespCode = to.response.StatusCode()
 		to.RespTime = t2.Sub(t1)
 		to.RequestSize = len(to.request.Header.Header()) + len(to.request.Body()) + len(to.request.RequestURI())
 		to.ResponseSize = len(to.response.Header.Header()) + len(to.response.Body())
-		if to.RespCode != 200 {
+
+		err = f.digestAuth(client, to, jar)
+		if err == nil {
+			err = f.followRedirects(client, to, jar)
+		}
+		to.RespCode = to.response.StatusCode()
+		if err != nil {
+			return err
+		}
+		f.extract(to)
+		// redirect is expected when not follow it
+		if to.RespCode != 200 && !(!f.followRedirect(to) && isRedirect(to.RespCode)) {
+			// GraphQL over HTTP may response errors with 4xx, they are classified too
+			if to.graphql {
+				if err := to.checkGraphQL(i); err != nil {
+					return err
+				}
+			}
 			return fmt.Errorf("response is not 200")
 		}
+		if to.graphql && !to.allowErrors && !f.AllowErrors {
+			if err := to.checkGraphQL(i); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// prepare render request of case, then evaluate signature functions kept by render
+// and authorization helper, they are skipped if case has none
+func (f *HTTPFile) prepare(index int, to *Case, ve Replacer) error {
+	e := &f.renderer
+	if e.templates == nil {
+		e.templates = make(map[string]*template)
 	}
+	e.ve, e.strict, e.stack, e.kept = ve, true, e.stack[:0], 0
 
+	if err := to.render(e); err != nil {
+		return fmt.Errorf("case %s: %w", to.label(index), err)
+	}
+	if e.kept > 0 || to.tpl == nil {
+		if err := to.sign(ve); err != nil {
+			return fmt.Errorf("case %s: %w", to.label(index), err)
+		}
+	}
+	to.authorize()
 	return nil
 }
 
+// label is name of case in errors, unnamed case is labeled by its number from 1
+func (c *Case) label(index int) string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return strconv.Itoa(index + 1)
+}
+
+// render request of case from compiled template, case not parsed is expanded
+func (c *Case) render(e *expander) error {
+	if c.tpl == nil {
+		return c.expand(e)
+	}
+	return c.tpl.render(c.request, e)
+}
+
+// expand variables in method, uri, body and headers of request
+func (c *Case) expand(e *expander) error {
+	method, err := e.expand(c.request.Header.Method())
+	if err != nil {
+		return err
+	}
+	uri, err := e.expand(c.request.RequestURI())
+	if err != nil {
+		return err
+	}
+	body, err := e.expand(c.request.Body())
+	if err != nil {
+		return err
+	}
+
+	var keys, values [][]byte
+	c.request.Header.VisitAll(func(key, val []byte) {
+		if err != nil {
+			return
+		}
+		var expanded []byte
+		expanded, err = e.expand(val)
+		keys = append(keys, append([]byte(nil), key...))
+		values = append(values, expanded)
+	})
+	if err != nil {
+		return err
+	}
+
+	c.request.Header.SetMethodBytes(method)
+	c.request.SetRequestURIBytes(uri)
+	c.request.SetBody(body)
+	for i := range keys {
+		c.request.Header.SetBytesKV(keys[i], values[i])
+	}
+	return nil
+}
+
+// IsTemplate is required by TemplateReplacer interface, variables defined
+// in file and environment are templates
+func (f *HTTPFile) IsTemplate(key string) bool {
+	if _, ok := f.extracted[key]; ok {
+		return false
+	}
+	if _, ok := f.Variables[key]; ok {
+		return true
+	}
+	if f.Env != nil {
+		_, ok := f.Env.Get(key)
+		return ok
+	}
+	return false
+}
+
 // Get is required by ValueExtractor interface
 func (f *HTTPFile) Get(key string) (string, bool) {
+	// variable extracted from response, override the defined one
+	if val, ok := f.extracted[key]; ok {
+		return val, ok
+	}
+
 	// variable with defined name
 	val, ok := f.Variables[key]
 	if ok {
 		return val, ok
 	}
 
+	// variable of environment
+	if f.Env != nil {
+		if val, ok := f.Env.Get(key); ok {
+			return val, ok
+		}
+	}
+
 	// variable buildin
 	if key[0] == '$' {
 		return f.getBuildinVariable(key)
 	}
 
-	// header variable
-	if strings.Index(key, ".header.") > 0 {
-		return f.getHeaderVariable(key)
+	// cookie variable
+	if args := strings.SplitN(key, ".", 4); len(args) == 4 && args[2] == "cookie" {
+		return f.getCookieVariable(key)
+	}
+
+	// request variable
+	if rv, ok := parseRequestVariable(key); ok {
+		return f.getRequestVariable(rv)
 	}
+	return val, ok
+}
 
-	if strings.Index(key, ".body.") > 0 {
-		return f.getJSONPathVariable(key)
+// GetValue is required by TypedReplacer interface, request variables selected by
+// JSONPath are json values, others are string
+func (f *HTTPFile) GetValue(key string) (interface{}, bool) {
+	if _, ok := f.extracted[key]; !ok && !f.IsTemplate(key) {
+		if rv, ok := parseRequestVariable(key); ok {
+			return f.requestValue(rv)
+		}
 	}
+	val, ok := f.Get(key)
 	return val, ok
 }
 
@@ -311,77 +682,10 @@ func (f *HTTPFile) getBuildinVariable(key string) (string, bool) {
 		return funLocalDateTime(funcVar), true
 	case "$randomFromFile":
 		return funRandomFromFile(funcVar), true
-	}
-	return "", false
-}
-
-func (f *HTTPFile) getHeaderVariable(key string) (string, bool) {
-	args := strings.Split(key, ".")
-	if len(args) == 4 {
-		caseName, kind, name := args[0], args[1], args[3]
-		theCase := f.findCaseByName(caseName)
-		if theCase != nil {
-			switch kind {
-			case "request":
-				val := theCase.request.Header.Peek(name)
-				if val != nil {
-					return string(val), false
-				}
-			case "response":
-				if theCase.response != nil {
-					val := theCase.response.Header.Peek(name)
-					if val != nil {
-						return string(val), false
-					}
-				}
-			}
-		}
-	}
-	return "", false
-}
-
-func (f *HTTPFile) getJSONPathVariable(key string) (string, bool) {
-	pos := strings.Index(key, ".body.")
-	if pos < 0 {
-		return "", false
-	}
-	pos += 6
-
-	args := strings.Split(key[0:pos], ".")
-	if len(args) < 3 {
-		return "", false
-	}
-
-	caseName, kind := args[0], args[1]
-	theCase := f.findCaseByName(caseName)
-	if theCase == nil {
-		return "", false
-	}
-
-	path := key[pos:]
-
-	switch kind {
-	case "request":
-		if theCase.parsedReqBody == nil {
-			json.Unmarshal(theCase.request.Body(), &theCase.parsedReqBody)
-		}
-		return JSONPathGet(theCase.parsedReqBody, path), true
-	case "response":
-		if theCase.response != nil {
-			var body []byte
-			switch string(theCase.response.Header.Peek("Content-Encoding")) {
-			case "gzip":
-				body, _ = theCase.response.BodyGunzip()
-			case "deflate":
-				body, _ = theCase.response.BodyInflate()
-			default:
-				body = theCase.response.Body()
-			}
-			if theCase.parsedRespBody == nil {
-				json.Unmarshal(body, &theCase.parsedRespBody)
-			}
-			return JSONPathGet(theCase.parsedRespBody, path), true
-		}
+	case "$mock":
+		return funMock(funcVar), true
+	case "$oauth2":
+		return funOAuth2(f.Env, funcVar)
 	}
 	return "", false
 }
@@ -3,20 +3,27 @@ package httpfile
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/fantai/ftab/internal/echo"
 	"github.com/stretchr/testify/assert"
 	"github.com/valyala/fasthttp"
 )
 
-const echoServer = "http://127.0.0.1:6601/"
+const echoHost = "127.0.0.1:6611"
+const echoServer = "http://" + echoHost + "/"
 
 func TestMain(m *testing.M) {
-	//wg := &sync.WaitGroup{}
-	//wg.Add(1)
-	//go echo.Start(host, wg)
-	//time.Sleep(time.Second)
-	m.Run()
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	go echo.Start(echoHost, wg)
+	time.Sleep(time.Second)
+	code := m.Run()
+	wg.Done()
+	os.Exit(code)
 }
 func TestParse(t *testing.T) {
 	content := `
@@ -0,0 +1,674 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// postmanSchema is schema url of Postman collection v2.1
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// postmanCollection is Postman collection v2.1, only fields used by ftab are declared
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []*postmanItem    `json:"item"`
+	Event    []*postmanEvent   `json:"event,omitempty"`
+	Variable []postmanKeyValue `json:"variable,omitempty"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+}
+
+type postmanInfo struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// postmanItem is a folder if it has items, otherwise it's a request
+type postmanItem struct {
+	Name    string          `json:"name"`
+	Item    []*postmanItem  `json:"item,omitempty"`
+	Request *postmanRequest `json:"request,omitempty"`
+	Event   []*postmanEvent `json:"event,omitempty"`
+	Auth    *postmanAuth    `json:"auth,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body,omitempty"`
+	URL    postmanURL        `json:"url"`
+	Auth   *postmanAuth      `json:"auth,omitempty"`
+}
+
+type postmanKeyValue struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type,omitempty"`
+	Src      interface{} `json:"src,omitempty"`
+	Disabled bool        `json:"disabled,omitempty"`
+}
+
+// text is value of key value as string, value may be a number or boolean
+func (kv *postmanKeyValue) text() string {
+	switch v := kv.Value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, _ := json.Marshal(kv.Value)
+	return string(data)
+}
+
+// postmanURL is raw url, it may be a string or an object in collection
+type postmanURL struct {
+	Raw string `json:"raw"`
+}
+
+// MarshalJSON write url as a string, Postman parse it when importing
+func (u postmanURL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Raw)
+}
+
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), `"`) {
+		return json.Unmarshal(data, &u.Raw)
+	}
+	var obj struct {
+		Raw string `json:"raw"`
+	}
+	err := json.Unmarshal(data, &obj)
+	u.Raw = obj.Raw
+	return err
+}
+
+type postmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []postmanKeyValue   `json:"urlencoded,omitempty"`
+	FormData   []postmanKeyValue   `json:"formdata,omitempty"`
+	GraphQL    *postmanGraphQL     `json:"graphql,omitempty"`
+	Options    *postmanBodyOptions `json:"options,omitempty"`
+}
+
+type postmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type postmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// postmanAuth is auth of request, attributes are in list named by type like basic
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Basic  []postmanKeyValue `json:"basic,omitempty"`
+	Digest []postmanKeyValue `json:"digest,omitempty"`
+	Bearer []postmanKeyValue `json:"bearer,omitempty"`
+	APIKey []postmanKeyValue `json:"apikey,omitempty"`
+	AWSv4  []postmanKeyValue `json:"awsv4,omitempty"`
+}
+
+type postmanEvent struct {
+	Listen string        `json:"listen"`
+	Script postmanScript `json:"script"`
+}
+
+// postmanScript is script of event, exec may be a string or lines in collection
+type postmanScript struct {
+	Type string      `json:"type"`
+	Exec postmanExec `json:"exec"`
+}
+
+type postmanExec []string
+
+func (e *postmanExec) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(strings.TrimSpace(string(data)), `"`) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*e = strings.Split(text, "\n")
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(e))
+}
+
+// PostmanFile is a http file imported from requests of a folder of Postman collection
+type PostmanFile struct {
+	Path string    // path of http file, folders are directories
+	File *HTTPFile // requests of the folder
+}
+
+// PostmanImport is http files and env file imported from Postman collection
+type PostmanImport struct {
+	Files    []*PostmanFile               // http files of collection and folders having requests
+	Env      map[string]map[string]string // env file, collection variables are $shared
+	Warnings []string                     // scripts and features not converted
+}
+
+// postmanEnvironment is environment exported by Postman
+type postmanEnvironment struct {
+	Name   string `json:"name"`
+	Values []struct {
+		Key     string `json:"key"`
+		Value   string `json:"value"`
+		Enabled *bool  `json:"enabled"`
+	} `json:"values"`
+}
+
+// postmanScope is inherited by requests of folder, variables are set by pre-request
+// scripts of collection and folders
+type postmanScope struct {
+	path      []string
+	auth      *postmanAuth
+	variables map[string]string
+}
+
+// postmanImporter convert folders to http files
+type postmanImporter struct {
+	result *PostmanImport
+	paths  map[string]int
+	names  map[string]int // names of cases in current file
+}
+
+// postmanSetTag match variable set statement of script like pm.environment.set("name", value)
+var postmanSetTag, _ = regexp.Compile(`^(?:pm\.(?:environment|collectionVariables|globals|variables)\.set|postman\.set(?:Environment|Global)Variable)\(\s*["']([^"']+)["']\s*,\s*(.+?)\s*\)\s*;?$`)
+
+// pm.environment.get("name")
+var postmanGetTag, _ = regexp.Compile(`^pm\.(?:environment|collectionVariables|globals|variables)\.get\(\s*["']([^"']+)["']\s*\)$`)
+
+// pm.response.headers.get("name")
+var postmanHeaderTag, _ = regexp.Compile(`^pm\.response\.headers\.get\(\s*["']([^"']+)["']\s*\)$`)
+
+// .a[0]["b-c"] after pm.response.json()
+var postmanAccessorTag, _ = regexp.Compile(`^((\.[A-Za-z_$][\w$]*)|(\[\d+\])|(\["[^"\\]*"\])|(\['[^'\\]*'\]))*$`)
+
+// postmanSystem map Postman dynamic variables to system variables
+var postmanSystem = map[string]string{
+	"$timestamp":    "$timestamp",
+	"$isoTimestamp": "$datetime iso8601",
+	"$randomInt":    "$randomInt 0 1001",
+}
+
+// ImportPostman convert Postman collection v2.1 and environments to http files and env file,
+// requests of collection and each folder are a http file, collection variables and
+// environments are in env file, variables set by pre-request scripts are variables of
+// http files, variables set by test scripts from response are request variables
+func ImportPostman(collection []byte, environments ...[]byte) (*PostmanImport, error) {
+	var pc postmanCollection
+	if err := json.Unmarshal(collection, &pc); err != nil {
+		return nil, fmt.Errorf("postman: %w", err)
+	}
+	if pc.Info.Schema != "" && !strings.Contains(pc.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("postman: schema %s is not supported, collection v2.1 is expected", pc.Info.Schema)
+	}
+
+	im := &postmanImporter{
+		result: &PostmanImport{Env: make(map[string]map[string]string)},
+		paths:  make(map[string]int),
+	}
+	shared := make(map[string]string)
+	for _, v := range pc.Variable {
+		if !v.Disabled {
+			shared[v.Key] = im.text(pc.Info.Name, v.text())
+		}
+	}
+	if len(shared) > 0 {
+		im.result.Env["$shared"] = shared
+	}
+	for i, data := range environments {
+		var env postmanEnvironment
+		if err := json.Unmarshal(data, &env); err != nil {
+			return nil, fmt.Errorf("postman: environment %d: %w", i+1, err)
+		}
+		vars := make(map[string]string)
+		for _, v := range env.Values {
+			if v.Enabled == nil || *v.Enabled {
+				vars[v.Key] = im.text(env.Name, v.Value)
+			}
+		}
+		im.result.Env[env.Name] = vars
+	}
+
+	root := &postmanScope{auth: pc.Auth, variables: make(map[string]string)}
+	im.scripts(pc.Info.Name, pc.Event, "", root.variables)
+	im.folder(pc.Info.Name, pc.Item, root)
+	return im.result, nil
+}
+
+// folder convert requests of folder to a http file, sub folders are converted after it
+func (im *postmanImporter) folder(name string, items []*postmanItem, scope *postmanScope) {
+	file := &HTTPFile{
+		Variables:    make(map[string]string),
+		Cases:        make([]*Case, 0),
+		MaxRedirects: DefaultMaxRedirects,
+	}
+	for k, v := range scope.variables {
+		file.Variables[k] = v
+	}
+	im.names = make(map[string]int)
+
+	folders := make([]*postmanItem, 0)
+	for _, item := range items {
+		if item.Request == nil {
+			folders = append(folders, item)
+			continue
+		}
+		im.request(item, scope, file)
+	}
+	if len(file.Cases) > 0 {
+		im.result.Files = append(im.result.Files, &PostmanFile{Path: im.filePath(name, scope.path), File: file})
+	}
+
+	for _, item := range folders {
+		child := &postmanScope{
+			path:      append(append([]string(nil), scope.path...), item.Name),
+			auth:      scope.auth,
+			variables: make(map[string]string),
+		}
+		if item.Auth != nil && item.Auth.Type != "inherit" {
+			child.auth = item.Auth
+		}
+		for k, v := range scope.variables {
+			child.variables[k] = v
+		}
+		im.scripts(strings.Join(child.path, " / "), item.Event, "", child.variables)
+		im.folder(name, item.Item, child)
+	}
+}
+
+// filePath is path of http file of folder, it's unique in import
+func (im *postmanImporter) filePath(collection string, folders []string) string {
+	if len(folders) == 0 {
+		folders = []string{collection}
+	}
+	segments := make([]string, len(folders))
+	for i, folder := range folders {
+		if segments[i] = strings.Trim(nonWordTag.ReplaceAllString(folder, "_"), "_"); segments[i] == "" {
+			segments[i] = "folder"
+		}
+	}
+	path := strings.Join(segments, "/")
+	im.paths[path]++
+	if n := im.paths[path]; n > 1 {
+		path += "_" + strconv.Itoa(n)
+	}
+	return path + ".http"
+}
+
+// request convert request item to a named case of file
+func (im *postmanImporter) request(item *postmanItem, scope *postmanScope, file *HTTPFile) {
+	where := strings.Join(append(append([]string(nil), scope.path...), item.Name), " / ")
+	c := newCase()
+	c.Name = strings.Trim(nonWordTag.ReplaceAllString(item.Name, "_"), "_")
+	if c.Name == "" {
+		c.Name = "request"
+	}
+	im.names[c.Name]++
+	if n := im.names[c.Name]; n > 1 {
+		c.Name += "_" + strconv.Itoa(n)
+	}
+
+	pr, req := item.Request, c.request
+	method := strings.ToUpper(pr.Method)
+	if method == "" {
+		method = fasthttp.MethodGet
+	}
+	req.Header.SetMethod(method)
+	uri := im.text(where, pr.URL.Raw)
+	if !strings.Contains(uri, "://") && !strings.HasPrefix(uri, "{{") {
+		uri = "http://" + uri
+	}
+	req.SetRequestURI(uri)
+	for _, h := range pr.Header {
+		if !h.Disabled {
+			req.Header.Set(h.Key, im.text(where, h.text()))
+		}
+	}
+
+	auth := scope.auth
+	if pr.Auth != nil && pr.Auth.Type != "inherit" {
+		auth = pr.Auth
+	}
+	im.auth(where, req, auth)
+	if pr.Body != nil {
+		im.body(where, req, pr.Body)
+	}
+
+	im.scripts(where, item.Event, c.Name, file.Variables)
+	file.Cases = append(file.Cases, c)
+}
+
+// auth set credential of Postman auth to Authorization helper or api key
+func (im *postmanImporter) auth(where string, req *fasthttp.Request, auth *postmanAuth) {
+	if auth == nil {
+		return
+	}
+	attribute := func(attributes []postmanKeyValue, key string) string {
+		for _, kv := range attributes {
+			if kv.Key == key {
+				return im.text(where, kv.text())
+			}
+		}
+		return ""
+	}
+
+	switch auth.Type {
+	case "noauth":
+	case "basic":
+		req.Header.Set("Authorization", "Basic "+attribute(auth.Basic, "username")+":"+attribute(auth.Basic, "password"))
+	case "digest":
+		req.Header.Set("Authorization", "Digest "+attribute(auth.Digest, "username")+" "+attribute(auth.Digest, "password"))
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+attribute(auth.Bearer, "token"))
+	case "apikey":
+		key, value := attribute(auth.APIKey, "key"), attribute(auth.APIKey, "value")
+		if attribute(auth.APIKey, "in") == "query" {
+			uri, sep := string(req.RequestURI()), "?"
+			if strings.Contains(uri, "?") {
+				sep = "&"
+			}
+			req.SetRequestURI(uri + sep + queryEscape(key) + "=" + queryEscape(value))
+		} else {
+			req.Header.Set(key, value)
+		}
+	case "awsv4":
+		value := "AWS " + attribute(auth.AWSv4, "accessKey") + " " + attribute(auth.AWSv4, "secretKey")
+		for _, option := range [][2]string{{"token", "sessionToken"}, {"region", "region"}, {"service", "service"}} {
+			if v := attribute(auth.AWSv4, option[1]); v != "" {
+				value += " " + option[0] + ":" + v
+			}
+		}
+		req.Header.Set("Authorization", value)
+	default:
+		im.warn("%s: auth %s is not supported", where, auth.Type)
+	}
+}
+
+// body set request body by mode, content type is set if it's not given by headers
+func (im *postmanImporter) body(where string, req *fasthttp.Request, body *postmanBody) {
+	contentType := ""
+	switch body.Mode {
+	case "", "none":
+	case "raw":
+		req.SetBodyString(im.text(where, body.Raw))
+		if body.Options != nil {
+			contentType = map[string]string{
+				"json": "application/json", "xml": "application/xml", "html": "text/html",
+				"text": "text/plain", "javascript": "application/javascript",
+			}[body.Options.Raw.Language]
+		}
+	case "urlencoded":
+		form := make([]string, 0, len(body.URLEncoded))
+		for _, kv := range body.URLEncoded {
+			if !kv.Disabled {
+				form = append(form, queryEscape(im.text(where, kv.Key))+"="+queryEscape(im.text(where, kv.text())))
+			}
+		}
+		req.SetBodyString(strings.Join(form, "&"))
+		contentType = "application/x-www-form-urlencoded"
+	case "formdata":
+		buff := bytes.NewBuffer(nil)
+		w := multipart.NewWriter(buff)
+		w.SetBoundary(formBoundary)
+		for _, kv := range body.FormData {
+			if kv.Disabled {
+				continue
+			}
+			if kv.Type == "file" {
+				im.warn("%s: file field %s of form is not supported", where, kv.Key)
+				continue
+			}
+			w.WriteField(im.text(where, kv.Key), im.text(where, kv.text()))
+		}
+		w.Close()
+		req.SetBody(buff.Bytes())
+		contentType = w.FormDataContentType()
+	case "graphql":
+		if body.GraphQL == nil {
+			return
+		}
+		text := im.text(where, body.GraphQL.Query)
+		if variables := strings.TrimSpace(body.GraphQL.Variables); variables != "" {
+			text += "\n\n" + im.text(where, variables)
+		}
+		req.SetBodyString(text)
+		req.Header.Set(requestTypeHeader, GraphQLRequestType)
+	default:
+		im.warn("%s: body mode %s is not supported", where, body.Mode)
+	}
+	if contentType != "" && len(req.Header.ContentType()) == 0 {
+		req.Header.SetContentType(contentType)
+	}
+}
+
+// queryEscape escape text of form except placeholders
+func queryEscape(text string) string {
+	var buff strings.Builder
+	for _, seg := range compile([]byte(text)).segments {
+		if seg.inner == nil {
+			buff.WriteString(url.QueryEscape(string(seg.text)))
+		} else {
+			buff.WriteString("{{" + string(seg.text) + "}}")
+		}
+	}
+	return buff.String()
+}
+
+// text convert dynamic variables of Postman to system variables
+func (im *postmanImporter) text(where, text string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	var buff strings.Builder
+	for _, seg := range compile([]byte(text)).segments {
+		if seg.inner == nil {
+			buff.Write(seg.text)
+			continue
+		}
+		key := strings.TrimSpace(string(seg.text))
+		if system, ok := postmanSystem[key]; ok {
+			key = system
+		} else if strings.HasPrefix(key, "$") {
+			im.warn("%s: dynamic variable %s is not supported", where, key)
+		}
+		buff.WriteString("{{" + key + "}}")
+	}
+	return buff.String()
+}
+
+// scripts set variables by set statements of pre-request scripts, and test scripts
+// of named case, other statements are not supported
+func (im *postmanImporter) scripts(where string, events []*postmanEvent, caseName string, variables map[string]string) {
+	for _, event := range events {
+		if event.Listen != "prerequest" && event.Listen != "test" {
+			continue
+		}
+		response := ""
+		if event.Listen == "test" {
+			response = caseName
+		}
+
+		unsupported, first := 0, ""
+		for _, line := range event.Script.Exec {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "//") || strings.HasPrefix(line, "console.log(") {
+				continue
+			}
+			value, ok := "", false
+			groups := postmanSetTag.FindStringSubmatch(line)
+			if groups != nil {
+				value, ok = im.expression(where, groups[2], response)
+			}
+			if !ok {
+				if unsupported++; unsupported == 1 {
+					first = line
+				}
+				continue
+			}
+			if old, ok := variables[groups[1]]; ok && old != value {
+				im.warn("%s: variable %s is set to different values, %s is used", where, groups[1], value)
+			}
+			variables[groups[1]] = value
+		}
+		if unsupported > 0 {
+			im.warn("%s: %d lines of %s script are not supported, the first is %s", where, unsupported, event.Listen, first)
+		}
+	}
+}
+
+// expression convert javascript expression of value to text with placeholders, it's
+// concatenation of literals, variables, timestamps and values of response of the case
+func (im *postmanImporter) expression(where, expr, response string) (string, bool) {
+	terms := splitConcat(expr)
+	if len(terms) == 1 && response != "" && strings.HasPrefix(terms[0], "JSON.stringify(") && strings.HasSuffix(terms[0], ")") {
+		ref, ok := responseValue(strings.TrimSpace(terms[0][len("JSON.stringify("):len(terms[0])-1]), response)
+		return "{{json " + ref + "}}", ok
+	}
+
+	var buff strings.Builder
+	for _, term := range terms {
+		if text, ok := jsString(term); ok {
+			buff.WriteString(im.text(where, text))
+			continue
+		}
+		if groups := postmanGetTag.FindStringSubmatch(term); groups != nil {
+			buff.WriteString("{{" + groups[1] + "}}")
+			continue
+		}
+		if strings.HasPrefix(term, "pm.variables.replaceIn(") && strings.HasSuffix(term, ")") {
+			text, ok := jsString(strings.TrimSpace(term[len("pm.variables.replaceIn(") : len(term)-1]))
+			if !ok {
+				return "", false
+			}
+			buff.WriteString(im.text(where, text))
+			continue
+		}
+		switch term {
+		case "true", "false", "null":
+			buff.WriteString(term)
+			continue
+		case "Date.now()", "new Date().getTime()":
+			buff.WriteString("{{$timestampms}}")
+			continue
+		case "new Date().toISOString()":
+			buff.WriteString("{{$datetime iso8601}}")
+			continue
+		}
+		if _, err := strconv.ParseFloat(term, 64); err == nil {
+			buff.WriteString(term)
+			continue
+		}
+		if response == "" {
+			return "", false
+		}
+		ref, ok := responseValue(term, response)
+		if !ok {
+			return "", false
+		}
+		buff.WriteString("{{" + ref + "}}")
+	}
+	return buff.String(), true
+}
+
+// responseValue convert javascript expression of response value to request variable
+func responseValue(expr, caseName string) (string, bool) {
+	if groups := postmanHeaderTag.FindStringSubmatch(expr); groups != nil {
+		return caseName + ".response.headers." + groups[1], true
+	}
+	switch expr {
+	case "pm.response.text()", "responseBody":
+		return caseName + ".response.body.*", true
+	}
+	for _, prefix := range []string{"pm.response.json()", "JSON.parse(responseBody)"} {
+		if !strings.HasPrefix(expr, prefix) {
+			continue
+		}
+		accessor := expr[len(prefix):]
+		if accessor == "" {
+			return caseName + ".response.body.*", true
+		}
+		if !postmanAccessorTag.MatchString(accessor) {
+			return "", false
+		}
+		var path strings.Builder
+		path.WriteString("$")
+		for i := 0; i < len(accessor); i++ {
+			if accessor[i] == '"' {
+				path.WriteByte('\'')
+			} else {
+				path.WriteByte(accessor[i])
+			}
+		}
+		return caseName + ".response.body." + path.String(), true
+	}
+	return "", false
+}
+
+// splitConcat split expression by + out of quotes and brackets
+func splitConcat(expr string) []string {
+	terms := make([]string, 0)
+	depth, quote, start := 0, byte(0), 0
+	for i := 0; i < len(expr); i++ {
+		ch := expr[i]
+		switch {
+		case quote != 0:
+			if ch == '\\' {
+				i++
+			} else if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'' || ch == '`':
+			quote = ch
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+		case ch == ')' || ch == ']' || ch == '}':
+			depth--
+		case ch == '+' && depth == 0:
+			terms = append(terms, strings.TrimSpace(expr[start:i]))
+			start = i + 1
+		}
+	}
+	return append(terms, strings.TrimSpace(expr[start:]))
+}
+
+// jsString unquote javascript string literal, template literal with ${} is not a string
+func jsString(term string) (string, bool) {
+	if len(term) < 2 || term[0] != term[len(term)-1] {
+		return "", false
+	}
+	inner := term[1 : len(term)-1]
+	switch term[0] {
+	case '`':
+		return inner, !strings.ContainsAny(inner, "`") && !strings.Contains(inner, "${")
+	case '\'':
+		inner = strings.ReplaceAll(strings.ReplaceAll(inner, `\'`, `'`), `"`, `\"`)
+	case '"':
+	default:
+		return "", false
+	}
+	text, err := strconv.Unquote(`"` + inner + `"`)
+	return text, err == nil
+}
+
+// warn once for each message
+func (im *postmanImporter) warn(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	for _, w := range im.result.Warnings {
+		if w == message {
+			return
+		}
+	}
+	im.result.Warnings = append(im.result.Warnings, message)
+}
@@ -0,0 +1,147 @@
+package httpfile
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const partnerCollection = `{
+	"info": {"name": "Partner API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+	"auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+	"variable": [{"key": "baseUrl", "value": "https://api.example.com"}, {"key": "retries", "value": 3}, {"key": "off", "value": "x", "disabled": true}],
+	"event": [{"listen": "prerequest", "script": {"type": "text/javascript", "exec": ["pm.collectionVariables.set('version', 'v1');"]}}],
+	"item": [
+		{"name": "Login", "request": {"method": "POST", "auth": {"type": "noauth"},
+			"header": [{"key": "X-Old", "value": "1", "disabled": true}],
+			"body": {"mode": "urlencoded", "urlencoded": [{"key": "user", "value": "{{user}}"}, {"key": "pass", "value": "a b"}]},
+			"url": {"raw": "{{baseUrl}}/login", "host": ["{{baseUrl}}"]}},
+		 "event": [{"listen": "test", "script": {"exec": [
+			"pm.test('ok', function () {",
+			"  pm.response.to.have.status(200);",
+			"});",
+			"pm.environment.set(\"token\", pm.response.json().data[\"access-token\"]);",
+			"pm.environment.set(\"session\", pm.response.headers.get('X-Session'));"
+		 ]}}]},
+		{"name": "Users", "auth": {"type": "basic", "basic": [{"key": "username", "value": "admin"}, {"key": "password", "value": "{{password}}"}]},
+		 "event": [{"listen": "prerequest", "script": {"exec": "pm.variables.set(\"page\", 1);\nif (x) { y(); }"}}],
+		 "item": [
+			{"name": "list users", "event": [{"listen": "prerequest", "script": {"exec": [
+				"// request id",
+				"pm.environment.set(\"rid\", 'r-' + Date.now() + pm.variables.get(\"page\"));",
+				"pm.globals.set(\"id\", {{$guid}});"
+			 ]}}],
+			 "request": {"method": "GET", "url": "{{baseUrl}}/{{version}}/users?page={{page}}&n={{$randomInt}}",
+				"header": [{"key": "X-Request-Id", "value": "{{rid}}"}]}},
+			{"name": "create user", "request": {"method": "POST", "auth": {"type": "inherit"},
+				"body": {"mode": "raw", "raw": "{\"name\": \"{{$randomFirstName}}\"}", "options": {"raw": {"language": "json"}}},
+				"url": "{{baseUrl}}/users"}},
+			{"name": "Admin", "item": [
+				{"name": "avatar", "request": {"method": "PUT", "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "k"}, {"key": "value", "value": "{{key}}"}, {"key": "in", "value": "query"}]},
+					"body": {"mode": "formdata", "formdata": [{"key": "name", "value": "me"}, {"key": "file", "type": "file", "src": "a.png"}]},
+					"url": "{{baseUrl}}/avatar"}}
+			]}
+		]},
+		{"name": "query", "request": {"method": "POST", "auth": {"type": "oauth2"},
+			"body": {"mode": "graphql", "graphql": {"query": "{ me { id } }", "variables": ""}},
+			"url": "localhost:4000/graphql"}}
+	]
+}`
+
+const partnerEnvironment = `{"name": "staging", "values": [
+	{"key": "user", "value": "tester", "enabled": true},
+	{"key": "password", "value": "secret"},
+	{"key": "unused", "value": "x", "enabled": false}
+]}`
+
+func TestImportPostman(t *testing.T) {
+	result, err := ImportPostman([]byte(partnerCollection), []byte(partnerEnvironment))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, map[string]map[string]string{
+		"$shared": {"baseUrl": "https://api.example.com", "retries": "3"},
+		"staging": {"user": "tester", "password": "secret"},
+	}, result.Env)
+
+	paths := []string{}
+	for _, f := range result.Files {
+		paths = append(paths, f.Path)
+	}
+	assert.Equal(t, []string{"Partner_API.http", "Users.http", "Users/Admin.http"}, paths)
+
+	root := result.Files[0].File
+	assert.Equal(t, map[string]string{
+		"version": "v1",
+		"token":   "{{Login.response.body.$.data['access-token']}}",
+		"session": "{{Login.response.headers.X-Session}}",
+	}, root.Variables)
+	buff := bytes.NewBuffer(nil)
+	for _, c := range root.Cases {
+		c.WriteTo(buff)
+	}
+	assert.Equal(t, `# @name Login
+POST {{baseUrl}}/login
+Content-Type: application/x-www-form-urlencoded
+
+user={{user}}&pass=a+b
+# @name query
+POST http://localhost:4000/graphql
+X-Request-Type: GraphQL
+
+{ me { id } }
+`, buff.String())
+
+	users := result.Files[1].File
+	assert.Equal(t, "1", users.Variables["page"])
+	assert.Equal(t, "r-{{$timestampms}}{{page}}", users.Variables["rid"])
+	assert.NotContains(t, users.Variables, "id")
+	list := users.Cases[0].request
+	assert.Equal(t, "list_users", users.Cases[0].Name)
+	assert.Equal(t, "{{baseUrl}}/{{version}}/users?page={{page}}&n={{$randomInt 0 1001}}", string(list.RequestURI()))
+	assert.Equal(t, "Basic admin:{{password}}", string(list.Header.Peek("Authorization")))
+	create := users.Cases[1].request
+	assert.Equal(t, "application/json", string(create.Header.ContentType()))
+	assert.Equal(t, "Basic admin:{{password}}", string(create.Header.Peek("Authorization")))
+
+	avatar := result.Files[2].File.Cases[0].request
+	assert.Equal(t, "{{baseUrl}}/avatar?k={{key}}", string(avatar.RequestURI()))
+	assert.Nil(t, avatar.Header.Peek("Authorization"))
+	assert.Contains(t, string(avatar.Body()), "name=\"name\"\r\n\r\nme\r\n")
+
+	assert.Equal(t, []string{
+		"Login: 3 lines of test script are not supported, the first is pm.test('ok', function () {",
+		"query: auth oauth2 is not supported",
+		"Users: 1 lines of prerequest script are not supported, the first is if (x) { y(); }",
+		"Users / list users: 1 lines of prerequest script are not supported, the first is pm.globals.set(\"id\", {{$guid}});",
+		"Users / create user: dynamic variable $randomFirstName is not supported",
+		"Users / Admin / avatar: file field file of form is not supported",
+	}, result.Warnings)
+
+	_, err = ImportPostman([]byte(`{"info": {"schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}}`))
+	assert.EqualError(t, err, "postman: schema https://schema.getpostman.com/json/collection/v1.0.0/collection.json is not supported, collection v2.1 is expected")
+	_, err = ImportPostman([]byte(partnerCollection), []byte(`[]`))
+	assert.EqualError(t, err, "postman: environment 1: json: cannot unmarshal array into Go value of type httpfile.postmanEnvironment")
+}
+
+func TestPostmanRoundTrip(t *testing.T) {
+	file, err := ParseBytes([]byte(exportContent), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buff := bytes.NewBuffer(nil)
+	assert.NoError(t, file.WritePostman(buff, "cases"))
+
+	result, err := ImportPostman(buff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	imported := result.Files[0].File
+	assert.Equal(t, "{{login.response.body.$.id}}", imported.Variables["login_body_id"])
+	assert.Equal(t, "{{json login.response.body.$.id}}", imported.Variables["login_body_id_json"])
+	assert.Equal(t, "Basic {{user}}:it's", string(imported.Cases[0].request.Header.Peek("Authorization")))
+	assert.Equal(t, "AWS id key region:us-east-1 service:s3", string(imported.Cases[1].request.Header.Peek("Authorization")))
+	assert.Equal(t, "{{host}}/users/{{login_body_id}}", string(imported.Cases[1].request.RequestURI()))
+	assert.Equal(t, "query user($id: ID) { user(id: $id) { name } }\n\n{\"id\": \"{{$guid}}\"}", string(imported.Cases[2].request.Body()))
+}
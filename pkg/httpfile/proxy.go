@@ -0,0 +1,190 @@
+package httpfile
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// WithProxy set proxy of client, http://, https:// and socks5:// are supported,
+// NO_PROXY is still honored, empty proxy means use HTTP_PROXY and HTTPS_PROXY
+func WithProxy(proxyURL string) ClientOpt {
+	return func(c *ClientConfig) {
+		c.Proxy = proxyURL
+	}
+}
+
+// proxySelector select proxy for request url, nil means connect directly
+type proxySelector func(reqURL *url.URL) (*url.URL, error)
+
+func newProxySelector(proxyURL string) proxySelector {
+	cfg := httpproxy.FromEnvironment()
+	if proxyURL != "" {
+		cfg.HTTPProxy = proxyURL
+		cfg.HTTPSProxy = proxyURL
+	}
+	return cfg.ProxyFunc()
+}
+
+// proxyFor select the proxy for a host client
+func (ps proxySelector) proxyFor(hc *fasthttp.HostClient) (*url.URL, error) {
+	reqURL := &url.URL{Scheme: "http", Host: hc.Addr}
+	if hc.IsTLS {
+		reqURL.Scheme = "https"
+	}
+	// default ports is removed, so NO_PROXY match host without port
+	if host, port, err := net.SplitHostPort(hc.Addr); err == nil {
+		if (port == "80" && !hc.IsTLS) || (port == "443" && hc.IsTLS) {
+			reqURL.Host = host
+		}
+	}
+	return ps(reqURL)
+}
+
+// dialProxy connect addr through proxy, https target is tunneled by CONNECT,
+// http target is sent to http proxy in absolute-form, as many proxies refuse
+// CONNECT to ports other than 443
+func dialProxy(proxyURL *url.URL, addr string, isTLS bool, cfg *ClientConfig) (net.Conn, error) {
+	switch proxyURL.Scheme {
+	case "socks5", "socks5h":
+		var auth *proxy.Auth
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			auth = &proxy.Auth{User: proxyURL.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", proxyURL.Host, auth, &net.Dialer{Timeout: cfg.DialTimeout})
+		if err != nil {
+			return nil, fmt.Errorf("socks5 proxy %s: %w", proxyURL.Host, err)
+		}
+		return dialer.Dial("tcp", addr)
+	case "http", "https":
+		conn, err := dialHTTPProxy(proxyURL, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if !isTLS {
+			return &absoluteConn{
+				Conn:   conn,
+				prefix: []byte("http://" + addr),
+				auth:   []byte(proxyAuthorization(proxyURL)),
+				start:  true,
+			}, nil
+		}
+		return dialConnect(conn, proxyURL, addr, cfg.DialTimeout)
+	}
+	return nil, fmt.Errorf("unsupported proxy scheme %s", proxyURL.Scheme)
+}
+
+// dialHTTPProxy connect to http proxy, https proxy is verified by tls config
+// of client like the services
+func dialHTTPProxy(proxyURL *url.URL, cfg *ClientConfig) (net.Conn, error) {
+	proxyAddr := fasthttp.AddMissingPort(proxyURL.Host, proxyURL.Scheme == "https")
+	conn, err := fasthttp.DialTimeout(proxyAddr, cfg.DialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("http proxy %s: %w", proxyAddr, err)
+	}
+	if proxyURL.Scheme != "https" {
+		return conn, nil
+	}
+
+	tlsConfig := &tls.Config{}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+	}
+	tlsConfig.ServerName = proxyURL.Hostname()
+	tlsConn := tls.Client(conn, tlsConfig)
+	if cfg.DialTimeout > 0 {
+		tlsConn.SetDeadline(time.Now().Add(cfg.DialTimeout))
+	}
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("http proxy %s: %w", proxyAddr, err)
+	}
+	tlsConn.SetDeadline(time.Time{})
+	return tlsConn, nil
+}
+
+// proxyAuthorization return Proxy-Authorization header line of proxy url
+func proxyAuthorization(proxyURL *url.URL) string {
+	if proxyURL.User == nil {
+		return ""
+	}
+	password, _ := proxyURL.User.Password()
+	auth := base64.StdEncoding.EncodeToString([]byte(proxyURL.User.Username() + ":" + password))
+	return "Proxy-Authorization: Basic " + auth + "\r\n"
+}
+
+// dialConnect make a tunnel to addr by http CONNECT method
+func dialConnect(conn net.Conn, proxyURL *url.URL, addr string, timeout time.Duration) (net.Conn, error) {
+	req := "CONNECT " + addr + " HTTP/1.1\r\nHost: " + addr + "\r\n" + proxyAuthorization(proxyURL) + "\r\n"
+
+	if timeout > 0 {
+		conn.SetDeadline(time.Now().Add(timeout))
+	}
+	if _, err := conn.Write([]byte(req)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseResponse(resp)
+	resp.SkipBody = true
+	if err := resp.Read(bufio.NewReader(conn)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("http proxy %s: %w", proxyURL.Host, err)
+	}
+	if resp.StatusCode() != fasthttp.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("http proxy %s: CONNECT %s response %d", proxyURL.Host, addr, resp.StatusCode())
+	}
+	conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// absoluteConn send requests to http proxy in absolute-form, request line
+// GET /path is rewritten to GET http://addr/path, a request is written as a
+// whole before its response is read, so the first write after a read starts
+// a new request
+type absoluteConn struct {
+	net.Conn
+	prefix []byte // http://addr
+	auth   []byte // Proxy-Authorization header line, may be empty
+	start  bool   // next write starts a request
+}
+
+func (c *absoluteConn) Read(p []byte) (int, error) {
+	c.start = true
+	return c.Conn.Read(p)
+}
+
+func (c *absoluteConn) Write(p []byte) (int, error) {
+	if !c.start {
+		return c.Conn.Write(p)
+	}
+	c.start = false
+
+	sp := bytes.IndexByte(p, ' ')
+	eol := bytes.Index(p, []byte("\r\n"))
+	if sp < 0 || eol < sp || p[sp+1] != '/' {
+		return c.Conn.Write(p)
+	}
+	buf := make([]byte, 0, len(p)+len(c.prefix)+len(c.auth))
+	buf = append(buf, p[:sp+1]...)
+	buf = append(buf, c.prefix...)
+	buf = append(buf, p[sp+1:eol+2]...)
+	buf = append(buf, c.auth...)
+	buf = append(buf, p[eol+2:]...)
+	if _, err := c.Conn.Write(buf); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,146 @@
+package httpfile
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// startProxy start a http proxy, every CONNECT is tunneled and request in
+// absolute-form is forwarded to 127.0.0.1 with the same port
+func startProxy(t *testing.T, isTLS bool) (string, *int64, *int64) {
+	tunnels, forwards := new(int64), new(int64)
+	transport := &http.Transport{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, port, _ := net.SplitHostPort(r.Host)
+		target := "127.0.0.1:" + port
+		if r.Method == http.MethodConnect {
+			upstream, err := net.Dial("tcp", target)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer upstream.Close()
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			atomic.AddInt64(tunnels, 1)
+			conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			go io.Copy(upstream, rw)
+			io.Copy(conn, upstream)
+			return
+		}
+		if !r.URL.IsAbs() {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		atomic.AddInt64(forwards, 1)
+		req := r.Clone(r.Context())
+		req.RequestURI = ""
+		req.URL.Host = target
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		for k, v := range resp.Header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		io.Copy(w, resp.Body)
+	})
+
+	var server *httptest.Server
+	if isTLS {
+		server = httptest.NewTLSServer(handler)
+	} else {
+		server = httptest.NewServer(handler)
+	}
+	t.Cleanup(func() {
+		server.Close()
+		transport.CloseIdleConnections()
+	})
+	return server.URL, tunnels, forwards
+}
+
+func TestHTTPProxy(t *testing.T) {
+	proxyURL, tunnels, forwards := startProxy(t, false)
+
+	// echo.test can only be resolved by proxy
+	content := `
+	POST http://echo.test:6611/
+	Content-Type: application/json
+
+	{"a": "b"}
+	`
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := ReportStat(Bench(file, 2, 10, -1, WithProxy(proxyURL)))
+	assert.Equal(t, 10, report.Successed)
+	assert.Equal(t, int64(10), atomic.LoadInt64(forwards))
+	assert.Equal(t, int64(0), atomic.LoadInt64(tunnels))
+
+	t.Setenv("NO_PROXY", "echo.test")
+	report = ReportStat(Bench(file, 1, 2, -1, WithProxy(proxyURL)))
+	assert.Equal(t, 2, report.Failed)
+
+	t.Setenv("NO_PROXY", "")
+	t.Setenv("HTTP_PROXY", proxyURL)
+	report = ReportStat(Bench(file, 1, 2, -1))
+	assert.Equal(t, 2, report.Successed)
+	assert.Equal(t, int64(12), atomic.LoadInt64(forwards))
+
+	_, err = dialProxy(mustParseURL("ftp://127.0.0.1"), echoHost, false, newClientConfig())
+	assert.Error(t, err)
+}
+
+func TestHTTPSProxy(t *testing.T) {
+	proxyURL, tunnels, _ := startProxy(t, true)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// example.com is in certificate of test server, it's resolved by proxy
+	content := fmt.Sprintf("GET https://example.com:%s/", server.URL[strings.LastIndex(server.URL, ":")+1:])
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// proxy is verified as service
+	report := ReportStat(Bench(file, 1, 2, -1, WithProxy(proxyURL)))
+	assert.Equal(t, 2, report.Failed)
+	assert.Equal(t, int64(0), atomic.LoadInt64(tunnels))
+
+	rootCAs := x509.NewCertPool()
+	rootCAs.AddCert(server.Certificate())
+	report = ReportStat(Bench(file, 1, 2, -1, WithProxy(proxyURL), WithTLSConfig(&tls.Config{RootCAs: rootCAs})))
+	assert.Equal(t, 2, report.Successed)
+	assert.Equal(t, int64(1), atomic.LoadInt64(tunnels))
+}
+
+func mustParseURL(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(fmt.Sprintf("parse %s: %v", s, err))
+	}
+	return u
+}
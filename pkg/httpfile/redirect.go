@@ -0,0 +1,133 @@
+package httpfile
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultMaxRedirects is the max redirects followed by a case
+const DefaultMaxRedirects = 10
+
+// WithMaxRedirects set max redirects followed by a case, 0 is not follow
+func WithMaxRedirects(n int) ClientOpt {
+	return func(c *ClientConfig) {
+		c.MaxRedirects = n
+	}
+}
+
+func isRedirect(code int) bool {
+	switch code {
+	case fasthttp.StatusMovedPermanently,
+		fasthttp.StatusFound,
+		fasthttp.StatusSeeOther,
+		fasthttp.StatusTemporaryRedirect,
+		fasthttp.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+// redirectRequest build the request to location, 307 and 308 preserve method and body,
+// 303 and POST of 301, 302 change to GET as browsers do
+func redirectRequest(prev *fasthttp.Request, code int, location []byte) *fasthttp.Request {
+	next := fasthttp.AcquireRequest()
+	prev.CopyTo(next)
+	next.URI().UpdateBytes(location)
+
+	method := string(prev.Header.Method())
+	switch {
+	case code == fasthttp.StatusSeeOther && method != fasthttp.MethodHead,
+		(code == fasthttp.StatusMovedPermanently || code == fasthttp.StatusFound) && method == fasthttp.MethodPost:
+		next.Header.SetMethod(fasthttp.MethodGet)
+		next.ResetBody()
+		next.Header.Del("Content-Type")
+		next.Header.SetContentLength(0)
+	}
+
+	// credentials are not sent to another host
+	if !bytes.Equal(prev.URI().Host(), next.URI().Host()) {
+		next.Header.Del("Authorization")
+	}
+	next.Header.DelAllCookies()
+	return next
+}
+
+// followRedirect tell if redirects of case are followed, they are not by
+// # @no-redirect or max redirects 0
+func (f *HTTPFile) followRedirect(c *Case) bool {
+	return !c.noRedirect && f.MaxRedirects > 0
+}
+
+// followRedirects follow redirects of case, response of case is replaced by the final one,
+// cookies set along the chain are kept in it
+func (f *HTTPFile) followRedirects(client *fasthttp.Client, to *Case, jar *CookieJar) error {
+	if !f.followRedirect(to) {
+		return nil
+	}
+
+	prev := to.request
+	defer func() {
+		if prev != to.request {
+			fasthttp.ReleaseRequest(prev)
+		}
+	}()
+
+	for isRedirect(to.response.StatusCode()) {
+		location := to.response.Header.Peek("Location")
+		if len(location) == 0 {
+			return nil
+		}
+		if to.Redirects >= f.MaxRedirects {
+			return fmt.Errorf("request %s stopped after %d redirects", string(to.request.RequestURI()), to.Redirects)
+		}
+
+		next := redirectRequest(prev, to.response.StatusCode(), location)
+		if prev != to.request {
+			fasthttp.ReleaseRequest(prev)
+		}
+		prev = next
+
+		if !to.noCookieJar {
+			jar.apply(next)
+		}
+
+		resp := fasthttp.AcquireResponse()
+		t1 := time.Now()
+		err := client.Do(next, resp)
+		if err != nil {
+			fasthttp.ReleaseResponse(resp)
+			return fmt.Errorf("redirect %s failed: %w", string(next.RequestURI()), err)
+		}
+		t2 := time.Now()
+
+		if !to.noCookieJar {
+			jar.update(next, resp)
+		}
+
+		keepCookies(to.response, resp)
+		fasthttp.ReleaseResponse(to.response)
+		to.response = resp
+		to.Redirects++
+		to.RespTime += t2.Sub(t1)
+		to.RequestSize += len(next.Header.Header()) + len(next.Body()) + len(next.RequestURI())
+		to.ResponseSize += len(resp.Header.Header()) + len(resp.Body())
+	}
+	return nil
+}
+
+// keepCookies copy Set-Cookie of redirect response to the next one, so cookie of login
+// like login.response.cookie.SESSION is found, cookie set again by next one is newer
+func keepCookies(prev, next *fasthttp.Response) {
+	prev.Header.VisitAllCookie(func(key, value []byte) {
+		c := fasthttp.AcquireCookie()
+		defer fasthttp.ReleaseCookie(c)
+		c.SetKeyBytes(key)
+		if next.Header.Cookie(c) || c.ParseBytes(value) != nil {
+			return
+		}
+		next.Header.SetCookie(c)
+	})
+}
@@ -0,0 +1,98 @@
+package httpfile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func redirectServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "SESSION", Value: "s1", Path: "/"})
+			http.Redirect(w, r, "/home", http.StatusFound)
+		case "/temporary":
+			http.Redirect(w, r, "/home", http.StatusTemporaryRedirect)
+		case "/see-other":
+			http.Redirect(w, r, "/home", http.StatusSeeOther)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/home":
+			body, _ := io.ReadAll(r.Body)
+			fmt.Fprintf(w, "%s %s %s", r.Method, r.Header.Get("Cookie"), string(body))
+		}
+	}))
+}
+
+func TestRedirect(t *testing.T) {
+	server := redirectServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @name login
+	POST %[1]s/login
+	Content-Type: text/plain
+
+	a=b
+
+	###
+	POST %[1]s/temporary
+	Content-Type: text/plain
+
+	a=b
+
+	###
+	POST %[1]s/see-other
+	Content-Type: text/plain
+
+	a=b
+
+	###
+	# @no-redirect
+	GET %[1]s/loop
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "GET SESSION=s1 ", string(file.Cases[0].response.Body()))
+	assert.Equal(t, 1, file.Cases[0].Redirects)
+	// cookie set by redirect response is kept
+	session, ok := file.getCookieVariable("login.response.cookie.SESSION")
+	assert.True(t, ok)
+	assert.Equal(t, "s1", session)
+	assert.Equal(t, "POST SESSION=s1 \ta=b", string(file.Cases[1].response.Body()))
+	assert.Equal(t, "GET SESSION=s1 ", string(file.Cases[2].response.Body()))
+	assert.Equal(t, 302, file.Cases[3].RespCode)
+	assert.Equal(t, 0, file.Cases[3].Redirects)
+
+	loop, err := ParseBytes([]byte(fmt.Sprintf("GET %s/loop", server.URL)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := ReportStat(Bench(loop, 1, 2, -1, WithMaxRedirects(3)))
+	assert.Equal(t, 2, report.Failed)
+
+	// 0 is not follow, the redirect is the response
+	report = ReportStat(Bench(loop, 1, 2, -1, WithMaxRedirects(0)))
+	assert.Equal(t, 2, report.Successed)
+	assert.Equal(t, 0, report.Redirects)
+
+	report = ReportStat(Bench(file, 2, 4, -1))
+	assert.Equal(t, 4, report.Successed)
+	assert.Equal(t, 12, report.Redirects)
+}
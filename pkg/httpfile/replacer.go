@@ -6,6 +6,39 @@ type Replacer interface {
 	Get(key string) (string, bool)
 }
 
+// TemplateReplacer is a Replacer which known if value is a template, value of template
+// is expanded again, values of variable definition are templates, but values from
+// responses and functions are not
+type TemplateReplacer interface {
+	Replacer
+	// IsTemplate return true if value of key should be expanded again
+	IsTemplate(key string) bool
+}
+
+// TypedReplacer is a Replacer which known json value of key, like number or object
+// selected from json response, it's used by {{json key}}
+type TypedReplacer interface {
+	Replacer
+	// GetValue return the json value by key, if not found key, should return nil, false
+	GetValue(key string) (interface{}, bool)
+}
+
+// typedValue get json value of key, value of Replacer is string by default
+func typedValue(ve Replacer, key string) (interface{}, bool) {
+	if tr, ok := ve.(TypedReplacer); ok {
+		return tr.GetValue(key)
+	}
+	return ve.Get(key)
+}
+
+// isTemplate check value of key is a template, value of Replacer is template by default
+func isTemplate(ve Replacer, key string) bool {
+	if tr, ok := ve.(TemplateReplacer); ok {
+		return tr.IsTemplate(key)
+	}
+	return true
+}
+
 // MapReplacer is a ValueExtractor based on map[string]string
 type MapReplacer map[string]string
 
@@ -27,3 +60,23 @@ func (le ListReplacer) Get(key string) (string, bool) {
 	}
 	return "", false
 }
+
+// IsTemplate is required by TemplateReplacer interface
+func (le ListReplacer) IsTemplate(key string) bool {
+	for _, e := range le {
+		if _, ok := e.Get(key); ok {
+			return isTemplate(e, key)
+		}
+	}
+	return false
+}
+
+// GetValue is required by TypedReplacer interface
+func (le ListReplacer) GetValue(key string) (interface{}, bool) {
+	for _, e := range le {
+		if val, ok := typedValue(e, key); ok {
+			return val, ok
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,167 @@
+package httpfile
+
+import (
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// requestVariable refer a value of named request like REST Client does,
+// {{name.(request|response).(headers|body).(Header-Name|*|JSONPath|XPath)}},
+// {{name.response.data.JSONPath}} select from data of GraphQL response
+type requestVariable struct {
+	caseName string // name of case, given by # @name
+	kind     string // request or response
+	part     string // headers, body or data
+	path     string // header name, * for whole body, JSONPath or XPath of body
+}
+
+// parseRequestVariable parse key like login.response.headers.X-Token,
+// .header. is kept for compatibility
+func parseRequestVariable(key string) (requestVariable, bool) {
+	args := strings.SplitN(key, ".", 4)
+	if len(args) != 4 || len(args[0]) == 0 || len(args[3]) == 0 {
+		return requestVariable{}, false
+	}
+	if args[1] != "request" && args[1] != "response" {
+		return requestVariable{}, false
+	}
+	switch args[2] {
+	case "headers", "header":
+		args[2] = "headers"
+	case "body":
+	case "data":
+		if args[1] != "response" {
+			return requestVariable{}, false
+		}
+	default:
+		return requestVariable{}, false
+	}
+	return requestVariable{caseName: args[0], kind: args[1], part: args[2], path: args[3]}, true
+}
+
+// getRequestVariable get value of request variable, it's evaluated lazily against cases
+// of this file, only executed cases have value, so a template file given to Duplicate
+// keep request variables for each virtual user to resolve them against it's own copy
+func (f *HTTPFile) getRequestVariable(rv requestVariable) (string, bool) {
+	val, ok := f.requestValue(rv)
+	if !ok {
+		return "", false
+	}
+	return jsonValueString(val), true
+}
+
+// requestValue get typed value of request variable, value selected by JSONPath is
+// a json value, values selected are a array if more than one, others are string
+func (f *HTTPFile) requestValue(rv requestVariable) (interface{}, bool) {
+	theCase := f.findCaseByName(rv.caseName)
+	if theCase == nil || theCase.request == nil || theCase.response == nil {
+		return "", false
+	}
+
+	if rv.part == "headers" {
+		var val []byte
+		if rv.kind == "request" {
+			val = theCase.request.Header.Peek(rv.path)
+		} else {
+			val = theCase.response.Header.Peek(rv.path)
+		}
+		if val == nil {
+			return "", false
+		}
+		return string(val), true
+	}
+
+	body := theCase.body(rv.kind)
+	switch {
+	case rv.path == "*" && rv.part == "body":
+		return string(body), true
+	case strings.HasPrefix(rv.path, "/") && rv.part == "body":
+		doc, ok := theCase.parsedBody(rv.kind).(*xmlNode)
+		if !ok {
+			doc = parseXML(body)
+			if doc == nil {
+				return "", false
+			}
+			theCase.setParsedBody(rv.kind, doc)
+		}
+		return XPathGet(doc, rv.path, f.Namespaces)
+	default:
+		data, ok := theCase.jsonBody(rv.kind)
+		if !ok {
+			return "", false
+		}
+		if rv.part == "data" {
+			// data of GraphQL response
+			obj, _ := data.(map[string]interface{})
+			if data, ok = obj["data"]; !ok {
+				return "", false
+			}
+		}
+		p, err := theCase.paths.compile(rv.path)
+		if err != nil {
+			zap.L().Error("jsonpath parse failed", zap.String("path", rv.path), zap.Error(err))
+			return "", false
+		}
+		values := p.Query(data)
+		switch len(values) {
+		case 0:
+			// nothing matched is undefined, like a missing header
+			return nil, false
+		case 1:
+			return values[0], true
+		}
+		return values, true
+	}
+}
+
+// body return request body or decoded response body
+func (c *Case) body(kind string) []byte {
+	if kind == "request" {
+		return c.request.Body()
+	}
+	var body []byte
+	var err error
+	switch string(c.response.Header.Peek("Content-Encoding")) {
+	case "gzip":
+		body, err = c.response.BodyGunzip()
+	case "deflate":
+		body, err = c.response.BodyInflate()
+	default:
+		body = c.response.Body()
+	}
+	if err != nil {
+		return c.response.Body()
+	}
+	return body
+}
+
+// jsonBody return parsed json body, it's cached
+func (c *Case) jsonBody(kind string) (interface{}, bool) {
+	data := c.parsedBody(kind)
+	if _, ok := data.(*xmlNode); ok || data == nil {
+		data = nil
+		if json.Unmarshal(c.body(kind), &data) != nil {
+			return nil, false
+		}
+		c.setParsedBody(kind, data)
+	}
+	return data, true
+}
+
+// parsedBody return cached parsed request or response body, nil if not parsed
+func (c *Case) parsedBody(kind string) interface{} {
+	if kind == "request" {
+		return c.parsedReqBody
+	}
+	return c.parsedRespBody
+}
+
+func (c *Case) setParsedBody(kind string, data interface{}) {
+	if kind == "request" {
+		c.parsedReqBody = data
+	} else {
+		c.parsedRespBody = data
+	}
+}
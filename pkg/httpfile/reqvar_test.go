@@ -0,0 +1,231 @@
+package httpfile
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func loginServer() *httptest.Server {
+	var counter int64
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			token := "t" + strconv.FormatInt(atomic.AddInt64(&counter, 1), 10)
+			w.Header().Set("X-Token", token)
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"token":"%s","user":{"id":7}}`, token)
+		case "/soap":
+			w.Header().Set("Content-Type", "text/xml")
+			w.Write([]byte(`<?xml version="1.0"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <LoginResult code="0">
+      <Item>a</Item>
+      <Item>b</Item>
+      <Token>x1</Token>
+    </LoginResult>
+  </soap:Body>
+</soap:Envelope>`))
+		default:
+			w.Write([]byte(r.Header.Get("Authorization") + "|" + r.Header.Get("X-Code")))
+		}
+	}))
+}
+
+func TestParseRequestVariable(t *testing.T) {
+	rv, ok := parseRequestVariable("login.response.headers.X-Token")
+	assert.True(t, ok)
+	assert.Equal(t, requestVariable{"login", "response", "headers", "X-Token"}, rv)
+
+	rv, ok = parseRequestVariable("login.request.header.X-Token")
+	assert.True(t, ok)
+	assert.Equal(t, "headers", rv.part)
+
+	rv, ok = parseRequestVariable("login.response.body.$.a.b")
+	assert.True(t, ok)
+	assert.Equal(t, "$.a.b", rv.path)
+
+	rv, ok = parseRequestVariable("user.response.data.user.id")
+	assert.True(t, ok)
+	assert.Equal(t, requestVariable{"user", "response", "data", "user.id"}, rv)
+	_, ok = parseRequestVariable("user.request.data.user.id")
+	assert.False(t, ok)
+
+	_, ok = parseRequestVariable("login.response.cookie.SESSION")
+	assert.False(t, ok)
+	_, ok = parseRequestVariable("login.result.body.*")
+	assert.False(t, ok)
+	_, ok = parseRequestVariable("login.response.body")
+	assert.False(t, ok)
+}
+
+func TestRequestVariable(t *testing.T) {
+	server := loginServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @name login
+	POST %[1]s/login
+	Content-Type: application/json
+
+	{"name": "u1"}
+
+	###
+	# @name soap
+	POST %[1]s/soap
+
+	###
+	# @name profile
+	GET %[1]s/profile
+	Authorization: Bearer {{login.response.headers.X-Token}}
+	X-Code: {{soap.response.body.//LoginResult/@code}}
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Bearer t1|0", string(file.Cases[2].response.Body()))
+
+	for key, expected := range map[string]string{
+		"login.response.headers.x-token":              "t1",
+		"login.response.header.X-Token":               "t1",
+		"login.request.headers.Content-Type":          "application/json",
+		"login.response.body.$.token":                 "t1",
+		"login.response.body.$.user.id":               "7",
+		"login.request.body.$.name":                   "u1",
+		"login.request.body.*":                        "\t{\"name\": \"u1\"}",
+		"login.response.body.*":                       `{"token":"t1","user":{"id":7}}`,
+		"soap.response.body.//Token":                  "x1",
+		"soap.response.body.//soap:Body/*/Token":      "x1",
+		"soap.response.body./Envelope/Body/*/Item[2]": "b",
+		"soap.response.body.//Item/text()":            "a",
+	} {
+		val, ok := file.Get(key)
+		assert.True(t, ok, key)
+		assert.Equal(t, expected, val, key)
+	}
+
+	for _, key := range []string{
+		"login.response.headers.X-None",
+		"login.response.body.$.none",
+		"soap.response.body.//None",
+		"none.response.body.*",
+	} {
+		_, ok := file.Get(key)
+		assert.False(t, ok, key)
+	}
+}
+
+func TestRequestVariableNotExecuted(t *testing.T) {
+	server := loginServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @name profile
+	GET %[1]s/profile
+	Authorization: Bearer {{login.response.headers.X-Token}}
+
+	###
+	# @name login
+	POST %[1]s/login
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = file.Execute(&fasthttp.Client{})
+	assert.ErrorContains(t, err, "undefined variable login.response.headers.X-Token")
+}
+
+func TestRequestVariableIterations(t *testing.T) {
+	server := loginServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @name login
+	POST %[1]s/login
+
+	###
+	# @name profile
+	GET %[1]s/profile
+	Authorization: {{login.response.body.$.token}}
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	client := &fasthttp.Client{}
+	for i := 1; i <= 3; i++ {
+		w := file.Duplicate(true, true)
+		w.AutoClean = false
+		err = w.Execute(client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "t"+strconv.Itoa(i)+"|", string(w.Cases[1].response.Body()))
+		w.Release()
+	}
+}
+
+func TestRequestVariableTyped(t *testing.T) {
+	content := fmt.Sprintf(`
+	@name = a "quoted" <name>
+
+	# @name case1
+	POST %[1]s
+	Content-Type: application/json
+
+	{"n": 7, "s": "x", "obj": {"a": [1, true, null]}, "items": [{"id": 1}, {"id": 2}]}
+
+	###
+	# @name case2
+	POST %[1]s
+	Content-Type: application/json
+
+	{"n": {{case1.response.body.$.n}}, "obj": {{case1.response.body.$.obj}}, "ids": {{case1.response.body.$.items[*].id}}, "jn": {{json case1.response.body.$.n}}, "js": {{json case1.response.body.$.s}}, "rs": "{{raw case1.response.body.$.s}}", "name": {{json name}}}
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.JSONEq(t, `{"n": 7, "obj": {"a": [1, true, null]}, "ids": [1, 2], "jn": 7, "js": "x", "rs": "x", "name": "a \"quoted\" <name>"}`,
+		string(file.Cases[1].response.Body()))
+
+	val, ok := file.GetValue("case1.response.body.$.n")
+	assert.True(t, ok)
+	assert.Equal(t, float64(7), val)
+	val, ok = file.GetValue("name")
+	assert.True(t, ok)
+	assert.Equal(t, `a "quoted" <name>`, val)
+
+	_, err = ExpandVariable([]byte("{{json none}}"), file)
+	assert.EqualError(t, err, "undefined variable none")
+	_, err = ExpandVariable([]byte(`{"n": {{case1.response.body.$.none}}}`), file)
+	assert.EqualError(t, err, "undefined variable case1.response.body.$.none")
+	assert.Equal(t, "{{json none}}", ReplaceVariableString("{{json none}}", file))
+}
@@ -0,0 +1,296 @@
+package httpfile
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// signature functions are evaluated after the rest of request is expanded, so they can sign it,
+// template is a quoted string or variable name, ${method}, ${path}, ${query}, ${url}, ${body},
+// ${header.Name} in template are parts of request, other ${name} are variables
+//
+//	{{$hmac sha256 secretVar "${method}\n${path}\n${body}\n${header.X-Timestamp}" [hex|base64]}}
+//	{{$jwt HS256 secretVar claimsVar}}
+//	{{$md5 template}}
+//	{{$sha256 template}}
+//	{{$base64 template}}
+var signatureFunctions = map[string]bool{
+	"$hmac":   true,
+	"$jwt":    true,
+	"$md5":    true,
+	"$sha256": true,
+	"$base64": true,
+}
+
+// ${name}
+var templateRef, _ = regexp.Compile(`\$\{(.+?)\}`)
+
+// funcArg is a argument of function
+type funcArg struct {
+	text   string
+	quoted bool
+}
+
+// splitArgs split function arguments by space, "a b" is unquoted as go string, 'a b' is raw
+func splitArgs(text string) []funcArg {
+	var args []funcArg
+	for {
+		text = strings.TrimLeft(text, " \t")
+		if text == "" {
+			return args
+		}
+		switch text[0] {
+		case '"':
+			end := 1
+			for end < len(text) && text[end] != '"' {
+				if text[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(text) {
+				end = len(text) - 1
+			}
+			quoted := text[:end+1]
+			val, err := strconv.Unquote(quoted)
+			if err != nil {
+				val = strings.Trim(quoted, `"`)
+			}
+			args = append(args, funcArg{text: val, quoted: true})
+			text = text[end+1:]
+		case '\'':
+			end := strings.IndexByte(text[1:], '\'')
+			if end < 0 {
+				end = len(text) - 1
+			}
+			args = append(args, funcArg{text: text[1 : end+1], quoted: true})
+			if end+2 > len(text) {
+				text = ""
+			} else {
+				text = text[end+2:]
+			}
+		default:
+			end := strings.IndexAny(text, " \t")
+			if end < 0 {
+				end = len(text)
+			}
+			args = append(args, funcArg{text: text[:end]})
+			text = text[end:]
+		}
+	}
+}
+
+// requestReplacer evaluate signature functions with the expanded request
+type requestReplacer struct {
+	req *fasthttp.Request
+	ve  Replacer
+	err error // the first failed signature function
+}
+
+// value of argument, unquoted argument is variable name if it's defined
+func (rr *requestReplacer) value(arg funcArg) string {
+	if !arg.quoted {
+		if val, ok := rr.ve.Get(arg.text); ok {
+			return ReplaceVariableString(val, rr.ve)
+		}
+	}
+	return arg.text
+}
+
+// template expand ${name} in argument
+func (rr *requestReplacer) template(arg funcArg) string {
+	text := rr.value(arg)
+	return templateRef.ReplaceAllStringFunc(text, func(ref string) string {
+		name := ref[2 : len(ref)-1]
+		switch name {
+		case "method":
+			return string(rr.req.Header.Method())
+		case "path":
+			return string(rr.req.URI().PathOriginal())
+		case "query":
+			return string(rr.req.URI().QueryString())
+		case "url":
+			return rr.req.URI().String()
+		case "body":
+			return string(rr.req.Body())
+		}
+		if strings.HasPrefix(name, "header.") {
+			return string(rr.req.Header.Peek(name[7:]))
+		}
+		if val, ok := rr.ve.Get(name); ok {
+			return ReplaceVariableString(val, rr.ve)
+		}
+		return ref
+	})
+}
+
+// Get is required by Replacer interface
+func (rr *requestReplacer) Get(key string) (string, bool) {
+	name, rest, _ := strings.Cut(key, " ")
+	if !signatureFunctions[name] {
+		return "", false
+	}
+	args := splitArgs(rest)
+
+	var result string
+	var err error
+	switch name {
+	case "$hmac":
+		result, err = rr.hmac(args)
+	case "$jwt":
+		result, err = rr.jwt(args)
+	case "$md5", "$sha256", "$base64":
+		if len(args) != 1 {
+			err = fmt.Errorf("%s need 1 argument", name)
+			break
+		}
+		data := []byte(rr.template(args[0]))
+		switch name {
+		case "$md5":
+			sum := md5.Sum(data)
+			result = hex.EncodeToString(sum[:])
+		case "$sha256":
+			result = sha256Hex(data)
+		case "$base64":
+			result = base64.StdEncoding.EncodeToString(data)
+		}
+	}
+	if err != nil {
+		if rr.err == nil {
+			rr.err = fmt.Errorf("%s: %w", name, err)
+		}
+		return "", false
+	}
+	return result, true
+}
+
+func hashByName(name string) (func() hash.Hash, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "-", "")) {
+	case "md5":
+		return md5.New, nil
+	case "sha1":
+		return sha1.New, nil
+	case "sha256":
+		return sha256.New, nil
+	case "sha384":
+		return sha512.New384, nil
+	case "sha512":
+		return sha512.New, nil
+	}
+	return nil, fmt.Errorf("unsupported hash %s", name)
+}
+
+// hmac is {{$hmac algorithm secret template [hex|base64]}}
+func (rr *requestReplacer) hmac(args []funcArg) (string, error) {
+	if len(args) < 3 {
+		return "", fmt.Errorf("$hmac need algorithm, secret and template")
+	}
+	newHash, err := hashByName(args[0].text)
+	if err != nil {
+		return "", err
+	}
+	h := hmac.New(newHash, []byte(rr.value(args[1])))
+	h.Write([]byte(rr.template(args[2])))
+	sum := h.Sum(nil)
+
+	encoding := "hex"
+	if len(args) > 3 {
+		encoding = args[3].text
+	}
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(sum), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(sum), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(sum), nil
+	}
+	return "", fmt.Errorf("unsupported encoding %s", encoding)
+}
+
+// jwt is {{$jwt HS256 secret claims}}, iat is added if not given
+func (rr *requestReplacer) jwt(args []funcArg) (string, error) {
+	if len(args) != 3 {
+		return "", fmt.Errorf("$jwt need algorithm, secret and claims")
+	}
+	alg := strings.ToUpper(args[0].text)
+	var newHash func() hash.Hash
+	switch alg {
+	case "HS256":
+		newHash = sha256.New
+	case "HS384":
+		newHash = sha512.New384
+	case "HS512":
+		newHash = sha512.New
+	default:
+		return "", fmt.Errorf("unsupported jwt algorithm %s", alg)
+	}
+
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(rr.template(args[2])), &claims); err != nil {
+		return "", fmt.Errorf("jwt claims: %w", err)
+	}
+	if _, ok := claims["iat"]; !ok {
+		claims["iat"] = time.Now().Unix()
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	header, _ := json.Marshal(map[string]string{"alg": alg, "typ": "JWT"})
+
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	h := hmac.New(newHash, []byte(rr.value(args[1])))
+	h.Write([]byte(signing))
+	return signing + "." + base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// sign evaluate signature functions in request, body is evaluated first,
+// then uri and headers, so headers can sign the final body and uri, request
+// isn't sent if a function failed
+func (c *Case) sign(ve Replacer) error {
+	rr := &requestReplacer{req: c.request, ve: ve}
+	replace := func(text []byte) ([]byte, error) {
+		replaced := ReplaceVariable(text, rr)
+		return replaced, rr.err
+	}
+
+	body, err := replace(c.request.Body())
+	if err != nil {
+		return err
+	}
+	c.request.SetBody(body)
+	uri, err := replace(c.request.RequestURI())
+	if err != nil {
+		return err
+	}
+	c.request.SetRequestURIBytes(uri)
+
+	var keys, values [][]byte
+	c.request.Header.VisitAll(func(key, val []byte) {
+		keys = append(keys, append([]byte(nil), key...))
+		values = append(values, append([]byte(nil), val...))
+	})
+	for i := range keys {
+		value, err := replace(values[i])
+		if err != nil {
+			return err
+		}
+		c.request.Header.SetBytesKV(keys[i], value)
+	}
+	return nil
+}
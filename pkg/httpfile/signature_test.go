@@ -0,0 +1,100 @@
+package httpfile
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSignatureFunctions(t *testing.T) {
+	content := fmt.Sprintf(`
+	@server = %s
+	@secret = s3cr3t
+	@user = tom
+	@claims = {"sub": "${user}", "iat": 1600000000}
+
+	POST {{server}}api/order?id=1
+	Content-Type: application/json
+	X-Timestamp: 1600000000
+	X-Signature: {{$hmac sha256 secret "${method}\n${path}\n${body}\n${header.X-Timestamp}"}}
+	X-Signature-Base64: {{$hmac sha256 secret 'raw' base64}}
+	X-Body-Md5: {{$md5 "${body}"}}
+	X-Body-Sha256: {{$sha256 "${body}"}}
+	X-Basic: {{$base64 "${user}:pass"}}
+	Authorization: Bearer {{$jwt HS256 secret claims}}
+
+	{"user": "{{user}}"}
+	`, echoServer)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	err = file.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := file.Cases[0].request
+	header := func(name string) string {
+		return string(req.Header.Peek(name))
+	}
+
+	body := `{"user": "tom"}`
+	assert.Equal(t, body, strings.TrimSpace(string(req.Body())))
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte("POST\n/api/order\n" + string(req.Body()) + "\n1600000000"))
+	assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), header("X-Signature"))
+
+	mac = hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte("raw"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), header("X-Signature-Base64"))
+
+	assert.Equal(t, md5Hex(string(req.Body())), header("X-Body-Md5"))
+	assert.Equal(t, sha256Hex(req.Body()), header("X-Body-Sha256"))
+	assert.Equal(t, "dG9tOnBhc3M=", header("X-Basic"))
+
+	token := strings.TrimPrefix(header("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	assert.Equal(t, 3, len(parts))
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	claims := map[string]interface{}{}
+	json.Unmarshal(payload, &claims)
+	assert.Equal(t, "tom", claims["sub"])
+	mac = hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(mac.Sum(nil)), parts[2])
+
+	// failed function fails the case instead of sending it as is
+	for header, message := range map[string]string{
+		`{{$hmac sha1024 secret "x"}}`: "case 1: $hmac: unsupported hash sha1024",
+		`{{$jwt RS256 secret claims}}`: "case 1: $jwt: unsupported jwt algorithm RS256",
+		`{{$md5}}`:                     "case 1: $md5: $md5 need 1 argument",
+	} {
+		file, err := ParseBytes([]byte(fmt.Sprintf("@secret = s3cr3t\n@claims = {}\nGET %s\nX-Signature: %s\n", echoServer, header)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.EqualError(t, file.Execute(&fasthttp.Client{}), message, header)
+		file.Release()
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	assert.Equal(t, []funcArg{
+		{text: "sha256"},
+		{text: "secret"},
+		{text: "a\nb c", quoted: true},
+		{text: `{"a": 1}`, quoted: true},
+	}, splitArgs(` sha256  secret "a\nb c" '{"a": 1}'`))
+}
@@ -0,0 +1,60 @@
+package httpfile
+
+import (
+	"bytes"
+	"strings"
+)
+
+// SOAP envelope namespaces
+const (
+	SOAP11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	SOAP12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// soapEnvelope is a case sent as SOAP request, by # @soap [1.1|1.2] [action]
+type soapEnvelope struct {
+	version string // 1.1 or 1.2
+	action  string // SOAPAction of 1.1 or action parameter of content type of 1.2
+}
+
+// parseSOAPDirective parse value of # @soap directive
+func parseSOAPDirective(value string) *soapEnvelope {
+	soap := &soapEnvelope{version: "1.1"}
+	fields := strings.Fields(value)
+	if len(fields) > 0 && (fields[0] == "1.1" || fields[0] == "1.2") {
+		soap.version, fields = fields[0], fields[1:]
+	}
+	if len(fields) > 0 {
+		soap.action = strings.Trim(fields[0], `"`)
+	}
+	return soap
+}
+
+// wrap body of case in SOAP envelope, body which is a envelope already is kept,
+// content type and action header are set if not given
+func (s *soapEnvelope) wrap(c *Case) {
+	ns, contentType := SOAP11Namespace, "text/xml; charset=utf-8"
+	if s.version == "1.2" {
+		ns, contentType = SOAP12Namespace, "application/soap+xml; charset=utf-8"
+		if len(s.action) > 0 {
+			contentType += `; action="` + s.action + `"`
+		}
+	} else if len(c.request.Header.Peek("SOAPAction")) == 0 {
+		c.request.Header.Set("SOAPAction", `"`+s.action+`"`)
+	}
+	if len(c.request.Header.ContentType()) == 0 {
+		c.request.Header.SetContentType(contentType)
+	}
+
+	body := bytes.TrimSpace(c.request.Body())
+	if bytes.Contains(body, []byte(":Envelope")) || bytes.Contains(body, []byte("<Envelope")) {
+		return
+	}
+
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	buf.WriteString(`<soap:Envelope xmlns:soap="` + ns + `"><soap:Body>`)
+	buf.Write(body)
+	buf.WriteString(`</soap:Body></soap:Envelope>`)
+	c.request.SetBody(buf.Bytes())
+}
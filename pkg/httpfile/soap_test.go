@@ -0,0 +1,138 @@
+package httpfile
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func soapServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/xml")
+		w.Header().Set("X-Action", r.Header.Get("SOAPAction"))
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		if strings.Contains(string(body), "<m:Login") {
+			w.Write([]byte(`<?xml version="1.0"?>
+<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/">
+  <s:Body>
+    <LoginResponse xmlns="urn:auth" xmlns:x="urn:other">
+      <x:Token>other</x:Token>
+      <Token>t1</Token>
+    </LoginResponse>
+  </s:Body>
+</s:Envelope>`))
+			return
+		}
+		w.Write(body)
+	}))
+}
+
+func TestXPathGet(t *testing.T) {
+	doc := `<a xmlns:p="urn:p" xmlns:q="urn:q"><p:b id="1">x</p:b><q:b id="2">y</q:b><c><b>z</b></c></a>`
+	ns := map[string]string{"p": "urn:p", "q": "urn:q"}
+
+	for path, expected := range map[string]string{
+		"/a/b":          "x",
+		"/a/q:b":        "y",
+		"/a/q:b/@id":    "2",
+		"//b[2]":        "y",
+		"//c/b":         "z",
+		"/a/*[3]":       "z",
+		"/a":            "xyz",
+		"/a/other:b":    "x",
+		"//q:b/text()":  "y",
+		"/a/c/b/text()": "z",
+	} {
+		val, ok := XPathGet(doc, path, ns)
+		assert.True(t, ok, path)
+		assert.Equal(t, expected, val, path)
+	}
+
+	for _, path := range []string{"/b", "/a/p:c", "//q:b[2]", "a/b", "/a/b[x]"} {
+		_, ok := XPathGet(doc, path, ns)
+		assert.False(t, ok, path)
+	}
+
+	_, ok := XPathGet("not xml", "/a", nil)
+	assert.False(t, ok)
+}
+
+func TestParseSOAPDirective(t *testing.T) {
+	assert.Equal(t, &soapEnvelope{version: "1.1"}, parseSOAPDirective(""))
+	assert.Equal(t, &soapEnvelope{version: "1.1", action: "urn:Login"}, parseSOAPDirective(`"urn:Login"`))
+	assert.Equal(t, &soapEnvelope{version: "1.2", action: "urn:Login"}, parseSOAPDirective("1.2 urn:Login"))
+}
+
+func TestSOAP(t *testing.T) {
+	server := soapServer()
+	defer server.Close()
+
+	content := fmt.Sprintf(`
+	# @namespace auth=urn:auth
+	# @namespace soap = %[2]s
+
+	# @name login
+	# @soap urn:Login
+	POST %[1]s/login
+
+	<m:Login xmlns:m="urn:auth"><m:Name>u1</m:Name></m:Login>
+
+	###
+	# @name echo
+	# @soap 1.2 urn:Echo
+	POST %[1]s/echo
+
+	<m:Echo xmlns:m="urn:echo">{{login.response.body.//auth:Token}}</m:Echo>
+
+	###
+	# @name raw
+	# @soap
+	POST %[1]s/raw
+	Content-Type: text/xml
+
+	<soap:Envelope xmlns:soap="%[2]s"><soap:Body>r</soap:Body></soap:Envelope>
+	`, server.URL, SOAP11Namespace)
+
+	file, err := ParseBytes([]byte(content), DisaableAutoClean)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	assert.Equal(t, map[string]string{"auth": "urn:auth", "soap": SOAP11Namespace}, file.Namespaces)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?><soap:Envelope xmlns:soap="`+SOAP11Namespace+`"><soap:Body>`+
+		`<m:Login xmlns:m="urn:auth"><m:Name>u1</m:Name></m:Login></soap:Body></soap:Envelope>`,
+		string(file.Cases[0].request.Body()))
+
+	w := file.Duplicate(false, true)
+	w.AutoClean = false
+	defer w.Release()
+	err = w.Execute(&fasthttp.Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, `"urn:Login"`, string(w.Cases[0].response.Header.Peek("X-Action")))
+	assert.Equal(t, "text/xml; charset=utf-8", string(w.Cases[0].response.Header.Peek("X-Content-Type")))
+
+	assert.Equal(t, `application/soap+xml; charset=utf-8; action="urn:Echo"`, string(w.Cases[1].response.Header.Peek("X-Content-Type")))
+	val, ok := w.Get("echo.response.body./soap12:Envelope/soap12:Body/*")
+	assert.True(t, ok)
+	assert.Equal(t, "t1", val)
+	val, ok = w.Get("login.response.body.//soap:Body/auth:LoginResponse/x:Token")
+	assert.True(t, ok)
+	assert.Equal(t, "other", val)
+
+	assert.Equal(t, `<soap:Envelope xmlns:soap="`+SOAP11Namespace+`"><soap:Body>r</soap:Body></soap:Envelope>`,
+		strings.TrimSpace(string(w.Cases[2].response.Body())))
+	val, ok = w.Get("raw.response.body./soap:Envelope/soap:Body")
+	assert.True(t, ok)
+	assert.Equal(t, "r", val)
+}
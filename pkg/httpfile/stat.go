@@ -16,6 +16,14 @@ type Stat struct {
 	BytesReceived int
 	Successed     int
 	Failed        int
+	ConnOpened    int
+	ConnReused    int
+	ConnClosed    int
+	TLSHandshakes int
+	TLSResumed    int
+	HandshakeTime float64
+	Redirects     int
+	GraphQLErrors []string // classes of GraphQL errors failed the iteration
 }
 
 // Report is the statatics of results
@@ -41,6 +49,14 @@ type Report struct {
 	P90TimeUsed           float64
 	P95TimeUsed           float64
 	P99TimeUsed           float64
+	ConnOpened            int
+	ConnReused            int
+	ConnClosed            int
+	TLSHandshakes         int
+	TLSResumed            int
+	AvgHandshakeTime      float64
+	Redirects             int
+	GraphQLErrors         map[string]int // count of GraphQL errors by class
 	Stats                 []Stat
 }
 
@@ -76,12 +92,26 @@ func ReportStat(stats []Stat, totalTimeUsed float64) Report {
 	})
 
 	sumTimeUsed := 0.0
+	handshakeTime := 0.0
 	report.TotalRequests = len(stats)
 	for _, s := range stats {
 		report.TotalRecv = report.TotalRecv + s.BytesReceived
 		report.TotalSend = report.TotalSend + s.BytesSend
 		report.Successed += s.Successed
 		report.Failed += s.Failed
+		report.Redirects += s.Redirects
+		report.ConnOpened += s.ConnOpened
+		report.ConnReused += s.ConnReused
+		report.ConnClosed += s.ConnClosed
+		report.TLSHandshakes += s.TLSHandshakes
+		report.TLSResumed += s.TLSResumed
+		handshakeTime += s.HandshakeTime
+		for _, class := range s.GraphQLErrors {
+			if report.GraphQLErrors == nil {
+				report.GraphQLErrors = make(map[string]int)
+			}
+			report.GraphQLErrors[class]++
+		}
 		sumTimeUsed = sumTimeUsed + s.TimeConsuming
 	}
 	report.RequestTotalTimeUsed = totalTimeUsed
@@ -89,6 +119,9 @@ func ReportStat(stats []Stat, totalTimeUsed float64) Report {
 	report.SendSpeed = float64(report.TotalSend) / float64(report.RequestTotalTimeUsed)
 
 	report.ResponseTotalTimeUsed = sumTimeUsed
+	if report.TLSHandshakes > 0 {
+		report.AvgHandshakeTime = handshakeTime / float64(report.TLSHandshakes)
+	}
 	report.AvgTimeUsed = sumTimeUsed / float64(report.Successed)
 	report.RequestPerSecond = int((1.0 / report.RequestTotalTimeUsed) * float64(report.Successed))
 	report.ResponsePerSecond = int((1.0 / report.ResponseTotalTimeUsed) * float64(report.Successed))
@@ -120,6 +153,7 @@ func PlainOutput(report *Report, w io.Writer) {
 	fmt.Fprintf(w, format, "Successed", report.Successed)
 	fmt.Fprintf(w, format, "RateLimit", report.Successed)
 	fmt.Fprintf(w, format, "Failed", report.Failed)
+	fmt.Fprintf(w, format, "Redirects", report.Redirects)
 	fmt.Fprintf(w, format, "Request Time Used", report.RequestTotalTimeUsed)
 	fmt.Fprintf(w, format, "Reqeust Per Second", report.RequestPerSecond)
 	fmt.Fprintf(w, format, "Send Speed", report.SendSpeed)
@@ -139,6 +173,41 @@ func PlainOutput(report *Report, w io.Writer) {
 	fmt.Fprintf(w, format, "P90 Time Used", report.P90TimeUsed)
 	fmt.Fprintf(w, format, "P95 Time Used", report.P95TimeUsed)
 	fmt.Fprintf(w, format, "P99 Time Used", report.P99TimeUsed)
+
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, format, "Conn Opened", report.ConnOpened)
+	fmt.Fprintf(w, format, "Conn Reused", report.ConnReused)
+	fmt.Fprintf(w, format, "Conn Closed", report.ConnClosed)
+
+	if report.TLSHandshakes > 0 {
+		fmt.Fprintln(w)
+
+		fmt.Fprintf(w, format, "TLS Handshakes", report.TLSHandshakes)
+		fmt.Fprintf(w, format, "TLS Resumed", report.TLSResumed)
+		fmt.Fprintf(w, format, "Avg Handshake Time", report.AvgHandshakeTime)
+	}
+
+	if len(report.GraphQLErrors) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "GraphQL Errors")
+		for _, class := range graphQLErrorClasses(report) {
+			fmt.Fprintf(w, format, class, report.GraphQLErrors[class])
+		}
+	}
+}
+
+// graphQLErrorClasses sort classes of GraphQL errors by count
+func graphQLErrorClasses(report *Report) []string {
+	classes := make([]string, 0, len(report.GraphQLErrors))
+	for class := range report.GraphQLErrors {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		ci, cj := report.GraphQLErrors[classes[i]], report.GraphQLErrors[classes[j]]
+		return ci > cj || ci == cj && classes[i] < classes[j]
+	})
+	return classes
 }
 
 func thoundsNumber(n int) string {
@@ -195,6 +264,7 @@ func HumanOutput(report *Report, w io.Writer) {
 	fmt.Fprintf(w, format, "Currency", thoundsNumber(report.Currency), "")
 	fmt.Fprintf(w, format, "Successed", thoundsNumber(report.Successed), "")
 	fmt.Fprintf(w, format, "Failed", thoundsNumber(report.Failed), "")
+	fmt.Fprintf(w, format, "Redirects", thoundsNumber(report.Redirects), "")
 
 	fmt.Fprintln(w)
 
@@ -217,4 +287,26 @@ func HumanOutput(report *Report, w io.Writer) {
 	fmt.Fprintf(w, format, "P90 Time Used", humanDuration(report.P90TimeUsed), "")
 	fmt.Fprintf(w, format, "P95 Time Used", humanDuration(report.P95TimeUsed), "")
 	fmt.Fprintf(w, format, "P99 Time Used", humanDuration(report.P99TimeUsed), "")
+
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, format, "Conn Opened", thoundsNumber(report.ConnOpened), "")
+	fmt.Fprintf(w, format, "Conn Reused", thoundsNumber(report.ConnReused), "")
+	fmt.Fprintf(w, format, "Conn Closed", thoundsNumber(report.ConnClosed), "")
+
+	if report.TLSHandshakes > 0 {
+		fmt.Fprintln(w)
+
+		fmt.Fprintf(w, format, "TLS Handshakes", thoundsNumber(report.TLSHandshakes), "")
+		fmt.Fprintf(w, format, "TLS Resumed", thoundsNumber(report.TLSResumed), "")
+		fmt.Fprintf(w, format, "Avg Handshake Time", humanDuration(report.AvgHandshakeTime), "")
+	}
+
+	if len(report.GraphQLErrors) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "GraphQL Errors")
+		for _, class := range graphQLErrorClasses(report) {
+			fmt.Fprintf(w, format, class, thoundsNumber(report.GraphQLErrors[class]), "")
+		}
+	}
 }
@@ -0,0 +1,179 @@
+package httpfile
+
+import (
+	"bytes"
+
+	"github.com/valyala/fasthttp"
+)
+
+// template is text compiled to literal segments and placeholder slots once,
+// so it's rendered for each iteration without scanning text again
+type template struct {
+	segments []segment
+	dynamic  bool // has placeholder
+}
+
+// segment is literal text or a placeholder
+type segment struct {
+	text   []byte    // literal text, or inner text of placeholder
+	inner  *template // compiled inner text of placeholder, nil for literal
+	key    string    // trimmed inner text, if it has no nested placeholder
+	escape bool      // value is escaped as content of json string
+}
+
+// compileTemplate compile text, text is copied so it can be reused by caller
+func compileTemplate(text []byte) *template {
+	return compile(append([]byte(nil), text...))
+}
+
+func compile(text []byte) *template {
+	t := &template{}
+	for {
+		start := bytes.Index(text, openTag)
+		if start < 0 {
+			break
+		}
+		end := closeTag(text, start)
+		if end < 0 {
+			break
+		}
+		if start > 0 {
+			t.segments = append(t.segments, segment{text: text[:start]})
+		}
+		inner := text[start+2 : end]
+		seg := segment{text: inner, inner: compile(inner)}
+		if !seg.inner.dynamic {
+			seg.key = string(bytes.TrimSpace(inner))
+		}
+		t.segments = append(t.segments, seg)
+		t.dynamic = true
+		text = text[end+2:]
+	}
+	if len(text) > 0 {
+		t.segments = append(t.segments, segment{text: text})
+	}
+	return t
+}
+
+// render append rendered text to out, placeholder is kept if it's failed and expander is not strict
+func (t *template) render(e *expander, out []byte) ([]byte, error) {
+	for _, seg := range t.segments {
+		if seg.inner == nil {
+			out = append(out, seg.text...)
+			continue
+		}
+
+		inner, key := seg.text, seg.key
+		if seg.inner.dynamic {
+			var err error
+			inner, err = seg.inner.render(e, nil)
+			if err != nil {
+				return nil, err
+			}
+			key = string(bytes.TrimSpace(inner))
+		}
+
+		start := len(out)
+		var err error
+		out, err = e.value(out, key)
+		if err != nil {
+			if e.strict && err != errKept {
+				return nil, err
+			}
+			e.kept++
+			out = append(append(append(out[:start], "{{"...), inner...), "}}"...)
+		}
+		if seg.escape {
+			// value is escaped after it, then moved to where it starts
+			end := len(out)
+			out = appendJSONEscaped(out, out[start:end])
+			out = append(out[:start], out[end:]...)
+		}
+	}
+	return out, nil
+}
+
+// appendJSONEscaped append text escaped as content of json string, without quotes
+func appendJSONEscaped(out, text []byte) []byte {
+	const hex = "0123456789abcdef"
+	for _, c := range text {
+		switch {
+		case c == '"' || c == '\\':
+			out = append(out, '\\', c)
+		case c == '\n':
+			out = append(out, '\\', 'n')
+		case c == '\r':
+			out = append(out, '\\', 'r')
+		case c == '\t':
+			out = append(out, '\\', 't')
+		case c < 0x20:
+			out = append(out, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// requestTemplate is compiled request of case, it's shared by duplicated cases
+type requestTemplate struct {
+	method  *template
+	uri     *template
+	body    *template
+	headers []headerTemplate
+}
+
+// headerTemplate is a compiled header, only value is a template
+type headerTemplate struct {
+	key   []byte
+	value *template
+}
+
+// compileRequest compile method, uri, body and headers of request
+func compileRequest(req *fasthttp.Request) *requestTemplate {
+	t := &requestTemplate{
+		method: compileTemplate(req.Header.Method()),
+		uri:    compileTemplate(req.RequestURI()),
+		body:   compileTemplate(req.Body()),
+	}
+	req.Header.VisitAll(func(key, val []byte) {
+		t.headers = append(t.headers, headerTemplate{
+			key:   append([]byte(nil), key...),
+			value: compileTemplate(val),
+		})
+	})
+	return t
+}
+
+// render request from template, rendered text is written in buffer of expander
+// and copied by request, so the buffer is reused by next render
+func (t *requestTemplate) render(req *fasthttp.Request, e *expander) error {
+	buf := e.buf[:0]
+	defer func() {
+		e.buf = buf[:0]
+	}()
+
+	var err error
+	if buf, err = t.method.render(e, buf[:0]); err != nil {
+		return err
+	}
+	req.Header.SetMethodBytes(buf)
+
+	if buf, err = t.uri.render(e, buf[:0]); err != nil {
+		return err
+	}
+	req.SetRequestURIBytes(buf)
+
+	if buf, err = t.body.render(e, buf[:0]); err != nil {
+		return err
+	}
+	req.SetBody(buf)
+
+	for _, h := range t.headers {
+		if buf, err = h.value.render(e, buf[:0]); err != nil {
+			return err
+		}
+		req.Header.SetBytesKV(h.key, buf)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package httpfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompileTemplate(t *testing.T) {
+	text := []byte(`{"a": {{a}}, "r": {{$randomInt {{min}} {{max}}}}, "s": "{{$md5 "${body}"}}"}`)
+	tpl := compileTemplate(text)
+	text[0] = '['
+
+	assert.True(t, tpl.dynamic)
+	assert.Len(t, tpl.segments, 7)
+	assert.Equal(t, `{"a": `, string(tpl.segments[0].text))
+	assert.Equal(t, `a`, string(tpl.segments[1].text))
+	assert.False(t, tpl.segments[1].inner.dynamic)
+	assert.Equal(t, `$randomInt {{min}} {{max}}`, string(tpl.segments[3].text))
+	assert.True(t, tpl.segments[3].inner.dynamic)
+
+	ve := MapReplacer{"a": "1", "min": "5", "max": "1"}
+	out, err := tpl.render(&expander{ve: ListReplacer{ve, &HTTPFile{}}, strict: true}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a": 1, "r": 5, "s": "{{$md5 "${body}"}}"}`, string(out))
+
+	_, err = compileTemplate([]byte("{{b}}")).render(&expander{ve: ve, strict: true}, nil)
+	assert.EqualError(t, err, "undefined variable b")
+	out, err = compileTemplate([]byte("{{b}}-{{a}}")).render(&expander{ve: ve}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "{{b}}-1", string(out))
+
+	tpl = compileTemplate([]byte("plain"))
+	assert.False(t, tpl.dynamic)
+}
+
+func TestRequestTemplate(t *testing.T) {
+	file, err := ParseBytes([]byte(`
+	@host = http://127.0.0.1
+	@token = t1
+
+	# @name c1
+	POST {{host}}/users/{{id}}
+	Authorization: Bearer {{token}}
+	Content-Type: application/json
+
+	{"id": {{id}}}
+	`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Release()
+
+	w := file.Duplicate(false, false)
+	defer w.Release()
+	assert.Equal(t, 0, len(w.Cases[0].request.Body()))
+
+	err = w.prepare(0, w.Cases[0], ListReplacer{MapReplacer{"id": "7"}, w})
+	assert.NoError(t, err)
+	req := w.Cases[0].request
+	assert.Equal(t, "POST", string(req.Header.Method()))
+	assert.Equal(t, "http://127.0.0.1/users/7", req.URI().String())
+	assert.Equal(t, "Bearer t1", string(req.Header.Peek("Authorization")))
+	assert.Equal(t, "application/json", string(req.Header.ContentType()))
+	assert.Equal(t, `{"id": 7}`, strings.TrimSpace(string(req.Body())))
+
+	w2 := file.Duplicate(false, true)
+	defer w2.Release()
+	assert.Equal(t, `{"id": {{id}}}`, strings.TrimSpace(string(w2.Cases[0].request.Body())))
+	assert.Equal(t, "Bearer t1", string(w2.Cases[0].request.Header.Peek("Authorization")))
+}
+
+func BenchmarkRequestTemplate(b *testing.B) {
+	file, err := ParseBytes([]byte(`
+	@host = http://127.0.0.1
+	@token = t1
+	@url = {{host}}/users
+
+	# @name c1
+	POST {{url}}/{{id}}
+	Authorization: Bearer {{token}}
+	Content-Type: application/json
+
+	{"id": {{id}}, "name": "{{token}}", "items": [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]}
+	`))
+	if err != nil {
+		b.Fatal(err)
+	}
+	file.Variables["id"] = "7"
+
+	// duplicate of virtual user is reset and rendered for each iteration
+	w := file.Duplicate(true, false)
+	defer w.Release()
+	iterate := func() {
+		w.reset(file)
+		for i, c := range w.Cases {
+			if err := w.prepare(i, c, w); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	iterate()
+	if allocs := testing.AllocsPerRun(100, iterate); allocs > 0 {
+		b.Fatalf("%v allocs per iteration", allocs)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		iterate()
+	}
+}
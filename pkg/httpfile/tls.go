@@ -0,0 +1,187 @@
+package httpfile
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// TLSOptions describe how to connect a https service
+type TLSOptions struct {
+	CACert       string   // pem file of CA to verify server certificate
+	Cert         string   // pem file of client certificate
+	Key          string   // pem file of client private key
+	Insecure     bool     // skip verify server certificate
+	ServerName   string   // server name indication, default is host of url
+	MinVersion   string   // minimum TLS version, 1.0, 1.1, 1.2 or 1.3
+	MaxVersion   string   // maximum TLS version, 1.0, 1.1, 1.2 or 1.3
+	CipherSuites []string // cipher suite names, empty is go default
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+func tlsVersion(name string) (uint16, error) {
+	if name == "" {
+		return 0, nil
+	}
+	v, ok := tlsVersions[strings.TrimPrefix(strings.ToLower(name), "tls")]
+	if !ok {
+		return 0, fmt.Errorf("unknown tls version %s", name)
+	}
+	return v, nil
+}
+
+func cipherSuite(name string) (uint16, error) {
+	for _, s := range tls.CipherSuites() {
+		if s.Name == name {
+			return s.ID, nil
+		}
+	}
+	for _, s := range tls.InsecureCipherSuites() {
+		if s.Name == name {
+			return s.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown cipher suite %s", name)
+}
+
+// Config build tls.Config from options
+func (o *TLSOptions) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		InsecureSkipVerify: o.Insecure,
+		ServerName:         o.ServerName,
+	}
+
+	if o.CACert != "" {
+		pem, err := os.ReadFile(o.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("read ca cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", o.CACert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if o.Cert != "" || o.Key != "" {
+		key := o.Key
+		if key == "" {
+			key = o.Cert
+		}
+		cert, err := tls.LoadX509KeyPair(o.Cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	var err error
+	if cfg.MinVersion, err = tlsVersion(o.MinVersion); err != nil {
+		return nil, err
+	}
+	if cfg.MaxVersion, err = tlsVersion(o.MaxVersion); err != nil {
+		return nil, err
+	}
+
+	for _, name := range o.CipherSuites {
+		id, err := cipherSuite(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+
+	return cfg, nil
+}
+
+// WithTLSConfig set tls config of client
+func WithTLSConfig(tlsConfig *tls.Config) ClientOpt {
+	return func(c *ClientConfig) {
+		c.TLSConfig = tlsConfig
+	}
+}
+
+// tlsCounter count tls handshakes made by a virtual user
+type tlsCounter struct {
+	handshakes    int64
+	resumed       int64
+	handshakeTime int64 // nanoseconds
+}
+
+func (tc *tlsCounter) Handshakes() int {
+	return int(atomic.LoadInt64(&tc.handshakes))
+}
+
+func (tc *tlsCounter) Resumed() int {
+	return int(atomic.LoadInt64(&tc.resumed))
+}
+
+func (tc *tlsCounter) HandshakeTime() time.Duration {
+	return time.Duration(atomic.LoadInt64(&tc.handshakeTime))
+}
+
+// configureTLS let virtual user do tls handshake itself, so it can be measured
+func (vu *virtualUser) configureTLS(cfg *ClientConfig) func(hc *fasthttp.HostClient) error {
+	tlsConfig := &tls.Config{}
+	if cfg.TLSConfig != nil {
+		tlsConfig = cfg.TLSConfig.Clone()
+	}
+	// sessions are cached per virtual user, as a real client does
+	tlsConfig.ClientSessionCache = tls.NewLRUClientSessionCache(16)
+
+	return func(hc *fasthttp.HostClient) error {
+		if !hc.IsTLS {
+			return nil
+		}
+
+		hostConfig := tlsConfig
+		if hostConfig.ServerName == "" {
+			hostConfig = tlsConfig.Clone()
+			host, _, err := net.SplitHostPort(hc.Addr)
+			if err != nil {
+				host = hc.Addr
+			}
+			hostConfig.ServerName = host
+		}
+
+		dial := hc.Dial
+		hc.Dial = func(addr string) (net.Conn, error) {
+			rawConn, err := dial(addr)
+			if err != nil {
+				return nil, err
+			}
+			conn := tls.Client(rawConn, hostConfig)
+			if cfg.DialTimeout > 0 {
+				conn.SetDeadline(time.Now().Add(cfg.DialTimeout))
+			}
+			t1 := time.Now()
+			if err := conn.Handshake(); err != nil {
+				rawConn.Close()
+				return nil, fmt.Errorf("tls handshake: %w", err)
+			}
+			t2 := time.Now()
+			conn.SetDeadline(time.Time{})
+
+			atomic.AddInt64(&vu.tls.handshakes, 1)
+			atomic.AddInt64(&vu.tls.handshakeTime, int64(t2.Sub(t1)))
+			if conn.ConnectionState().DidResume {
+				atomic.AddInt64(&vu.tls.resumed, 1)
+			}
+			return conn, nil
+		}
+		return nil
+	}
+}
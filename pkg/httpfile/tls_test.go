@@ -0,0 +1,55 @@
+package httpfile
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTLSHandshake(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	content := fmt.Sprintf(`
+	GET %s
+	Connection: close
+	`, server.URL)
+
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unknown authority
+	report := ReportStat(Bench(file, 1, 3, -1))
+	assert.Equal(t, 3, report.Failed)
+
+	tlsOptions := TLSOptions{CACert: caFile, ServerName: "example.com", MinVersion: "1.2"}
+	tlsConfig, err := tlsOptions.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report = ReportStat(Bench(file, 1, 3, -1, WithTLSConfig(tlsConfig)))
+	assert.Equal(t, 3, report.Successed)
+	assert.Equal(t, 3, report.TLSHandshakes)
+	assert.Equal(t, 2, report.TLSResumed)
+	assert.Greater(t, report.AvgHandshakeTime, 0.0)
+
+	_, err = (&TLSOptions{MinVersion: "0.9"}).Config()
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package httpfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// errStrayComment means a comment is between request line and body, it can't be kept in place
+// as headers are written from request
+var errStrayComment = errors.New("comment after request line can't be kept, move it before request line")
+
+// WriteTo write file as .http text, variables not defined by lines of cases are written
+// first, cases are separated by ###, comments, directives and variables are kept in
+// order, so parsing the output give the same file. it fails if a comment is between
+// headers, as it can't be kept in place
+func (f *HTTPFile) WriteTo(w io.Writer) (int64, error) {
+	for i, c := range f.Cases {
+		if c.stray {
+			return 0, fmt.Errorf("case %d: %w", i+1, errStrayComment)
+		}
+	}
+	buff := bytes.NewBuffer(nil)
+
+	defined := make(map[string]bool)
+	for _, c := range f.Cases {
+		for _, line := range c.lines {
+			if strings.HasPrefix(line, "@") {
+				defined[line[1:]] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(f.Variables))
+	for name := range f.Variables {
+		if !defined[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		buff.WriteString("@" + name + " = " + f.Variables[name] + "\n")
+	}
+	if len(names) > 0 {
+		buff.WriteString("\n")
+	}
+
+	// variable defined more than once is written by the first
+	written := make(map[string]bool)
+	variable := func(name string) (string, bool) {
+		value, ok := f.Variables[name]
+		if !ok || written[name] {
+			return "", false
+		}
+		written[name] = true
+		return value, true
+	}
+	for i, c := range f.Cases {
+		if i > 0 {
+			buff.WriteString("\n###\n")
+			if !c.empty || len(c.lines) > 0 {
+				buff.WriteString("\n")
+			}
+		}
+		c.write(buff, variable)
+	}
+	return buff.WriteTo(w)
+}
+
+// WriteTo write case as .http text, the comments, name, think time and other directives,
+// request line, headers and body, variables of file are not written
+func (c *Case) WriteTo(w io.Writer) (int64, error) {
+	if c.stray {
+		return 0, errStrayComment
+	}
+	buff := bytes.NewBuffer(nil)
+	c.write(buff, nil)
+	return buff.WriteTo(w)
+}
+
+// write case to buff, name and think time are written by lines of them or before others,
+// variables in lines are written by value of variable, they are skipped if it's nil
+func (c *Case) write(buff *bytes.Buffer, variable func(name string) (string, bool)) {
+	named, thought := false, false
+	for _, line := range c.lines {
+		if nameTag.MatchString(line) {
+			named = true
+		} else if groups := directiveTag.FindStringSubmatch(line); groups != nil && groups[1] == "think" {
+			thought = true
+		}
+	}
+	if c.Name != "" && !named {
+		buff.WriteString("# @name " + c.Name + "\n")
+	}
+	if c.think > 0 && !thought {
+		buff.WriteString("# @think " + c.think.String() + "\n")
+	}
+
+	// variables are separated from following lines by empty line
+	variables := false
+	for _, line := range c.lines {
+		if strings.HasPrefix(line, "@") {
+			if variable == nil {
+				continue
+			}
+			if value, ok := variable(line[1:]); ok {
+				buff.WriteString(line + " = " + value + "\n")
+				variables = true
+			}
+			continue
+		}
+		if variables {
+			buff.WriteString("\n")
+			variables = false
+		}
+
+		switch groups := directiveTag.FindStringSubmatch(line); {
+		case nameTag.MatchString(line):
+			if c.Name != "" {
+				buff.WriteString("# @name " + c.Name + "\n")
+			}
+		case groups != nil && groups[1] == "think":
+			if c.think > 0 {
+				buff.WriteString("# @think " + c.think.String() + "\n")
+			}
+		default:
+			buff.WriteString(line + "\n")
+		}
+	}
+	if c.empty {
+		return
+	}
+	if variables {
+		buff.WriteString("\n")
+	}
+	// options like -F @file are kept by command as written
+	if c.curl != "" {
+		buff.WriteString(c.curl + "\n")
+		return
+	}
+
+	req := c.request
+	if c.source != nil {
+		req = c.source
+	}
+	// line breaks and comments of body are kept if it's not changed
+	body, sent := req.Body(), sentText(c.text)
+	if bytes.Equal(body, sent) || bytes.Equal(body, bytes.ReplaceAll(sent, []byte("\n"), nil)) {
+		body = c.text
+	}
+	writeRequest(buff, req, body)
+}
+
+// sentText remove comment lines from text of body, they are skipped by parser
+func sentText(text []byte) []byte {
+	sent := make([]byte, 0, len(text))
+	for _, line := range bytes.SplitAfter(text, []byte("\n")) {
+		if !commentTag.Match(bytes.TrimSuffix(line, []byte("\n"))) {
+			sent = append(sent, line...)
+		}
+	}
+	return sent
+}
+
+// writeRequest write request line, headers and body, Content-Length is computed when sending,
+// line breaks around body are trimmed as they are empty lines around body
+func writeRequest(buff *bytes.Buffer, req *fasthttp.Request, body []byte) {
+	buff.Write(req.Header.Method())
+	buff.WriteString(" ")
+	buff.Write(req.RequestURI())
+	buff.WriteString("\n")
+	req.Header.VisitAll(func(key, value []byte) {
+		if strings.EqualFold(string(key), fasthttp.HeaderContentLength) {
+			return
+		}
+		buff.Write(key)
+		buff.WriteString(": ")
+		buff.Write(value)
+		buff.WriteString("\n")
+	})
+	if body = bytes.Trim(body, "\n"); len(body) > 0 {
+		buff.WriteString("\n")
+		buff.Write(body)
+		buff.WriteString("\n")
+	}
+}
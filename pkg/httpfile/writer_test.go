@@ -0,0 +1,229 @@
+package httpfile
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const unformatted = `### start
+@host = http://127.0.0.1:8080
+@user=admin
+
+// login first
+# @name=login
+// @think 1500ms
+# @no-cookie-jar
+POST {{host}}/login
+Content-Type: application/json
+
+{
+    "user": "{{user}}"
+}
+
+
+###
+@token = {{login.response.body.$.token}}
+@user = root
+# @name   me
+GET {{host}}/me
+Authorization: Bearer {{token}}
+
+###
+
+POST {{host}}/graphql
+X-Request-Type: GraphQL
+
+# id only
+query me { me { id } }
+
+{"a": 1}
+
+
+###
+# @soap 1.2 urn:ping
+POST {{host}}/soap
+
+<ping/>
+###
+`
+
+const formatted = `### start
+@host = http://127.0.0.1:8080
+@user = root
+
+// login first
+# @name login
+# @think 1.5s
+# @no-cookie-jar
+POST {{host}}/login
+Content-Type: application/json
+
+{
+    "user": "{{user}}"
+}
+
+###
+
+@token = {{login.response.body.$.token}}
+
+# @name me
+GET {{host}}/me
+Authorization: Bearer {{token}}
+
+###
+
+POST {{host}}/graphql
+X-Request-Type: GraphQL
+
+# id only
+query me { me { id } }
+
+{"a": 1}
+
+###
+
+# @soap 1.2 urn:ping
+POST {{host}}/soap
+
+<ping/>
+
+###
+`
+
+func TestHTTPFileWriteTo(t *testing.T) {
+	file, err := ParseBytes([]byte(unformatted))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buff := bytes.NewBuffer(nil)
+	_, err = file.WriteTo(buff)
+	assert.NoError(t, err)
+	assert.Equal(t, formatted, buff.String())
+
+	// output is stable
+	parsed, err := ParseBytes(buff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, parsed.Cases, len(file.Cases))
+	assert.Equal(t, `{    "user": "{{user}}"}`, string(parsed.Cases[0].request.Body()))
+	assert.Equal(t, string(file.Cases[2].request.Body()), string(parsed.Cases[2].request.Body()))
+	buff.Reset()
+	parsed.WriteTo(buff)
+	assert.Equal(t, formatted, buff.String())
+
+	// changes of variables and cases are written
+	parsed.Variables["host"] = "https://example.com"
+	parsed.Variables["version"] = "v1"
+	delete(parsed.Variables, "token")
+	parsed.Cases[0].Name = "signin"
+	parsed.Cases[0].think = 0
+	parsed.Cases[2].Name = "graphql"
+	parsed.Cases[2].think = time.Second
+	parsed.Cases[3].source.SetBodyString("<pong/>")
+	buff.Reset()
+	parsed.WriteTo(buff)
+	assert.Contains(t, buff.String(), `@version = v1
+
+### start
+@host = https://example.com
+@user = root
+
+// login first
+# @name signin
+# @no-cookie-jar
+POST`)
+	assert.Contains(t, buff.String(), "###\n\n# @name me\n")
+	assert.Contains(t, buff.String(), "###\n\n# @name graphql\n# @think 1s\nPOST {{host}}/graphql\n")
+	assert.Contains(t, buff.String(), "POST {{host}}/soap\n\n<pong/>\n")
+}
+
+func TestHTTPFileWriteToKeepPlace(t *testing.T) {
+	dir := t.TempDir()
+	avatar, data := filepath.Join(dir, "avatar.png"), filepath.Join(dir, "data.txt")
+	assert.NoError(t, os.WriteFile(avatar, []byte("png"), 0o600))
+	assert.NoError(t, os.WriteFile(data, []byte("a=1"), 0o600))
+
+	// curl command is kept as written, files are not inlined
+	content := fmt.Sprintf(`# @name upload
+curl -X POST '{{host}}/upload' \
+  -F 'avatar=@%s'
+X-Extra: 1
+# after curl
+
+###
+
+# @name post
+curl {{host}}/post -d @%s
+
+###
+
+POST {{host}}/items
+Content-Type: application/json
+
+# @think 1s
+// not sent
+{"a": 1}
+`, avatar, data)
+	file, err := ParseBytes([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "1", string(file.Cases[0].request.Header.Peek("X-Extra")))
+	assert.Equal(t, "a=1", string(file.Cases[1].request.Body()))
+	assert.Equal(t, `{"a": 1}`, string(file.Cases[2].request.Body()))
+
+	buff := bytes.NewBuffer(nil)
+	_, err = file.WriteTo(buff)
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf(`# @name upload
+curl -X POST '{{host}}/upload' \
+  -F 'avatar=@%s'
+X-Extra: 1
+# after curl
+
+###
+
+# @name post
+curl {{host}}/post -d @%s
+
+###
+
+# @think 1s
+POST {{host}}/items
+Content-Type: application/json
+
+// not sent
+{"a": 1}
+`, avatar, data), buff.String())
+
+	// directives after request line are written before it and still take effect
+	file, err = ParseBytes([]byte("GET http://example.com/a\n# @no-redirect\nAccept: */*\n\n# @extract id = regex \"id\"\n{}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buff.Reset()
+	_, err = file.WriteTo(buff)
+	assert.NoError(t, err)
+	assert.Equal(t, "# @no-redirect\n# @extract id = regex \"id\"\nGET http://example.com/a\nAccept: */*\n\n{}\n", buff.String())
+	parsed, err := ParseBytes(buff.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, parsed.Cases[0].noRedirect)
+	assert.Equal(t, file.Cases[0].extractors, parsed.Cases[0].extractors)
+
+	// comment between headers is refused instead of being moved
+	file, err = ParseBytes([]byte("GET http://example.com\n# note\nAccept: */*\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = file.WriteTo(buff)
+	assert.EqualError(t, err, "case 1: comment after request line can't be kept, move it before request line")
+}
@@ -0,0 +1,219 @@
+package httpfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// xmlNode is a element of parsed xml document, document itself is a node without name
+type xmlNode struct {
+	name     xml.Name
+	attrs    []xml.Attr
+	children []*xmlNode
+	text     []byte // char data directly in this element
+	parent   *xmlNode
+}
+
+// parseXML parse xml document, nil if failed
+func parseXML(data []byte) *xmlNode {
+	doc := &xmlNode{}
+	cur := doc
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &xmlNode{name: t.Name, attrs: t.Attr, parent: cur}
+			cur.children = append(cur.children, node)
+			cur = node
+		case xml.EndElement:
+			if cur.parent != nil {
+				cur = cur.parent
+			}
+		case xml.CharData:
+			cur.text = append(cur.text, t...)
+		}
+	}
+	if len(doc.children) == 0 {
+		zap.L().Error("xml parse failed, no element found")
+		return nil
+	}
+	return doc
+}
+
+// value return string value of node, text of all descendants
+func (n *xmlNode) value() string {
+	if len(n.children) == 0 {
+		return strings.TrimSpace(string(n.text))
+	}
+	var sb strings.Builder
+	var walk func(n *xmlNode)
+	walk = func(n *xmlNode) {
+		sb.Write(n.text)
+		for _, c := range n.children {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.TrimSpace(sb.String())
+}
+
+// descendants return node itself and all it's descendants in document order
+func (n *xmlNode) descendants() []*xmlNode {
+	result := []*xmlNode{n}
+	for _, c := range n.children {
+		result = append(result, c.descendants()...)
+	}
+	return result
+}
+
+// xpathStep is a location step like //ns:name[2]
+type xpathStep struct {
+	descendant bool   // step after //
+	test       string // name test, * match any element
+	index      int    // position given by [n], 0 if not given
+}
+
+// matchName check name matched name test, prefix declared in namespaces must
+// match namespace uri, name without prefix or with undeclared prefix match local name
+func matchName(test string, name xml.Name, namespaces map[string]string) bool {
+	if pos := strings.IndexByte(test, ':'); pos >= 0 {
+		if uri, ok := namespaces[test[:pos]]; ok && uri != name.Space {
+			return false
+		}
+		test = test[pos+1:]
+	}
+	return test == "*" || test == name.Local
+}
+
+// splitXPath split path to steps, subset of xpath is supported, child /, descendant //,
+// name test with *, position predicate [n], @attr and text() as last step
+func splitXPath(path string) ([]xpathStep, bool) {
+	var steps []xpathStep
+	for len(path) > 0 {
+		if path[0] != '/' {
+			return nil, false
+		}
+		step := xpathStep{}
+		path = path[1:]
+		if strings.HasPrefix(path, "/") {
+			step.descendant = true
+			path = path[1:]
+		}
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
+		step.test, path = path[:end], path[end:]
+		if pos := strings.IndexByte(step.test, '['); pos >= 0 {
+			if !strings.HasSuffix(step.test, "]") {
+				return nil, false
+			}
+			index, err := strconv.Atoi(step.test[pos+1 : len(step.test)-1])
+			if err != nil || index < 1 {
+				return nil, false
+			}
+			step.test, step.index = step.test[:pos], index
+		}
+		if len(step.test) == 0 {
+			return nil, false
+		}
+		steps = append(steps, step)
+	}
+	return steps, len(steps) > 0
+}
+
+// declareNamespace declare xml namespace used by xpath, by # @namespace prefix=uri
+func (f *HTTPFile) declareNamespace(value string) {
+	prefix, uri, ok := strings.Cut(value, "=")
+	if !ok {
+		prefix, uri, ok = strings.Cut(value, " ")
+	}
+	prefix, uri = strings.TrimSpace(prefix), strings.TrimSpace(uri)
+	if !ok || len(prefix) == 0 || len(uri) == 0 {
+		return
+	}
+	if f.Namespaces == nil {
+		f.Namespaces = make(map[string]string)
+	}
+	f.Namespaces[prefix] = uri
+}
+
+// XPathGet get value of the first node matched path from xml data, prefixes in path
+// are resolved by namespaces
+func XPathGet(data interface{}, path string, namespaces map[string]string) (string, bool) {
+	var doc *xmlNode
+	switch d := data.(type) {
+	case *xmlNode:
+		doc = d
+	case string:
+		doc = parseXML([]byte(d))
+	case []byte:
+		doc = parseXML(d)
+	}
+	if doc == nil {
+		return "", false
+	}
+
+	steps, ok := splitXPath(path)
+	if !ok {
+		zap.L().Error("xpath parse failed", zap.String("path", path))
+		return "", false
+	}
+
+	nodes := []*xmlNode{doc}
+	for i, step := range steps {
+		if step.descendant {
+			var all []*xmlNode
+			for _, n := range nodes {
+				all = append(all, n.descendants()...)
+			}
+			nodes = all
+		}
+
+		last := i == len(steps)-1
+		if last && step.index == 0 && (step.test == "text()" || step.test[0] == '@') {
+			for _, n := range nodes {
+				if step.test == "text()" {
+					if text := strings.TrimSpace(string(n.text)); len(text) > 0 {
+						return text, true
+					}
+					continue
+				}
+				for _, attr := range n.attrs {
+					if matchName(step.test[1:], attr.Name, namespaces) {
+						return attr.Value, true
+					}
+				}
+			}
+			return "", false
+		}
+
+		var matched []*xmlNode
+		for _, n := range nodes {
+			position := 0
+			for _, c := range n.children {
+				if !matchName(step.test, c.name, namespaces) {
+					continue
+				}
+				position++
+				if step.index == 0 || step.index == position {
+					matched = append(matched, c)
+				}
+			}
+		}
+		nodes = matched
+		if len(nodes) == 0 {
+			return "", false
+		}
+	}
+	return nodes[0].value(), true
+}
@@ -0,0 +1,352 @@
+package jsonpath
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// logicalExpr is a filter expression evaluated to true or false
+type logicalExpr interface {
+	test(ctx *context, current interface{}) bool
+}
+
+// valueExpr is a comparable evaluated to a value, false if it's Nothing
+type valueExpr interface {
+	value(ctx *context, current interface{}) (interface{}, bool)
+}
+
+// orExpr is a || b
+type orExpr []logicalExpr
+
+func (e orExpr) test(ctx *context, current interface{}) bool {
+	for _, sub := range e {
+		if sub.test(ctx, current) {
+			return true
+		}
+	}
+	return false
+}
+
+// andExpr is a && b
+type andExpr []logicalExpr
+
+func (e andExpr) test(ctx *context, current interface{}) bool {
+	for _, sub := range e {
+		if !sub.test(ctx, current) {
+			return false
+		}
+	}
+	return true
+}
+
+// notExpr is !a
+type notExpr struct {
+	expr logicalExpr
+}
+
+func (e *notExpr) test(ctx *context, current interface{}) bool {
+	return !e.expr.test(ctx, current)
+}
+
+// filterQuery is a query in filter, relative to @ or absolute from $
+type filterQuery struct {
+	relative bool
+	segments []*segment
+}
+
+func (q *filterQuery) nodes(ctx *context, current interface{}) []interface{} {
+	if q.relative {
+		return ctx.query(q.segments, current)
+	}
+	return ctx.query(q.segments, ctx.root)
+}
+
+// singular check query select at most one node, only name and index selectors in child segments
+func (q *filterQuery) singular() bool {
+	for _, seg := range q.segments {
+		if seg.descendant || len(seg.selectors) != 1 {
+			return false
+		}
+		switch seg.selectors[0].(type) {
+		case *nameSelector, *indexSelector:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// test of existence, true if query select any node
+func (q *filterQuery) test(ctx *context, current interface{}) bool {
+	return len(q.nodes(ctx, current)) > 0
+}
+
+// value of singular query
+func (q *filterQuery) value(ctx *context, current interface{}) (interface{}, bool) {
+	nodes := q.nodes(ctx, current)
+	if len(nodes) != 1 {
+		return nil, false
+	}
+	return nodes[0], true
+}
+
+// literal is a string, number, true, false or null
+type literal struct {
+	val interface{}
+}
+
+func (l *literal) value(ctx *context, current interface{}) (interface{}, bool) {
+	return l.val, true
+}
+
+// compareExpr is a comparison like @.price < 10
+type compareExpr struct {
+	op          string
+	left, right valueExpr
+}
+
+func (e *compareExpr) test(ctx *context, current interface{}) bool {
+	l, lok := e.left.value(ctx, current)
+	r, rok := e.right.value(ctx, current)
+	switch e.op {
+	case "==":
+		return equal(l, lok, r, rok)
+	case "!=":
+		return !equal(l, lok, r, rok)
+	case "<":
+		return less(l, lok, r, rok)
+	case "<=":
+		return less(l, lok, r, rok) || equal(l, lok, r, rok)
+	case ">":
+		return less(r, rok, l, lok)
+	case ">=":
+		return less(r, rok, l, lok) || equal(l, lok, r, rok)
+	}
+	return false
+}
+
+// number convert numeric value to float64
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// equal compare values, Nothing is only equal to Nothing
+func equal(l interface{}, lok bool, r interface{}, rok bool) bool {
+	if !lok || !rok {
+		return !lok && !rok
+	}
+	ln, lnum := number(l)
+	rn, rnum := number(r)
+	if lnum || rnum {
+		return lnum && rnum && ln == rn
+	}
+	switch lv := l.(type) {
+	case []interface{}:
+		rv, ok := r.([]interface{})
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for i := range lv {
+			if !equal(lv[i], true, rv[i], true) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		rv, ok := r.(map[string]interface{})
+		if !ok || len(lv) != len(rv) {
+			return false
+		}
+		for key, val := range lv {
+			other, ok := rv[key]
+			if !ok || !equal(val, true, other, true) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(l, r)
+}
+
+// less compare numbers or strings, other values are not ordered
+func less(l interface{}, lok bool, r interface{}, rok bool) bool {
+	if !lok || !rok {
+		return false
+	}
+	ln, lnum := number(l)
+	rn, rnum := number(r)
+	if lnum && rnum {
+		return ln < rn
+	}
+	ls, lstr := l.(string)
+	rs, rstr := r.(string)
+	return lstr && rstr && ls < rs
+}
+
+// function types defined by RFC 9535
+const (
+	valueType = iota
+	logicalType
+	nodesType
+)
+
+// function is a function extension used in filter
+type function struct {
+	result int   // result type
+	params []int // parameter types
+	call   func(args []interface{}) interface{}
+}
+
+// functions are functions defined by RFC 9535, arguments of ValueType are values with
+// nothing as a nil nothing, arguments of NodesType are []interface{}
+var functions = map[string]*function{
+	"length": {result: valueType, params: []int{valueType}, call: funcLength},
+	"count":  {result: valueType, params: []int{nodesType}, call: funcCount},
+	"match":  {result: logicalType, params: []int{valueType, valueType}, call: funcMatch},
+	"search": {result: logicalType, params: []int{valueType, valueType}, call: funcSearch},
+	"value":  {result: valueType, params: []int{nodesType}, call: funcValue},
+}
+
+// nothing is the special result of ValueType without value
+type nothing struct{}
+
+func funcLength(args []interface{}) interface{} {
+	switch v := args[0].(type) {
+	case string:
+		return float64(utf8.RuneCountInString(v))
+	case []interface{}:
+		return float64(len(v))
+	case map[string]interface{}:
+		return float64(len(v))
+	}
+	return nothing{}
+}
+
+func funcCount(args []interface{}) interface{} {
+	return float64(len(args[0].([]interface{})))
+}
+
+func funcValue(args []interface{}) interface{} {
+	nodes := args[0].([]interface{})
+	if len(nodes) != 1 {
+		return nothing{}
+	}
+	return nodes[0]
+}
+
+func funcMatch(args []interface{}) interface{} {
+	return regexTest(args, true)
+}
+
+func funcSearch(args []interface{}) interface{} {
+	return regexTest(args, false)
+}
+
+// regexTest test text by pattern, pattern is compiled when filter is compiled if
+// it's a literal, pattern from data is compiled for each test and never cached
+func regexTest(args []interface{}, full bool) bool {
+	text, ok := args[0].(string)
+	if !ok {
+		return false
+	}
+	switch pattern := args[1].(type) {
+	case *regexp.Regexp:
+		return pattern.MatchString(text)
+	case string:
+		re, err := compileIRegexp(pattern, full)
+		return err == nil && re.MatchString(text)
+	}
+	return false
+}
+
+// regexLiteral is literal pattern of match() and search() compiled with filter
+type regexLiteral struct {
+	re *regexp.Regexp // nil if pattern is invalid, which matches nothing
+}
+
+func (l *regexLiteral) value(ctx *context, current interface{}) (interface{}, bool) {
+	return l.re, l.re != nil
+}
+
+// compileIRegexp compile I-Regexp (RFC 9485), . match any character except \n and \r
+func compileIRegexp(pattern string, full bool) (*regexp.Regexp, error) {
+	var sb strings.Builder
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern):
+			sb.WriteByte(c)
+			i++
+			sb.WriteByte(pattern[i])
+			continue
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '.' && !inClass:
+			sb.WriteString(`[^\n\r]`)
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	if full {
+		return regexp.Compile(`^(?:` + sb.String() + `)$`)
+	}
+	return regexp.Compile(sb.String())
+}
+
+// funcExpr is a function call like length(@.name)
+type funcExpr struct {
+	name string
+	fn   *function
+	args []interface{} // valueExpr for ValueType, *filterQuery for NodesType, logicalExpr for LogicalType
+}
+
+func (e *funcExpr) call(ctx *context, current interface{}) interface{} {
+	args := make([]interface{}, len(e.args))
+	for i, arg := range e.args {
+		switch e.fn.params[i] {
+		case nodesType:
+			args[i] = arg.(*filterQuery).nodes(ctx, current)
+		case logicalType:
+			args[i] = arg.(logicalExpr).test(ctx, current)
+		default:
+			val, ok := arg.(valueExpr).value(ctx, current)
+			if !ok {
+				val = nothing{}
+			}
+			args[i] = val
+		}
+	}
+	return e.fn.call(args)
+}
+
+func (e *funcExpr) value(ctx *context, current interface{}) (interface{}, bool) {
+	result := e.call(ctx, current)
+	if _, ok := result.(nothing); ok {
+		return nil, false
+	}
+	return result, true
+}
+
+func (e *funcExpr) test(ctx *context, current interface{}) bool {
+	result := e.call(ctx, current)
+	if nodes, ok := result.([]interface{}); ok && e.fn.result == nodesType {
+		return len(nodes) > 0
+	}
+	b, _ := result.(bool)
+	return b
+}
@@ -0,0 +1,235 @@
+// Package jsonpath is a JSONPath (RFC 9535) implementation, paths are compiled once and
+// queried against values decoded by encoding/json, result is a list of typed values
+package jsonpath
+
+import (
+	"sort"
+)
+
+// Path is a compiled JSONPath query, it's safe for concurrent use
+type Path struct {
+	text     string
+	segments []*segment
+}
+
+// segment is a child segment like .a, [0, 1] or a descendant segment like ..a
+type segment struct {
+	descendant bool
+	selectors  []selector
+}
+
+// selector select children of a value
+type selector interface {
+	// selectFrom append selected children of value to out
+	selectFrom(ctx *context, value interface{}, out []interface{}) []interface{}
+}
+
+// context of a query, root is the value of $
+type context struct {
+	root interface{}
+}
+
+// Compile parse a JSONPath query
+func Compile(path string) (*Path, error) {
+	p := &parser{text: path}
+	segments, err := p.parseQuery()
+	if err != nil {
+		return nil, err
+	}
+	return &Path{text: path, segments: segments}, nil
+}
+
+// MustCompile is like Compile but panic if path is invalid
+func MustCompile(path string) *Path {
+	p, err := Compile(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String return the source text of path
+func (p *Path) String() string {
+	return p.text
+}
+
+// Query return values selected by path from data, data is decoded by encoding/json
+func (p *Path) Query(data interface{}) []interface{} {
+	ctx := &context{root: data}
+	return ctx.query(p.segments, data)
+}
+
+// Get return the first value selected by path, false if nothing selected
+func (p *Path) Get(data interface{}) (interface{}, bool) {
+	values := p.Query(data)
+	if len(values) == 0 {
+		return nil, false
+	}
+	return values[0], true
+}
+
+// query apply segments to value
+func (ctx *context) query(segments []*segment, value interface{}) []interface{} {
+	nodes := []interface{}{value}
+	for _, seg := range segments {
+		var next []interface{}
+		for _, n := range nodes {
+			if seg.descendant {
+				walk(n, func(v interface{}) {
+					for _, s := range seg.selectors {
+						next = s.selectFrom(ctx, v, next)
+					}
+				})
+				continue
+			}
+			for _, s := range seg.selectors {
+				next = s.selectFrom(ctx, n, next)
+			}
+		}
+		nodes = next
+		if len(nodes) == 0 {
+			break
+		}
+	}
+	return nodes
+}
+
+// walk visit value and all it's descendants in document order
+func walk(value interface{}, visit func(v interface{})) {
+	visit(value)
+	for _, child := range children(value) {
+		walk(child, visit)
+	}
+}
+
+// children return elements of array or member values of object,
+// members are ordered by name since order of object is not kept by decoding
+func children(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		result := make([]interface{}, len(keys))
+		for i, key := range keys {
+			result[i] = v[key]
+		}
+		return result
+	}
+	return nil
+}
+
+// nameSelector select member of object like .name or ['name']
+type nameSelector struct {
+	name string
+}
+
+func (s *nameSelector) selectFrom(ctx *context, value interface{}, out []interface{}) []interface{} {
+	if obj, ok := value.(map[string]interface{}); ok {
+		if member, ok := obj[s.name]; ok {
+			out = append(out, member)
+		}
+	}
+	return out
+}
+
+// wildcardSelector select all children like .* or [*]
+type wildcardSelector struct{}
+
+func (s *wildcardSelector) selectFrom(ctx *context, value interface{}, out []interface{}) []interface{} {
+	return append(out, children(value)...)
+}
+
+// indexSelector select element of array like [1] or [-1]
+type indexSelector struct {
+	index int
+}
+
+func (s *indexSelector) selectFrom(ctx *context, value interface{}, out []interface{}) []interface{} {
+	if arr, ok := value.([]interface{}); ok {
+		i := s.index
+		if i < 0 {
+			i += len(arr)
+		}
+		if i >= 0 && i < len(arr) {
+			out = append(out, arr[i])
+		}
+	}
+	return out
+}
+
+// sliceSelector select elements of array like [start:end:step]
+type sliceSelector struct {
+	start, end int
+	hasStart   bool // start is given, otherwise it's depend on step
+	hasEnd     bool // end is given, otherwise it's depend on step
+	step       int
+}
+
+func normalizeIndex(i, n int) int {
+	if i < 0 {
+		return n + i
+	}
+	return i
+}
+
+func clamp(i, low, high int) int {
+	if i < low {
+		return low
+	}
+	if i > high {
+		return high
+	}
+	return i
+}
+
+func (s *sliceSelector) selectFrom(ctx *context, value interface{}, out []interface{}) []interface{} {
+	arr, ok := value.([]interface{})
+	if !ok || s.step == 0 {
+		return out
+	}
+	n := len(arr)
+	if s.step > 0 {
+		start, end := 0, n
+		if s.hasStart {
+			start = clamp(normalizeIndex(s.start, n), 0, n)
+		}
+		if s.hasEnd {
+			end = clamp(normalizeIndex(s.end, n), 0, n)
+		}
+		for i := start; i < end; i += s.step {
+			out = append(out, arr[i])
+		}
+		return out
+	}
+
+	start, end := n-1, -1
+	if s.hasStart {
+		start = clamp(normalizeIndex(s.start, n), -1, n-1)
+	}
+	if s.hasEnd {
+		end = clamp(normalizeIndex(s.end, n), -1, n-1)
+	}
+	for i := start; i > end; i += s.step {
+		out = append(out, arr[i])
+	}
+	return out
+}
+
+// filterSelector select children matched filter like [?@.price < 10]
+type filterSelector struct {
+	expr logicalExpr
+}
+
+func (s *filterSelector) selectFrom(ctx *context, value interface{}, out []interface{}) []interface{} {
+	for _, child := range children(value) {
+		if s.expr.test(ctx, child) {
+			out = append(out, child)
+		}
+	}
+	return out
+}
@@ -0,0 +1,179 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const store = `{ "store": {
+    "book": [
+      { "category": "reference",
+        "author": "Nigel Rees",
+        "title": "Sayings of the Century",
+        "price": 8.95
+      },
+      { "category": "fiction",
+        "author": "Evelyn Waugh",
+        "title": "Sword of Honour",
+        "price": 12.99
+      },
+      { "category": "fiction",
+        "author": "Herman Melville",
+        "title": "Moby Dick",
+        "isbn": "0-553-21311-3",
+        "price": 8.99
+      },
+      { "category": "fiction",
+        "author": "J. R. R. Tolkien",
+        "title": "The Lord of the Rings",
+        "isbn": "0-395-19395-8",
+        "price": 22.99
+      }
+    ],
+    "bicycle": {
+      "color": "red",
+      "price": 399
+    }
+  }
+}`
+
+func decode(t *testing.T, text string) interface{} {
+	var data interface{}
+	if err := json.Unmarshal([]byte(text), &data); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func query(t *testing.T, data interface{}, path string) []interface{} {
+	p, err := Compile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.Query(data)
+}
+
+func TestQueryStore(t *testing.T) {
+	data := decode(t, store)
+
+	cases := []struct {
+		path     string
+		expected string
+	}{
+		{`$.store.book[*].author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$..author`, `["Nigel Rees","Evelyn Waugh","Herman Melville","J. R. R. Tolkien"]`},
+		{`$.store.*.color`, `["red"]`},
+		{`$.store..price`, `[399,8.95,12.99,8.99,22.99]`},
+		{`$..book[2].author`, `["Herman Melville"]`},
+		{`$..book[2].publisher`, `[]`},
+		{`$..book[-1].title`, `["The Lord of the Rings"]`},
+		{`$..book[0,1].price`, `[8.95,12.99]`},
+		{`$..book[:2].price`, `[8.95,12.99]`},
+		{`$..book[?@.isbn].title`, `["Moby Dick","The Lord of the Rings"]`},
+		{`$..book[?@.price<10].title`, `["Sayings of the Century","Moby Dick"]`},
+		{`$..book[?(@.price < 10 && @.category == 'fiction')].title`, `["Moby Dick"]`},
+		{`$..book[?!@.isbn || @.price > 20].price`, `[8.95,12.99,22.99]`},
+		{`$..book[?@.price > $.store.bicycle.price].title`, `[]`},
+		{`$['store']["bicycle"]['color']`, `["red"]`},
+		{`$.store.book[?length(@.title) == 9].author`, `["Herman Melville"]`},
+		{`$.store.book[?match(@.author, 'J.*')].price`, `[22.99]`},
+		{`$.store.book[?search(@.title, 'of the')].price`, `[8.95,22.99]`},
+		{`$.store[?count(@.*) == 2].color`, `["red"]`},
+		{`$.store.book[?value(@..isbn) == '0-553-21311-3'].title`, `["Moby Dick"]`},
+		{`$.store.book[1:4:2].price`, `[12.99,22.99]`},
+		{`$.store.book[::-1].price`, `[22.99,8.99,12.99,8.95]`},
+		{`$.store.book[-2:].price`, `[8.99,22.99]`},
+		{`$.store.book[0:0].price`, `[]`},
+		{`$.store.book[0:4:0].price`, `[]`},
+		{`$ .store .bicycle [ 'price' ]`, `[399]`},
+	}
+	for _, c := range cases {
+		result, err := json.Marshal(query(t, data, c.path))
+		assert.NoError(t, err)
+		if c.expected == "[]" {
+			assert.Equal(t, "null", string(result), c.path)
+			continue
+		}
+		assert.JSONEq(t, c.expected, string(result), c.path)
+	}
+}
+
+func TestQueryTyped(t *testing.T) {
+	data := decode(t, `{"a":{"b":[1,2]},"n":null,"t":true,"s":"xé"}`)
+
+	for path, expected := range map[string]interface{}{
+		`$.a`:           map[string]interface{}{"b": []interface{}{float64(1), float64(2)}},
+		`$.a.b`:         []interface{}{float64(1), float64(2)},
+		`$.n`:           nil,
+		`$.t`:           true,
+		`$['s']`:        "xé",
+		`$["s"]`:        "xé",
+		`$[?@ == true]`: true,
+	} {
+		val, ok := MustCompile(path).Get(data)
+		assert.True(t, ok, path)
+		assert.Equal(t, expected, val, path)
+	}
+
+	_, ok := MustCompile(`$.missing`).Get(data)
+	assert.False(t, ok)
+
+	assert.Len(t, query(t, data, `$[?@.b == $.a.b]`), 1)
+	assert.Len(t, query(t, data, `$[?@.missing == $.none]`), 4)
+	assert.Len(t, query(t, data, `$[?@ == null]`), 1)
+	assert.Len(t, query(t, data, `$[?@ < 'y']`), 1)
+}
+
+func TestQueryRegex(t *testing.T) {
+	data := decode(t, `[{"s":"abc","p":"b"},{"s":"abc","p":"^a.$"},{"s":"xyz","p":"["}]`)
+
+	// pattern from data is compiled per node
+	assert.Equal(t, []interface{}{"abc"}, query(t, data, `$[?search(@.s, @.p)].s`))
+	assert.Len(t, query(t, data, `$[?match(@.s, @.p)]`), 0)
+	assert.Len(t, query(t, data, `$[?match(@.s, 'a.c')]`), 2)
+	// invalid pattern matches nothing
+	assert.Len(t, query(t, data, `$[?search(@.s, '[')]`), 0)
+}
+
+func TestCompileInvalid(t *testing.T) {
+	for _, path := range []string{
+		``,
+		`a.b`,
+		`$.`,
+		`$.1a`,
+		`$ `,
+		`$[`,
+		`$[01]`,
+		`$[-0]`,
+		`$[9007199254740992]`,
+		`$['a`,
+		`$['\a']`,
+		`$["\uD800"]`,
+		`$[?@.a == ]`,
+		`$[?@.* == 1]`,
+		`$[?@..a == 1]`,
+		`$[?length(@.a)]`,
+		`$[?match(@.a, 'a') == true]`,
+		`$[?count(1) == 1]`,
+		`$[?unknown(@.a)]`,
+		`$[?length(@.a, 1) == 1]`,
+		`$[?'a']`,
+		`$[?@.b == [1, 2]]`,
+		`$[?(@.a == 1]`,
+	} {
+		_, err := Compile(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func BenchmarkQuery(b *testing.B) {
+	var data interface{}
+	json.Unmarshal([]byte(store), &data)
+	p := MustCompile(`$..book[?@.price < 10].title`)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.Query(data)
+	}
+}
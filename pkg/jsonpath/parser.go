@@ -0,0 +1,660 @@
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxInt is the max exact integer of IEEE 754 double, range of index and literal integer
+const maxInt = 1<<53 - 1
+
+// parser is a recursive descent parser of RFC 9535 grammar
+type parser struct {
+	text string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("jsonpath %s at %d: %s", p.text, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *parser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.text[p.pos]
+}
+
+func (p *parser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.text[p.pos:], prefix)
+}
+
+// skipSpace skip blank space, space, tab, line feed and carriage return
+func (p *parser) skipSpace() {
+	for !p.eof() {
+		switch p.text[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// parseQuery parse jsonpath-query = "$" segments
+func (p *parser) parseQuery() ([]*segment, error) {
+	if p.peek() != '$' {
+		return nil, p.errorf("query must start with $")
+	}
+	p.pos++
+	segments, err := p.parseSegments()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.text[p.pos:])
+	}
+	return segments, nil
+}
+
+// parseSegments parse segments = *(S segment)
+func (p *parser) parseSegments() ([]*segment, error) {
+	var segments []*segment
+	for {
+		save := p.pos
+		p.skipSpace()
+		if c := p.peek(); c != '.' && c != '[' {
+			p.pos = save
+			return segments, nil
+		}
+		seg, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, seg)
+	}
+}
+
+// parseSegment parse .name, .*, [selectors], ..name, ..* or ..[selectors]
+func (p *parser) parseSegment() (*segment, error) {
+	seg := &segment{}
+	if p.hasPrefix("..") {
+		seg.descendant = true
+		p.pos += 2
+		if p.peek() == '[' {
+			return p.parseBracketed(seg)
+		}
+	} else if p.peek() == '.' {
+		p.pos++
+	} else {
+		return p.parseBracketed(seg)
+	}
+
+	if p.peek() == '*' {
+		p.pos++
+		seg.selectors = []selector{&wildcardSelector{}}
+		return seg, nil
+	}
+	name := p.parseMemberName()
+	if len(name) == 0 {
+		return nil, p.errorf("member name is expected")
+	}
+	seg.selectors = []selector{&nameSelector{name: name}}
+	return seg, nil
+}
+
+func isNameFirst(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseMemberName parse member-name-shorthand, empty if not found
+func (p *parser) parseMemberName() string {
+	start := p.pos
+	if p.eof() || !isNameFirst(p.peek()) {
+		return ""
+	}
+	for !p.eof() && (isNameFirst(p.peek()) || isDigit(p.peek())) {
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+// parseBracketed parse "[" S selector *(S "," S selector) S "]"
+func (p *parser) parseBracketed(seg *segment) (*segment, error) {
+	p.pos++
+	for {
+		p.skipSpace()
+		sel, err := p.parseSelector()
+		if err != nil {
+			return nil, err
+		}
+		seg.selectors = append(seg.selectors, sel)
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return seg, nil
+		default:
+			return nil, p.errorf("] is expected")
+		}
+	}
+}
+
+// parseSelector parse name, wildcard, index, slice or filter selector
+func (p *parser) parseSelector() (selector, error) {
+	switch c := p.peek(); {
+	case c == '\'' || c == '"':
+		name, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &nameSelector{name: name}, nil
+	case c == '*':
+		p.pos++
+		return &wildcardSelector{}, nil
+	case c == '?':
+		p.pos++
+		p.skipSpace()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return &filterSelector{expr: expr}, nil
+	case c == '-' || isDigit(c) || c == ':':
+		return p.parseIndexOrSlice()
+	}
+	return nil, p.errorf("selector is expected")
+}
+
+// parseInt parse int = "0" / (["-"] DIGIT1 *DIGIT) within range of I-JSON
+func (p *parser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	if !isDigit(p.peek()) {
+		return 0, p.errorf("integer is expected")
+	}
+	if p.peek() == '0' {
+		p.pos++
+		if p.pos-start > 1 {
+			return 0, p.errorf("-0 is not a valid integer")
+		}
+		if isDigit(p.peek()) {
+			return 0, p.errorf("integer with leading zero")
+		}
+		return 0, nil
+	}
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	n, err := strconv.ParseInt(p.text[start:p.pos], 10, 64)
+	if err != nil || n > maxInt || n < -maxInt {
+		return 0, p.errorf("integer %s out of range", p.text[start:p.pos])
+	}
+	return int(n), nil
+}
+
+// parseIndexOrSlice parse index like 1 or slice like 1:5:2
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	s := &sliceSelector{step: 1}
+	if p.peek() != ':' {
+		n, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		save := p.pos
+		p.skipSpace()
+		if p.peek() != ':' {
+			p.pos = save
+			return &indexSelector{index: n}, nil
+		}
+		s.start, s.hasStart = n, true
+	}
+
+	// first :
+	p.pos++
+	p.skipSpace()
+	if c := p.peek(); c == '-' || isDigit(c) {
+		n, err := p.parseInt()
+		if err != nil {
+			return nil, err
+		}
+		s.end, s.hasEnd = n, true
+		p.skipSpace()
+	}
+	if p.peek() == ':' {
+		p.pos++
+		p.skipSpace()
+		if c := p.peek(); c == '-' || isDigit(c) {
+			n, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			s.step = n
+		}
+	}
+	return s, nil
+}
+
+// parseString parse string literal quoted by ' or "
+func (p *parser) parseString() (string, error) {
+	quote := p.peek()
+	p.pos++
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.text[p.pos]
+		switch {
+		case c == quote:
+			p.pos++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated string")
+			}
+			e := p.text[p.pos]
+			p.pos++
+			switch e {
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case '/', '\\':
+				sb.WriteByte(e)
+			case 'u':
+				r, err := p.parseUnicode()
+				if err != nil {
+					return "", err
+				}
+				sb.WriteRune(r)
+			default:
+				if e != quote {
+					return "", p.errorf("invalid escape \\%c", e)
+				}
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// parseHex4 parse 4 hex digits after \u
+func (p *parser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.text) {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	n, err := strconv.ParseUint(p.text[p.pos:p.pos+4], 16, 32)
+	if err != nil {
+		return 0, p.errorf("invalid unicode escape")
+	}
+	p.pos += 4
+	return rune(n), nil
+}
+
+// parseUnicode parse \uXXXX, surrogate pair is \uXXXX\uXXXX
+func (p *parser) parseUnicode() (rune, error) {
+	r, err := p.parseHex4()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case r >= 0xDC00 && r <= 0xDFFF:
+		return 0, p.errorf("unpaired low surrogate")
+	case r >= 0xD800 && r <= 0xDBFF:
+		if !p.hasPrefix(`\u`) {
+			return 0, p.errorf("unpaired high surrogate")
+		}
+		p.pos += 2
+		low, err := p.parseHex4()
+		if err != nil {
+			return 0, err
+		}
+		if low < 0xDC00 || low > 0xDFFF {
+			return 0, p.errorf("invalid low surrogate")
+		}
+		return utf16.DecodeRune(r, low), nil
+	}
+	return r, nil
+}
+
+// parseOr parse logical-or-expr = logical-and-expr *(S "||" S logical-and-expr)
+func (p *parser) parseOr() (logicalExpr, error) {
+	var or orExpr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, expr)
+		save := p.pos
+		p.skipSpace()
+		if !p.hasPrefix("||") {
+			p.pos = save
+			break
+		}
+		p.pos += 2
+		p.skipSpace()
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+// parseAnd parse logical-and-expr = basic-expr *(S "&&" S basic-expr)
+func (p *parser) parseAnd() (logicalExpr, error) {
+	var and andExpr
+	for {
+		expr, err := p.parseBasic()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, expr)
+		save := p.pos
+		p.skipSpace()
+		if !p.hasPrefix("&&") {
+			p.pos = save
+			break
+		}
+		p.pos += 2
+		p.skipSpace()
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+// parseBasic parse paren-expr, comparison-expr or test-expr
+func (p *parser) parseBasic() (logicalExpr, error) {
+	if p.peek() == '!' && !p.hasPrefix("!=") {
+		p.pos++
+		p.skipSpace()
+		var expr logicalExpr
+		var err error
+		if p.peek() == '(' {
+			expr, err = p.parseParen()
+		} else {
+			expr, err = p.parseTest()
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &notExpr{expr: expr}, nil
+	}
+	if p.peek() == '(' {
+		return p.parseParen()
+	}
+
+	start := p.pos
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	op := p.parseOperator()
+	if len(op) == 0 {
+		// not a comparison, parse it again as test expression
+		p.pos = start
+		return p.parseTest()
+	}
+	p.skipSpace()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	l, err := p.comparable(left)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.comparable(right)
+	if err != nil {
+		return nil, err
+	}
+	return &compareExpr{op: op, left: l, right: r}, nil
+}
+
+// parseParen parse "(" S logical-expr S ")"
+func (p *parser) parseParen() (logicalExpr, error) {
+	p.pos++
+	p.skipSpace()
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.peek() != ')' {
+		return nil, p.errorf(") is expected")
+	}
+	p.pos++
+	return expr, nil
+}
+
+// parseTest parse test-expr, a filter query or function of LogicalType or NodesType
+func (p *parser) parseTest() (logicalExpr, error) {
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	switch e := operand.(type) {
+	case *filterQuery:
+		return e, nil
+	case *funcExpr:
+		if e.fn.result == valueType {
+			return nil, p.errorf("result of %s() is not a logical value", e.name)
+		}
+		return e, nil
+	}
+	return nil, p.errorf("literal can't be used as test expression")
+}
+
+// parseOperator parse comparison operator, empty if not found
+func (p *parser) parseOperator() string {
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.hasPrefix(op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// comparable check operand is comparable, literal, singular query or function of ValueType
+func (p *parser) comparable(operand interface{}) (valueExpr, error) {
+	switch e := operand.(type) {
+	case *literal:
+		return e, nil
+	case *filterQuery:
+		if !e.singular() {
+			return nil, p.errorf("query in comparison must be singular")
+		}
+		return e, nil
+	case *funcExpr:
+		if e.fn.result != valueType {
+			return nil, p.errorf("result of %s() is not comparable", e.name)
+		}
+		return e, nil
+	}
+	return nil, p.errorf("invalid comparable")
+}
+
+// parseOperand parse literal, filter query or function expression
+func (p *parser) parseOperand() (interface{}, error) {
+	c := p.peek()
+	switch {
+	case c == '@' || c == '$':
+		p.pos++
+		segments, err := p.parseSegments()
+		if err != nil {
+			return nil, err
+		}
+		return &filterQuery{relative: c == '@', segments: segments}, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &literal{val: s}, nil
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case c >= 'a' && c <= 'z':
+		for _, kw := range []struct {
+			text string
+			val  interface{}
+		}{{"true", true}, {"false", false}, {"null", nil}} {
+			if p.hasPrefix(kw.text) {
+				next := p.pos + len(kw.text)
+				if next >= len(p.text) || !(isNameFirst(p.text[next]) || isDigit(p.text[next]) || p.text[next] == '(') {
+					p.pos = next
+					return &literal{val: kw.val}, nil
+				}
+			}
+		}
+		return p.parseFunction()
+	}
+	return nil, p.errorf("operand is expected")
+}
+
+// parseNumber parse number = (int / "-0") [ frac ] [ exp ]
+func (p *parser) parseNumber() (interface{}, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	if !isDigit(p.peek()) {
+		return nil, p.errorf("number is expected")
+	}
+	if p.peek() == '0' {
+		p.pos++
+		if isDigit(p.peek()) {
+			return nil, p.errorf("number with leading zero")
+		}
+	}
+	for isDigit(p.peek()) {
+		p.pos++
+	}
+	if p.peek() == '.' {
+		p.pos++
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("fraction is expected")
+		}
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	if c := p.peek(); c == 'e' || c == 'E' {
+		p.pos++
+		if c := p.peek(); c == '+' || c == '-' {
+			p.pos++
+		}
+		if !isDigit(p.peek()) {
+			return nil, p.errorf("exponent is expected")
+		}
+		for isDigit(p.peek()) {
+			p.pos++
+		}
+	}
+	n, err := strconv.ParseFloat(p.text[start:p.pos], 64)
+	if err != nil {
+		return nil, p.errorf("invalid number %s", p.text[start:p.pos])
+	}
+	return &literal{val: n}, nil
+}
+
+// parseFunction parse function-expr = function-name "(" S [function-argument *(S "," S function-argument)] S ")"
+func (p *parser) parseFunction() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && (p.peek() >= 'a' && p.peek() <= 'z' || isDigit(p.peek()) || p.peek() == '_') {
+		p.pos++
+	}
+	name := p.text[start:p.pos]
+	if p.peek() != '(' {
+		return nil, p.errorf("unexpected %s", name)
+	}
+	fn, ok := functions[name]
+	if !ok {
+		return nil, p.errorf("unknown function %s", name)
+	}
+	p.pos++
+
+	e := &funcExpr{name: name, fn: fn}
+	p.skipSpace()
+	for p.peek() != ')' {
+		if len(e.args) == len(fn.params) {
+			return nil, p.errorf("too many arguments of %s()", name)
+		}
+		arg, err := p.parseArgument(fn.params[len(e.args)])
+		if err != nil {
+			return nil, err
+		}
+		e.args = append(e.args, arg)
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.pos++
+			p.skipSpace()
+		} else if p.peek() != ')' {
+			return nil, p.errorf(") is expected")
+		}
+	}
+	p.pos++
+	if len(e.args) != len(fn.params) {
+		return nil, p.errorf("%s() need %d arguments", name, len(fn.params))
+	}
+	if name == "match" || name == "search" {
+		if l, ok := e.args[1].(*literal); ok {
+			if pattern, ok := l.val.(string); ok {
+				re, _ := compileIRegexp(pattern, name == "match")
+				e.args[1] = &regexLiteral{re: re}
+			}
+		}
+	}
+	return e, nil
+}
+
+// parseArgument parse function argument of given type
+func (p *parser) parseArgument(typ int) (interface{}, error) {
+	switch typ {
+	case nodesType:
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if q, ok := operand.(*filterQuery); ok {
+			return q, nil
+		}
+		return nil, p.errorf("query is expected as argument")
+	case logicalType:
+		return p.parseOr()
+	}
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return p.comparable(operand)
+}
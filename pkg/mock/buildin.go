@@ -20,6 +20,11 @@ func dateTimeMock(layout string) func() string {
 	}
 }
 
+// DateTime mock a time in 30 days around now
+func DateTime(layout string) string {
+	return dateTimeMock(layout)()
+}
+
 func buildinPatterns() []PatternMock {
 	return []PatternMock{
 		newPatternMock(`^\d\d\d\d-\d\d-\d\d$`, dateTimeMock("2006-01-02")),
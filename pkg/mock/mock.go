@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/fantai/ftab/pkg/mock/cn"
 	"github.com/spf13/viper"
@@ -28,6 +29,66 @@ func Load(name string) Mocker {
 	}
 }
 
+// Default is mocker given by config mocker
+func Default() Mocker {
+	return Load(viper.GetString("mocker"))
+}
+
+// Hint mock a value by words in name like email, mobile, phone, idcard and name, false if
+// name has none of them, words are split by case and separators, so username and
+// hostname are not names
+func Hint(name string) (string, bool) {
+	mocker := Default()
+	hint := make(map[string]bool)
+	parts := words(name)
+	for i, word := range parts {
+		hint[word] = true
+		if i > 0 {
+			hint[parts[i-1]+word] = true
+		}
+	}
+	switch {
+	case hint["email"]:
+		return mocker.EMail(), true
+	case hint["mobile"] || hint["phone"]:
+		return mocker.Mobile(), true
+	case hint["idcard"]:
+		return mocker.IDCard(), true
+	case hint["name"]:
+		return mocker.Name(), true
+	}
+	return "", false
+}
+
+// words split name like lastName, last_name or IDCard to lower case words
+func words(name string) []string {
+	var result []string
+	runes := []rune(name)
+	start := -1
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				result = append(result, strings.ToLower(string(runes[start:i])))
+			}
+			start = -1
+			continue
+		}
+		// a word start at upper case after lower case, or before lower case like Card of IDCard
+		if start >= 0 && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			result = append(result, strings.ToLower(string(runes[start:i])))
+			start = i
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		result = append(result, strings.ToLower(string(runes[start:])))
+	}
+	return result
+}
+
 var dontMock = []string{
 	"host",
 	"port",
@@ -48,31 +109,43 @@ func init() {
 
 // Value return a mock data for given name
 func Value(name, originValue string) string {
+	if generate := generator(name, originValue); generate != nil {
+		return generate()
+	}
+	return originValue
+}
+
+// Mockable tell if value is mocked by Value, otherwise it's kept as is
+func Mockable(name, originValue string) bool {
+	return generator(name, originValue) != nil
+}
+
+// generator find generator by name or value, nil if value is not mocked
+func generator(name, originValue string) func() string {
 
 	nameLower := strings.ToLower(name)
 
 	if sort.SearchStrings(dontMock, nameLower) != len(dontMock) {
-		return originValue
+		return nil
 	}
 
-	mocker := Load(viper.GetString("mocker"))
+	mocker := Default()
 	switch nameLower {
 	case "idcard":
-		return mocker.IDCard()
+		return mocker.IDCard
 	case "email":
-		return mocker.EMail()
+		return mocker.EMail
 	case "name":
-		return mocker.Name()
+		return mocker.Name
 	case "mobile":
-		return mocker.Mobile()
+		return mocker.Mobile
 	}
 
-	originValueBytes := []byte(originValue)
 	for _, pm := range patterns {
-		if pm.Pattern.Match(originValueBytes) {
-			return pm.Mock()
+		if pm.Pattern.MatchString(originValue) {
+			return pm.Mock
 		}
 	}
 
-	return originValue
+	return nil
 }
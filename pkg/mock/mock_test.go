@@ -20,3 +20,29 @@ func TestMockValue(t *testing.T) {
 	t.Log(Value("time", "2020-08-23 15:23:42"))
 
 }
+
+func TestHint(t *testing.T) {
+	for _, name := range []string{"email", "userEmail", "mobile", "phoneNumber", "idcard", "IDCard", "id_card", "lastName", "first-name"} {
+		value, ok := Hint(name)
+		if !ok || value == "" {
+			t.Errorf("%s is not mocked", name)
+		}
+	}
+	for _, name := range []string{"age", "username", "hostname", "filename"} {
+		if _, ok := Hint(name); ok {
+			t.Errorf("%s is mocked", name)
+		}
+	}
+}
+
+func TestMockable(t *testing.T) {
+	if !Mockable("time", "2020-08-23") {
+		t.Error("date is not mockable")
+	}
+	if Mockable("host", "2020-08-23") {
+		t.Error("host is mockable")
+	}
+	if Mockable("token", "t1") {
+		t.Error("t1 is mockable")
+	}
+}